@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/DylanSharp/dtools/internal/aicmd"
+	"github.com/DylanSharp/dtools/internal/coderabbit/adapters"
+	"github.com/DylanSharp/dtools/internal/logging"
+)
+
+// doctorProbeTimeout bounds each external command doctor runs, so a hung
+// daemon shows up as a failed check instead of hanging the command
+const doctorProbeTimeout = 10 * time.Second
+
+var (
+	doctorOKStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("2")) // Green
+	doctorFailStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("1")) // Red
+	doctorDimStyle  = lipgloss.NewStyle().Faint(true)
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that dtools' dependencies are installed and configured",
+	Long: `Check the tools dtools relies on and print a checklist with hints for
+anything missing or misconfigured:
+
+  git     needed by every command; the current directory should be a repository
+  gh      used by review, and must be logged in (gh auth status); without gh,
+          review uses the token in GH_TOKEN or GITHUB_TOKEN instead
+  docker  used by worktree to run each worktree's services; the daemon must be running
+  claude  the AI CLI used by review and ralph (or the --ai-command binary)
+
+Exits with status 1 if any check fails.`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// doctorCheck is one line of the doctor checklist
+type doctorCheck struct {
+	Name    string
+	OK      bool
+	Skipped bool   // Not checked because a check it relies on failed
+	Detail  string // Version or state if OK, the problem otherwise
+	Hint    string // How to fix a failed check
+}
+
+// runDoctor runs every check and prints the checklist
+func runDoctor(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	checks := []doctorCheck{
+		checkBinary(ctx, "git", []string{"--version"}, "Install git from https://git-scm.com/downloads"),
+		checkGitRepo(ctx),
+		checkGH(ctx),
+		checkGHAuth(ctx),
+		checkBinary(ctx, "docker", []string{"--version"}, "Install Docker from https://docs.docker.com/get-docker/"),
+		checkDockerRunning(ctx),
+	}
+	aiCheck, err := checkAICommand(ctx)
+	if err != nil {
+		return err
+	}
+	checks = append(checks, aiCheck)
+
+	failed := 0
+	for _, check := range checks {
+		switch {
+		case check.OK:
+			fmt.Printf("%s %-15s %s\n", doctorOKStyle.Render("✓"), check.Name, doctorDimStyle.Render(check.Detail))
+			continue
+		case check.Skipped:
+			fmt.Printf("%s %-15s %s\n", doctorDimStyle.Render("-"), check.Name, doctorDimStyle.Render(check.Detail))
+			continue
+		}
+		failed++
+		fmt.Printf("%s %-15s %s\n", doctorFailStyle.Render("✗"), check.Name, check.Detail)
+		if check.Hint != "" {
+			fmt.Printf("  %s\n", doctorDimStyle.Render("→ "+check.Hint))
+		}
+	}
+
+	fmt.Println()
+	if failed == 0 {
+		fmt.Println(doctorOKStyle.Render("Everything looks good"))
+		return nil
+	}
+	fmt.Println(doctorFailStyle.Render(fmt.Sprintf("%d check(s) failed", failed)))
+
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	return &exitCodeError{code: 1}
+}
+
+// probe runs a command with doctorProbeTimeout and returns the first line of its output
+func probe(ctx context.Context, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, doctorProbeTimeout)
+	defer cancel()
+
+	out, err := logging.Output(exec.CommandContext(ctx, name, args...))
+	if err != nil {
+		return "", err
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return strings.TrimSpace(line), nil
+}
+
+// checkBinary checks that a binary is on the PATH and reports its version
+func checkBinary(ctx context.Context, name string, versionArgs []string, hint string) doctorCheck {
+	check := doctorCheck{Name: name, Hint: hint}
+	if _, err := exec.LookPath(name); err != nil {
+		check.Detail = "not found in PATH"
+		return check
+	}
+
+	check.OK = true
+	check.Detail = "installed"
+	if version, err := probe(ctx, name, versionArgs...); err == nil && version != "" {
+		check.Detail = version
+	}
+	return check
+}
+
+// checkGH checks that gh is installed, which isn't needed when a GitHub token is set
+func checkGH(ctx context.Context) doctorCheck {
+	check := checkBinary(ctx, "gh", []string{"--version"}, "Install the GitHub CLI from https://cli.github.com")
+	if !check.OK && adapters.TokenFromEnv() != "" {
+		check.Skipped = true
+		check.Detail = "not installed, review uses the GitHub token instead"
+	}
+	return check
+}
+
+// checkGitRepo checks that the current directory is inside a git repository
+func checkGitRepo(ctx context.Context) doctorCheck {
+	check := doctorCheck{
+		Name: "git repository",
+		Hint: "Run dtools from inside a git repository (worktree and review need one)",
+	}
+	root, err := probe(ctx, "git", "rev-parse", "--show-toplevel")
+	if err != nil {
+		check.Detail = "current directory is not in a git repository"
+		return check
+	}
+	check.OK = true
+	check.Detail = root
+	return check
+}
+
+// checkGHAuth checks that gh is logged in, the same way review does before starting
+func checkGHAuth(ctx context.Context) doctorCheck {
+	check := doctorCheck{Name: "gh auth", Hint: "Run `gh auth login`"}
+	if _, err := exec.LookPath("gh"); err != nil {
+		if token := adapters.TokenFromEnv(); token != "" {
+			return checkGitHubToken(ctx, token)
+		}
+		check.Skipped = true
+		check.Detail = "skipped, gh is not installed"
+		return check
+	}
+	if err := adapters.NewGitHubCLIClient().CheckAuth(ctx); err != nil {
+		check.Detail = "not logged in"
+		return check
+	}
+	check.OK = true
+	check.Detail = "logged in"
+	return check
+}
+
+// checkGitHubToken checks that GitHub accepts the token review uses when gh isn't installed
+func checkGitHubToken(ctx context.Context, token string) doctorCheck {
+	check := doctorCheck{Name: "github token", Hint: "Set GH_TOKEN or GITHUB_TOKEN to a valid token"}
+	ctx, cancel := context.WithTimeout(ctx, doctorProbeTimeout)
+	defer cancel()
+	if err := adapters.NewGitHubHTTPClient(token).CheckAuth(ctx); err != nil {
+		check.Detail = "token rejected or GitHub unreachable"
+		return check
+	}
+	check.OK = true
+	check.Detail = "accepted"
+	return check
+}
+
+// checkDockerRunning checks that the docker daemon answers
+func checkDockerRunning(ctx context.Context) doctorCheck {
+	check := doctorCheck{Name: "docker daemon", Hint: "Start Docker Desktop or the docker service"}
+	if _, err := exec.LookPath("docker"); err != nil {
+		check.Skipped = true
+		check.Detail = "skipped, docker is not installed"
+		return check
+	}
+	version, err := probe(ctx, "docker", "info", "--format", "{{.ServerVersion}}")
+	if err != nil {
+		check.Detail = "not running or not reachable"
+		return check
+	}
+	check.OK = true
+	check.Detail = "running, server " + version
+	return check
+}
+
+// checkAICommand checks that the AI CLI review and ralph run is available
+func checkAICommand(ctx context.Context) (doctorCheck, error) {
+	command, err := resolveAICommand()
+	if err != nil {
+		return doctorCheck{}, err
+	}
+
+	check := doctorCheck{Name: command.Binary}
+	if aiCommand == "" {
+		check.Hint = "Install Claude Code: https://docs.anthropic.com/en/docs/claude-code"
+	} else {
+		check.Hint = "Install " + command.Binary + " or fix --ai-command"
+	}
+
+	if !adapters.NewClaudeClientWithCommand(command).IsAvailable() {
+		check.Detail = "not found in PATH"
+		return check, nil
+	}
+	check.OK = true
+	check.Detail = "installed"
+	output, err := probe(ctx, command.Binary, "--version")
+	if err != nil || output == "" {
+		return check, nil
+	}
+	check.Detail = output
+	if version := aicmd.ParseVersion(output); command.IsClaude() && version != "" && !aicmd.IsTestedVersion(version) {
+		check.Detail += fmt.Sprintf(" (untested: dtools is tested with >=%s <%s)", aicmd.TestedMinVersion, aicmd.TestedMaxVersion)
+	}
+	return check, nil
+}
@@ -1,10 +1,26 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
+
+	"github.com/DylanSharp/dtools/internal/aicmd"
+	"github.com/DylanSharp/dtools/internal/logging"
+	"github.com/DylanSharp/dtools/internal/statedir"
+	dtui "github.com/DylanSharp/dtools/internal/ui"
+)
+
+var (
+	aiCommand string
+	verbose   bool
+	trustAI   bool
 )
 
 var rootCmd = &cobra.Command{
@@ -14,11 +30,140 @@ var rootCmd = &cobra.Command{
 
   worktree  Git worktree manager with isolated Docker environments
   review    CodeRabbit PR comment reviewer with Claude
-  ralph     PRD-based story execution with Claude`,
+  ralph     PRD-based story execution with Claude
+
+Run 'dtools doctor' to check that git, gh, docker and claude are set up.
+
+review and ralph run Claude with --dangerously-skip-permissions, so it edits
+files and runs commands unattended. The first time, you're asked to confirm;
+without a terminal, pass --yes-i-trust-claude.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		logging.Init(verbose)
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false,
+		"Log external commands (gh, git, docker, AI CLI) and their exit status to stderr")
+	rootCmd.PersistentFlags().StringVar(&aiCommand, "ai-command", "",
+		"AI CLI command template emitting stream-json ("+aicmd.PromptPlaceholder+" marks the prompt, default: Claude CLI)")
+	rootCmd.PersistentFlags().BoolVar(&trustAI, "yes-i-trust-claude", false,
+		"Accept that Claude runs with "+aicmd.SkipPermissionsFlag+" without being asked first (remembered)")
+}
+
+// checkAIVersion logs the Claude CLI's version and warns if dtools hasn't been
+// tested with it, since a changed stream format leaves Claude's thoughts empty.
+// Other AI CLIs aren't checked.
+func checkAIVersion(ctx context.Context, command aicmd.Command) {
+	if !command.IsClaude() {
+		return
+	}
+	version, err := command.Version(ctx)
+	if err != nil {
+		logging.Warn("could not determine the Claude CLI version", "err", err)
+		return
+	}
+	logging.Info("Claude CLI", "version", version, "tested", aicmd.IsTestedVersion(version))
+	if !aicmd.IsTestedVersion(version) {
+		fmt.Fprintf(os.Stderr, "Warning: dtools hasn't been tested with Claude CLI %s (tested: >=%s <%s).\n"+
+			"If Claude's output shows up empty, its stream format may have changed.\n",
+			version, aicmd.TestedMinVersion, aicmd.TestedMaxVersion)
+	}
+}
+
+// trustFile records that the user accepted running the AI without permission prompts
+const trustFile = "trust-claude"
+
+// confirmSkipPermissions makes sure the user has accepted, once, that the AI
+// command can edit files and run commands unattended. It asks on a terminal and
+// otherwise requires --yes-i-trust-claude. Commands that keep Claude's
+// permission prompts need no confirmation.
+func confirmSkipPermissions(command aicmd.Command) error {
+	if !command.SkipsPermissions() {
+		return nil
+	}
+	path := filepath.Join(statedir.Dir(), trustFile)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	if !trustAI {
+		if !isatty.IsTerminal(os.Stdin.Fd()) {
+			return fmt.Errorf("Claude runs with %s, so it can edit files and run commands without asking.\n"+
+				"Pass --yes-i-trust-claude to accept this (it's remembered), or use --ai-command without the flag", aicmd.SkipPermissionsFlag)
+		}
+
+		fmt.Fprintf(os.Stderr, "Claude runs with %s: it edits files and runs commands\n", aicmd.SkipPermissionsFlag)
+		fmt.Fprintln(os.Stderr, "in this directory without asking. Review its changes before you commit or push them.")
+		confirmed, err := dtui.Confirm("Let Claude run unattended? You won't be asked again.")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return fmt.Errorf("cancelled: Claude wasn't allowed to run without permission prompts")
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("could not record confirmation: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(time.Now().Format(time.RFC3339)+"\n"), 0644); err != nil {
+		return fmt.Errorf("could not record confirmation: %w", err)
+	}
+	return nil
+}
+
+// resolveAICommand returns the AI command selected with --ai-command, or the Claude CLI
+func resolveAICommand() (aicmd.Command, error) {
+	if aiCommand == "" {
+		return aicmd.Default(), nil
+	}
+	command, err := aicmd.Parse(aiCommand)
+	if err != nil {
+		return aicmd.Command{}, fmt.Errorf("invalid --ai-command: %w", err)
+	}
+	return command, nil
+}
+
+// logTUIToFile moves verbose logging into a file before a TUI takes over the
+// terminal, telling the user where to find it
+func logTUIToFile() {
+	logTUIToFileIn("")
+}
+
+// logTUIToFileIn is logTUIToFile with the log in dir, or the default location for ""
+func logTUIToFileIn(dir string) {
+	logToFile := logging.LogToFile
+	if dir != "" {
+		logToFile = func() (string, error) { return logging.LogToFileIn(dir) }
+	}
+	path, err := logToFile()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: verbose logging disabled:", err)
+		logging.Init(false)
+		return
+	}
+	if path != "" {
+		fmt.Fprintln(os.Stderr, "Verbose log:", path)
+	}
+}
+
+// exitCodeError makes dtools exit with a specific status without printing an error.
+// Commands returning it should set SilenceErrors and SilenceUsage.
+type exitCodeError struct {
+	code int
+}
+
+func (e *exitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
 }
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
+		var exitErr *exitCodeError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
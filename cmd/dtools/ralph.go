@@ -1,28 +1,79 @@
 package main
 
 import (
+	"context"
 	"embed"
+	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"text/template"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 
+	"github.com/DylanSharp/dtools/internal/aicmd"
+	"github.com/DylanSharp/dtools/internal/envfile"
+	"github.com/DylanSharp/dtools/internal/logging"
 	"github.com/DylanSharp/dtools/internal/ralph/adapters"
+	"github.com/DylanSharp/dtools/internal/ralph/domain"
 	"github.com/DylanSharp/dtools/internal/ralph/ports"
 	"github.com/DylanSharp/dtools/internal/ralph/service"
 	"github.com/DylanSharp/dtools/internal/ralph/ui"
+	dtui "github.com/DylanSharp/dtools/internal/ui"
 )
 
 //go:embed templates/*
 var ralphTemplateFS embed.FS
 
 var (
-	ralphPRDFile string
+	ralphPRDFile        string
+	ralphTimeout        time.Duration
+	ralphMaxPasses      int
+	ralphOutputDir      string
+	ralphOutputNested   bool // Several PRDs share --output-dir, each in a subdirectory
+	ralphStopOnFailure  bool
+	ralphCommitPerStory bool
+	ralphPushPerStory   bool
+	ralphTemplate       string
+	ralphOutput         string
+	ralphParallel       bool
+	ralphWorkDir        string
+	ralphEnv            []string
+	ralphEnvFile        string
+
+	ralphDeleteAllCompleted bool
+	ralphDeleteYes          bool
+
+	ralphStatusJSON bool
+	ralphStatusText bool
+
+	ralphExportFormat string
+	ralphExportOutput string
 )
 
+// ralphTemplateInfo describes an embedded PRD template
+type ralphTemplateInfo struct {
+	Name        string
+	Description string
+}
+
+// ralphTemplates lists the embedded PRD templates in display order
+var ralphTemplates = []ralphTemplateInfo{
+	{Name: "full", Description: "Overview plus three example stories with dependencies"},
+	{Name: "minimal", Description: "Overview and a single story"},
+	{Name: "bugfix", Description: "Reproduce, fix and guard against regressions of a bug"},
+}
+
+// defaultRalphTemplate is used when --template is not given
+const defaultRalphTemplate = "full"
+
 var ralphCmd = &cobra.Command{
 	Use:   "ralph",
 	Short: "PRD-based Claude agent loop",
@@ -41,30 +92,152 @@ var ralphInitCmd = &cobra.Command{
 	Short: "Initialize a new ralph project",
 	Long: `Create a new PRD file from template.
 
-If no name is provided, uses the current directory name.`,
+If no name is provided, uses the current directory name.
+The PRD is written to prd.md unless --output is given.
+Use --template to pick a template; 'dtools ralph templates' lists them.
+--template also accepts the path of your own template file.
+
+Templates are Go text/templates with these values:
+  {{.ProjectName}}  Project name ({{PROJECT_NAME}} also works)
+  {{.Date}}         Today's date, YYYY-MM-DD ({{DATE}})
+  {{.Author}}       git config user.name ({{AUTHOR}})
+  {{.Repo}}         Name of the enclosing git repository ({{REPO}})
+
+Custom templates can use conditionals, e.g. {{with .Author}}by {{.}}{{end}}.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runRalphInit,
 }
 
+var ralphRefreshCmd = &cobra.Command{
+	Use:   "refresh [prd-file]",
+	Short: "Merge PRD edits into an existing project",
+	Long: `Re-read the PRD and merge it into the saved project, keeping the
+progress of stories that still exist.
+
+Added, removed and changed stories are reported. Completed stories whose
+dependencies are no longer all completed are reset to pending.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRalphRefresh,
+}
+
+var ralphDeleteCmd = &cobra.Command{
+	Use:   "delete [project-id]",
+	Short: "Delete a ralph project's saved state",
+	Long: `Remove a project's saved state from $XDG_CONFIG_HOME/dtools/ralph/projects
+(~/.config/dtools/ralph/projects by default, $DTOOLS_STATE_DIR/ralph/projects if set).
+
+The PRD file itself is not touched. Use --all-completed to delete every
+project whose status is completed.`,
+	Example: `  # Delete a single project (IDs are shown by 'dtools ralph list')
+  dtools ralph delete my-project-1a2b3c
+
+  # Clear all finished projects without prompting
+  dtools ralph delete --all-completed --yes`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRalphDelete,
+}
+
+var ralphTemplatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "List available PRD templates",
+	Args:  cobra.NoArgs,
+	RunE:  runRalphTemplates,
+}
+
 var ralphStatusCmd = &cobra.Command{
 	Use:   "status [prd-file]",
 	Short: "Show project status",
 	Long: `Display the current status of a ralph project, including:
 - Total stories and completion progress
 - Story status (pending, blocked, completed, failed)
-- Dependency information`,
+- Dependency information
+
+With --json or --text the status is printed without the TUI and the exit
+code reflects the project state: 0 when every story is complete, 2 when a
+story has failed, and 3 when stories are still remaining.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runRalphStatus,
 }
 
+var ralphPlanCmd = &cobra.Command{
+	Use:   "plan [prd-file]",
+	Short: "Preview the story execution order",
+	Long: `Print the order ralph will execute a PRD's stories in, with each story's
+dependencies, and group stories into waves that only depend on earlier waves.
+
+Stories that can never run because of a missing or circular dependency are
+listed separately, and the command exits with status 1 if there are any.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRalphPlan,
+}
+
+var ralphExportCmd = &cobra.Command{
+	Use:   "export [project-id|prd-file]",
+	Short: "Export a progress report for a project",
+	Long: `Write a report of a project's progress: its overview, each story's status,
+attempts, time taken and last error, the dependencies between stories, and
+overall completion.
+
+The markdown format is meant for pasting into a PR description; its
+dependency graph is a mermaid diagram, which GitHub renders. The json format
+has the same fields as 'ralph status --json', plus the overview and the
+waves stories run in.`,
+	Example: `  # Add the report to the PR for this branch
+  dtools ralph export > report.md && gh pr edit --body-file report.md
+
+  # Export a project listed by 'dtools ralph list' as JSON
+  dtools ralph export my-project-1a2b3c --format json -o progress.json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRalphExport,
+}
+
 var ralphRunCmd = &cobra.Command{
-	Use:   "run [prd-file]",
+	Use:   "run [prd-file...]",
 	Short: "Execute project stories",
 	Long: `Run the ralph agent loop to execute stories from a PRD file.
 
 Stories are executed sequentially in dependency order. Claude is used
-to implement each story, and progress is displayed in a terminal UI.`,
-	Args: cobra.MaximumNArgs(1),
+to implement each story, and progress is displayed in a terminal UI.
+
+Stories run in the PRD's directory. If the PRD lives somewhere else, such
+as docs/, point --work-dir at the code; the project remembers it.
+
+Claude inherits dtools' environment. Use --env-file or --env to give it
+extra variables, such as credentials its tests need; values are never
+logged or saved.
+
+A large story may not fit in one Claude session. With --max-passes, a story
+whose session ends without Claude confirming it's complete is continued in
+a new session, told what the previous one changed, up to that many sessions.
+
+With --output-dir, a record of the run is kept there: each story's transcript
+in stories/<STORY-ID>.log, project events in run.log, the markdown report
+(as from 'ralph export') in report.md, and with --verbose the log. Given
+several PRDs, each gets a subdirectory named after its project ID.
+
+Given several PRD files, each runs as its own project, one after another
+(quitting the terminal UI stops the rest). With --parallel they all run at
+once, printing story progress instead of showing the terminal UI. Projects
+in the same checkout would edit each other's files, so they run one after
+another unless every story declares a **Scope:** and none overlap; give each
+PRD its own worktree ('dtools worktree create') to run them side by side. A summary
+follows, and the exit code covers every project like 'ralph status' does:
+0 when all are complete, 2 if any story failed, 3 if stories remain.`,
+	Example: `  # Run the PRD in the current directory
+  dtools ralph run
+
+  # Run a PRD kept in docs/ against the repository root
+  dtools ralph run docs/prd.md --work-dir .
+
+  # Give Claude the credentials in .env.test
+  dtools ralph run --env-file .env.test -e STRIPE_KEY=sk_test_123
+
+  # Run related PRDs back to back
+  dtools ralph run auth.md billing.md
+
+  # Run them at the same time
+  dtools ralph run auth.md billing.md --parallel`,
+	Args: cobra.ArbitraryArgs,
 	RunE: runRalphProject,
 }
 
@@ -79,12 +252,38 @@ func init() {
 	ralphCmd.AddCommand(ralphInitCmd)
 	ralphCmd.AddCommand(ralphStatusCmd)
 	ralphCmd.AddCommand(ralphRunCmd)
+	ralphCmd.AddCommand(ralphPlanCmd)
 	ralphCmd.AddCommand(ralphListCmd)
+	ralphCmd.AddCommand(ralphTemplatesCmd)
+	ralphCmd.AddCommand(ralphDeleteCmd)
+	ralphCmd.AddCommand(ralphRefreshCmd)
+	ralphCmd.AddCommand(ralphExportCmd)
 	rootCmd.AddCommand(ralphCmd)
 
 	// Flags
 	ralphRunCmd.Flags().StringVarP(&ralphPRDFile, "prd", "p", "prd.md", "Path to PRD file")
+	ralphRunCmd.Flags().BoolVar(&ralphStopOnFailure, "stop-on-failure", false, "Stop the run as soon as a story fails (critical stories always stop it)")
+	ralphRunCmd.Flags().BoolVar(&ralphCommitPerStory, "commit-per-story", false, "Commit all changes as \"[STORY-ID] title\" after each completed story")
+	ralphRunCmd.Flags().BoolVar(&ralphPushPerStory, "push", false, "Push after each story commit (with --commit-per-story)")
+	ralphRunCmd.Flags().StringVar(&ralphWorkDir, "work-dir", "", "Directory stories run in, instead of the PRD's directory; remembered for later runs")
+	ralphRunCmd.Flags().StringArrayVarP(&ralphEnv, "env", "e", nil, "Environment variable for Claude as KEY=value (repeatable, overrides --env-file)")
+	ralphRunCmd.Flags().StringVar(&ralphEnvFile, "env-file", "", "File of KEY=value lines added to Claude's environment")
+	ralphRunCmd.Flags().BoolVar(&ralphParallel, "parallel", false, "Run several PRDs at the same time, without the TUI")
+	ralphRunCmd.Flags().StringVar(&ralphOutputDir, "output-dir", "", "Directory to keep the run's story transcripts, report and verbose log in")
+	ralphRunCmd.Flags().IntVar(&ralphMaxPasses, "max-passes", 1, "Claude sessions a story may take: a story left unfinished is continued in a new session")
+	ralphRunCmd.Flags().DurationVar(&ralphTimeout, "timeout", aicmd.DefaultTimeout, "Maximum time for a single story run (0 disables)")
 	ralphStatusCmd.Flags().StringVarP(&ralphPRDFile, "prd", "p", "prd.md", "Path to PRD file")
+	ralphPlanCmd.Flags().StringVarP(&ralphPRDFile, "prd", "p", "prd.md", "Path to PRD file")
+	ralphStatusCmd.Flags().BoolVar(&ralphStatusJSON, "json", false, "Output as JSON instead of the TUI")
+	ralphStatusCmd.Flags().BoolVar(&ralphStatusText, "text", false, "Output as plain text instead of the TUI")
+	ralphRefreshCmd.Flags().StringVarP(&ralphPRDFile, "prd", "p", "prd.md", "Path to PRD file")
+	ralphExportCmd.Flags().StringVarP(&ralphPRDFile, "prd", "p", "prd.md", "Path to PRD file")
+	ralphExportCmd.Flags().StringVarP(&ralphExportFormat, "format", "f", "md", "Report format: md or json")
+	ralphExportCmd.Flags().StringVarP(&ralphExportOutput, "output", "o", "", "Write the report to this file instead of stdout")
+	ralphDeleteCmd.Flags().BoolVar(&ralphDeleteAllCompleted, "all-completed", false, "Delete every completed project")
+	ralphDeleteCmd.Flags().BoolVarP(&ralphDeleteYes, "yes", "y", false, "Skip the confirmation prompt")
+	ralphInitCmd.Flags().StringVarP(&ralphOutput, "output", "o", "prd.md", "Path of the PRD file to create")
+	ralphInitCmd.Flags().StringVarP(&ralphTemplate, "template", "t", defaultRalphTemplate, "PRD template name (see 'dtools ralph templates') or path to a template file")
 }
 
 // runRalphInit initializes a new ralph project
@@ -101,35 +300,131 @@ func runRalphInit(cmd *cobra.Command, args []string) error {
 		name = filepath.Base(cwd)
 	}
 
-	// Check if prd.md already exists
-	prdPath := "prd.md"
+	// Check if the PRD already exists
+	prdPath := ralphOutput
 	if _, err := os.Stat(prdPath); err == nil {
-		return fmt.Errorf("prd.md already exists. Delete it first or use a different name")
+		return fmt.Errorf("%s already exists. Delete it first or use --output to choose a different path", prdPath)
 	}
 
-	// Load template
-	template, err := ralphTemplateFS.ReadFile("templates/prd_template.md")
-	if err != nil {
+	// Load template, built in or from a file
+	var text []byte
+	var err error
+	if isRalphTemplate(ralphTemplate) {
+		text, err = ralphTemplateFS.ReadFile("templates/" + ralphTemplate + ".md")
+		if err != nil {
+			return fmt.Errorf("could not load template: %w", err)
+		}
+	} else if text, err = os.ReadFile(ralphTemplate); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("unknown template %q. Run 'dtools ralph templates' to list them", ralphTemplate)
+		}
 		return fmt.Errorf("could not load template: %w", err)
 	}
 
-	// Replace placeholders
-	content := strings.ReplaceAll(string(template), "{{PROJECT_NAME}}", name)
+	content, err := renderRalphTemplate(ralphTemplate, string(text), ralphTemplateData{
+		ProjectName: name,
+		Date:        time.Now().Format("2006-01-02"),
+		Author:      gitOutput("config", "user.name"),
+		Repo:        gitRepoName(),
+	})
+	if err != nil {
+		return fmt.Errorf("could not render template %s: %w", ralphTemplate, err)
+	}
 
-	// Write file
+	// Write file, creating parent directories as needed
+	if dir := filepath.Dir(prdPath); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("could not create directory %s: %w", dir, err)
+		}
+	}
 	if err := os.WriteFile(prdPath, []byte(content), 0644); err != nil {
-		return fmt.Errorf("could not write prd.md: %w", err)
+		return fmt.Errorf("could not write %s: %w", prdPath, err)
+	}
+
+	runHint := "dtools ralph run"
+	if filepath.Clean(prdPath) != "prd.md" {
+		runHint += " " + prdPath
 	}
 
 	fmt.Printf("Initialized ralph project: %s\n", name)
 	fmt.Printf("  Created: %s\n\n", prdPath)
 	fmt.Println("Next steps:")
-	fmt.Println("  1. Edit prd.md to define your stories")
-	fmt.Println("  2. Run 'dtools ralph run' to start implementing")
+	fmt.Printf("  1. Edit %s to define your stories\n", prdPath)
+	fmt.Printf("  2. Run '%s' to start implementing\n", runHint)
 
 	return nil
 }
 
+// runRalphTemplates lists the embedded PRD templates
+func runRalphTemplates(cmd *cobra.Command, args []string) error {
+	fmt.Println("Available templates:")
+	for _, t := range ralphTemplates {
+		marker := ""
+		if t.Name == defaultRalphTemplate {
+			marker = " (default)"
+		}
+		fmt.Printf("  %-10s %s%s\n", t.Name, t.Description, marker)
+	}
+	fmt.Println("\nUse: dtools ralph init [name] --template <name or file>")
+	return nil
+}
+
+// ralphTemplateData holds the values a PRD template can use, as fields
+// ({{.ProjectName}}) or as the original placeholders ({{PROJECT_NAME}})
+type ralphTemplateData struct {
+	ProjectName string
+	Date        string
+	Author      string // git config user.name; empty if unset
+	Repo        string // Enclosing git repository's directory name; empty outside a repository
+}
+
+// renderRalphTemplate executes a PRD template. The original placeholders are
+// template functions, so existing templates keep working.
+func renderRalphTemplate(name, text string, data ralphTemplateData) (string, error) {
+	tmpl, err := template.New(name).Funcs(template.FuncMap{
+		"PROJECT_NAME": func() string { return data.ProjectName },
+		"DATE":         func() string { return data.Date },
+		"AUTHOR":       func() string { return data.Author },
+		"REPO":         func() string { return data.Repo },
+	}).Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// gitOutput runs git and returns its trimmed output, or "" if it fails
+func gitOutput(args ...string) string {
+	out, err := logging.Output(exec.Command("git", args...))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// gitRepoName returns the directory name of the enclosing git repository, or "" outside one
+func gitRepoName() string {
+	if root := gitOutput("rev-parse", "--show-toplevel"); root != "" {
+		return filepath.Base(root)
+	}
+	return ""
+}
+
+// isRalphTemplate checks if name is a known template
+func isRalphTemplate(name string) bool {
+	for _, t := range ralphTemplates {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 // runRalphStatus shows project status
 func runRalphStatus(cmd *cobra.Command, args []string) error {
 	// Get PRD path
@@ -154,6 +449,18 @@ func runRalphStatus(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if ralphStatusJSON || ralphStatusText {
+		if ralphStatusJSON {
+			err = printRalphStatusJSON(project)
+		} else {
+			printRalphStatusText(project)
+		}
+		if err != nil {
+			return err
+		}
+		return ralphStatusExitCode(cmd, project)
+	}
+
 	// Display status using TUI
 	model := ui.NewStatusModel(project)
 	p := tea.NewProgram(model)
@@ -164,55 +471,567 @@ func runRalphStatus(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// runRalphProject executes the project
-func runRalphProject(cmd *cobra.Command, args []string) error {
-	// Get PRD path
+// ralphStatusStory is the JSON representation of a story in `ralph status`
+type ralphStatusStory struct {
+	ID              string            `json:"id"`
+	Title           string            `json:"title"`
+	Status          string            `json:"status"`
+	Priority        int               `json:"priority"`
+	Critical        bool              `json:"critical,omitempty"`
+	Attempts        int               `json:"attempts"`
+	DependsOn       []string          `json:"depends_on"`
+	BlockedBy       []string          `json:"blocked_by,omitempty"`     // Dependencies not yet completed
+	StalledReason   string            `json:"stalled_reason,omitempty"` // Why the story can never run, if it can't
+	StartedAt       *time.Time        `json:"started_at,omitempty"`
+	CompletedAt     *time.Time        `json:"completed_at,omitempty"`
+	DurationSeconds float64           `json:"duration_seconds"`
+	Error           string            `json:"error,omitempty"`
+	FilesChanged    []string          `json:"files_changed,omitempty"` // Files the last run changed
+	Metadata        map[string]string `json:"metadata,omitempty"`      // Declared in the PRD
+}
+
+// ralphStatusOutput is the JSON output of `ralph status`
+type ralphStatusOutput struct {
+	ID              string             `json:"id"`
+	Name            string             `json:"name"`
+	PRDPath         string             `json:"prd_path"`
+	WorkDir         string             `json:"work_dir"`
+	Status          string             `json:"status"`
+	Complete        bool               `json:"complete"`
+	Progress        int                `json:"progress"`
+	Total           int                `json:"total"`
+	Completed       int                `json:"completed"`
+	Pending         int                `json:"pending"`
+	Blocked         int                `json:"blocked"`
+	Running         int                `json:"running"`
+	Failed          int                `json:"failed"`
+	Stalled         int                `json:"stalled"` // Pending or blocked, but can never run
+	DurationSeconds float64            `json:"duration_seconds"`
+	Stories         []ralphStatusStory `json:"stories"`
+}
+
+// printRalphStatusJSON prints the project state as JSON
+func printRalphStatusJSON(project *domain.Project) error {
+	data, err := json.MarshalIndent(newRalphStatusOutput(project), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode status: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// newRalphStatusOutput builds the JSON representation of a project's state
+func newRalphStatusOutput(project *domain.Project) ralphStatusOutput {
+	output := ralphStatusOutput{
+		ID:              project.ID,
+		Name:            project.Name,
+		PRDPath:         project.PRDPath,
+		WorkDir:         project.WorkDir,
+		Status:          string(project.Status),
+		Complete:        project.IsComplete(),
+		Progress:        project.Progress(),
+		Total:           project.TotalStories(),
+		Completed:       project.CompletedStories(),
+		Pending:         project.PendingStories(),
+		Blocked:         project.BlockedStories(),
+		Running:         project.RunningStories(),
+		Failed:          project.FailedStories(),
+		DurationSeconds: project.Duration().Seconds(),
+		Stories:         []ralphStatusStory{},
+	}
+
+	stalled := service.NewScheduler().GetStalledStories(project)
+	output.Stalled = len(stalled)
+
+	completedIDs := project.GetCompletedIDs()
+	for _, story := range project.Stories {
+		dependsOn := story.DependsOn
+		if dependsOn == nil {
+			dependsOn = []string{}
+		}
+		output.Stories = append(output.Stories, ralphStatusStory{
+			ID:              story.ID,
+			Title:           story.Title,
+			Status:          string(story.Status),
+			Priority:        story.Priority,
+			Critical:        story.Critical,
+			Attempts:        story.Attempts,
+			DependsOn:       dependsOn,
+			BlockedBy:       story.UnmetDependencies(completedIDs),
+			StalledReason:   stalled[story.ID],
+			StartedAt:       story.StartedAt,
+			CompletedAt:     story.CompletedAt,
+			DurationSeconds: story.Duration().Seconds(),
+			Error:           story.Error,
+			FilesChanged:    story.FilesChanged(),
+			Metadata:        story.DeclaredMetadata(),
+		})
+	}
+	return output
+}
+
+// printRalphStatusText prints the project state as plain text
+func printRalphStatusText(project *domain.Project) {
+	fmt.Printf("Project: %s (%s)\n", project.Name, project.Status)
+	fmt.Printf("PRD: %s\n", project.PRDPath)
+	if project.WorkDir != filepath.Dir(project.PRDPath) {
+		fmt.Printf("Work dir: %s\n", project.WorkDir)
+	}
+	fmt.Printf("Progress: %d/%d stories (%d%%)", project.CompletedStories(), project.TotalStories(), project.Progress())
+	if failed := project.FailedStories(); failed > 0 {
+		fmt.Printf(", %d failed", failed)
+	}
+	if blocked := project.BlockedStories(); blocked > 0 {
+		fmt.Printf(", %d blocked", blocked)
+	}
+	stalled := service.NewScheduler().GetStalledStories(project)
+	if len(stalled) > 0 {
+		fmt.Printf(", %d stalled", len(stalled))
+	}
+	fmt.Println()
+	if total := project.StoriesDuration(); total > 0 {
+		fmt.Printf("Time: %s across stories\n", total.Round(time.Second))
+	}
+	fmt.Println()
+
+	completedIDs := project.GetCompletedIDs()
+	for _, story := range project.Stories {
+		var details []string
+		if story.Attempts > 0 {
+			details = append(details, fmt.Sprintf("%d attempt(s)", story.Attempts))
+		}
+		if elapsed := ui.FormatStoryDuration(story); elapsed != "" {
+			details = append(details, elapsed)
+		}
+		if reason, ok := stalled[story.ID]; ok {
+			details = append(details, "stalled, "+reason)
+		} else if unmet := story.UnmetDependencies(completedIDs); len(unmet) > 0 && !story.IsCompleted() {
+			details = append(details, "waiting on "+strings.Join(unmet, ", "))
+		}
+
+		line := fmt.Sprintf("  %s %s: %s", ui.GetStatusIcon(string(story.Status)), story.ID, story.Title)
+		if len(details) > 0 {
+			line += " (" + strings.Join(details, ", ") + ")"
+		}
+		fmt.Println(line)
+		if story.Error != "" {
+			fmt.Printf("      error: %s\n", story.Error)
+		}
+		if files := story.FilesChanged(); len(files) > 0 {
+			fmt.Printf("      files: %s\n", strings.Join(files, ", "))
+		}
+		for _, key := range story.DeclaredMetadataKeys() {
+			fmt.Printf("      %s: %s\n", key, story.Metadata[key])
+		}
+	}
+
+	printStalledStories(project, stalled)
+}
+
+// printStalledStories lists the stories that can't run until a failed or missing
+// dependency is dealt with, with how to unstick them
+func printStalledStories(project *domain.Project, stalled map[string]string) {
+	if len(stalled) == 0 {
+		return
+	}
+	fmt.Printf("\nStalled (%d), these can't run until the PRD is fixed and refreshed:\n", len(stalled))
+	for _, story := range project.Stories {
+		if reason, ok := stalled[story.ID]; ok {
+			fmt.Printf("  ⚠ %s: %s (%s)\n", story.ID, story.Title, reason)
+		}
+	}
+}
+
+// ralphExport is the JSON output of `ralph export`
+type ralphExport struct {
+	ralphStatusOutput
+	Overview   string     `json:"overview,omitempty"`
+	Waves      [][]string `json:"waves"`
+	ExportedAt time.Time  `json:"exported_at"`
+}
+
+// runRalphExport writes a project's progress report
+func runRalphExport(cmd *cobra.Command, args []string) error {
+	idOrPath := ralphPRDFile
+	if len(args) > 0 {
+		idOrPath = args[0]
+	}
+	if ralphExportFormat != "md" && ralphExportFormat != "json" {
+		return fmt.Errorf("unknown format %q (expected md or json)", ralphExportFormat)
+	}
+
+	svc, err := createRalphService()
+	if err != nil {
+		return err
+	}
+	project, err := svc.GetProject(idOrPath)
+	if err != nil {
+		return fmt.Errorf("could not load project (has it been run?): %w", err)
+	}
+
+	var report string
+	if ralphExportFormat == "json" {
+		waves, _ := svc.GetScheduler().GetExecutionWaves(project)
+		if waves == nil {
+			waves = [][]string{}
+		}
+		data, err := json.MarshalIndent(ralphExport{
+			ralphStatusOutput: newRalphStatusOutput(project),
+			Overview:          project.Description,
+			Waves:             waves,
+			ExportedAt:        time.Now(),
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode report: %w", err)
+		}
+		report = string(data) + "\n"
+	} else {
+		report = renderRalphMarkdownReport(project)
+	}
+
+	if ralphExportOutput == "" {
+		fmt.Print(report)
+		return nil
+	}
+	if err := os.WriteFile(ralphExportOutput, []byte(report), 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %s\n", ralphExportOutput)
+	return nil
+}
+
+// renderRalphMarkdownReport renders a project's progress as markdown for a PR description
+func renderRalphMarkdownReport(project *domain.Project) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "## %s\n\n", project.Name)
+	if overview := strings.TrimSpace(project.Description); overview != "" {
+		sb.WriteString(overview + "\n\n")
+	}
+
+	fmt.Fprintf(&sb, "**Progress:** %d/%d stories (%d%%)", project.CompletedStories(), project.TotalStories(), project.Progress())
+	if failed := project.FailedStories(); failed > 0 {
+		fmt.Fprintf(&sb, ", %d failed", failed)
+	}
+	if blocked := project.BlockedStories(); blocked > 0 {
+		fmt.Fprintf(&sb, ", %d blocked", blocked)
+	}
+	fmt.Fprintf(&sb, " · **Status:** %s", project.Status)
+	if total := project.StoriesDuration(); total > 0 {
+		fmt.Fprintf(&sb, " · **Time:** %s", total.Round(time.Second))
+	}
+	sb.WriteString("\n\n")
+
+	stalled := service.NewScheduler().GetStalledStories(project)
+	sb.WriteString("| | Story | Status | Attempts | Time | Depends on |\n")
+	sb.WriteString("|---|---|---|---|---|---|\n")
+	for _, story := range project.Stories {
+		status := string(story.Status)
+		if reason, ok := stalled[story.ID]; ok {
+			status = "stalled, " + reason
+		}
+		dependsOn := "—"
+		if story.HasDependencies() {
+			dependsOn = strings.Join(story.DependsOn, ", ")
+		}
+		elapsed := ui.FormatStoryDuration(story)
+		if elapsed == "" {
+			elapsed = "—"
+		}
+		fmt.Fprintf(&sb, "| %s | **%s** %s | %s | %d | %s | %s |\n",
+			ui.GetStatusIcon(string(story.Status)), story.ID, markdownCell(story.Title),
+			status, story.Attempts, elapsed, dependsOn)
+	}
+
+	var failed []*domain.Story
+	for _, story := range project.Stories {
+		if story.Error != "" && !story.IsCompleted() {
+			failed = append(failed, story)
+		}
+	}
+	if len(failed) > 0 {
+		sb.WriteString("\n### Errors\n\n")
+		for _, story := range failed {
+			fmt.Fprintf(&sb, "- **%s**: %s\n", story.ID, markdownCell(story.Error))
+		}
+	}
+
+	if graph := ralphMermaidGraph(project); graph != "" {
+		sb.WriteString("\n### Dependencies\n\n")
+		sb.WriteString(graph)
+	}
+
+	return sb.String()
+}
+
+// ralphMermaidGraph renders the story dependency graph as a mermaid flowchart,
+// or "" if no story depends on another
+func ralphMermaidGraph(project *domain.Project) string {
+	var edges []string
+	for _, story := range project.Stories {
+		for _, dep := range story.DependsOn {
+			edges = append(edges, fmt.Sprintf("  %s --> %s", mermaidID(dep), mermaidID(story.ID)))
+		}
+	}
+	if len(edges) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("```mermaid\nflowchart TD\n")
+	for _, story := range project.Stories {
+		fmt.Fprintf(&sb, "  %s[\"%s %s\"]\n", mermaidID(story.ID), ui.GetStatusIcon(string(story.Status)), story.ID)
+	}
+	sb.WriteString(strings.Join(edges, "\n"))
+	sb.WriteString("\n```\n")
+	return sb.String()
+}
+
+// mermaidID makes a story ID safe to use as a mermaid node ID
+func mermaidID(id string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			return r
+		}
+		return '_'
+	}, id)
+}
+
+// markdownCell flattens text onto one line and escapes pipes so it fits in a table cell
+func markdownCell(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	return strings.ReplaceAll(text, "|", "\\|")
+}
+
+// ralphStatusExitCode maps the project state to the `ralph status` exit code
+func ralphStatusExitCode(cmd *cobra.Command, project *domain.Project) error {
+	if project.IsComplete() {
+		return nil
+	}
+
+	code := 3
+	if project.HasFailures() || project.Status == domain.ProjectStatusFailed {
+		code = 2
+	}
+
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	return &exitCodeError{code: code}
+}
+
+// runRalphPlan prints the planned execution order of a PRD's stories
+func runRalphPlan(cmd *cobra.Command, args []string) error {
 	prdPath := ralphPRDFile
 	if len(args) > 0 {
 		prdPath = args[0]
 	}
 
-	// Create service
 	svc, err := createRalphService()
 	if err != nil {
 		return err
 	}
 
-	// Check Claude availability
-	executor := adapters.NewClaudeExecutor()
-	if !executor.IsAvailable() {
+	project, err := svc.PreviewProject(prdPath)
+	if err != nil {
+		return fmt.Errorf("could not parse PRD: %w", err)
+	}
+
+	scheduler := svc.GetScheduler()
+	order := scheduler.GetExecutionOrder(project)
+	waves, unreachable := scheduler.GetExecutionWaves(project)
+
+	fmt.Printf("Execution plan for %s (%d stories)\n\n", project.Name, project.TotalStories())
+
+	fmt.Println("Order:")
+	for i, id := range order {
+		story := project.GetStory(id)
+		if story == nil {
+			continue
+		}
+		line := fmt.Sprintf("  %2d. %s: %s (P%d)", i+1, story.ID, story.Title, story.Priority)
+		if story.HasDependencies() {
+			line += " after " + strings.Join(story.DependsOn, ", ")
+		}
+		fmt.Println(line)
+	}
+
+	fmt.Println("\nWaves (stories in a wave don't depend on each other):")
+	for i, wave := range waves {
+		fmt.Printf("  %d: %s\n", i+1, strings.Join(wave, ", "))
+	}
+
+	if len(unreachable) == 0 {
+		return nil
+	}
+
+	fmt.Printf("\nNever runs (%d):\n", len(unreachable))
+	for _, story := range project.Stories {
+		if reason, ok := unreachable[story.ID]; ok {
+			fmt.Printf("  ✗ %s: %s (%s)\n", story.ID, story.Title, reason)
+		}
+	}
+
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	return &exitCodeError{code: 1}
+}
+
+// ralphRunResult is the outcome of running one PRD as part of `ralph run`
+type ralphRunResult struct {
+	PRD     string
+	Project *domain.Project // nil if the project couldn't be loaded or started
+	Err     error
+}
+
+// runRalphProject executes the project of each PRD given, one after another or
+// with --parallel all at once, and summarizes them when there's more than one
+func runRalphProject(cmd *cobra.Command, args []string) error {
+	prdPaths := args
+	if len(prdPaths) == 0 {
+		prdPaths = []string{ralphPRDFile}
+	}
+	// Check AI CLI availability
+	command, err := resolveAICommand()
+	if err != nil {
+		return err
+	}
+	if !command.IsAvailable() {
+		if aiCommand != "" {
+			return fmt.Errorf("AI command %q not found in PATH", command.Binary)
+		}
 		return fmt.Errorf("Claude CLI not found. Please install Claude Code first")
 	}
+	if err := confirmSkipPermissions(command); err != nil {
+		return err
+	}
+	checkAIVersion(cmd.Context(), command)
 
-	// Try to load existing project, or initialize from PRD
+	ralphOutputNested = len(prdPaths) > 1
+
+	if len(prdPaths) == 1 && !ralphParallel {
+		_, _, err := runRalphPRD(prdPaths[0])
+		return err
+	}
+
+	var results []ralphRunResult
+	if ralphParallel {
+		results = runRalphPRDsParallel(cmd.Context(), prdPaths)
+	} else {
+		for _, prdPath := range prdPaths {
+			project, finished, err := runRalphPRD(prdPath)
+			results = append(results, ralphRunResult{PRD: prdPath, Project: project, Err: err})
+			// Quitting the TUI mid-run stops the whole run, not just this PRD
+			if err == nil && !finished {
+				fmt.Println("\nRun interrupted, skipping the remaining PRDs")
+				break
+			}
+		}
+	}
+
+	return printRalphRunSummary(cmd, prdPaths, results)
+}
+
+// loadRalphProject loads the project for a PRD, initializing it on first run,
+// and applies --work-dir
+func loadRalphProject(svc *service.ProjectService, prdPath string, printf func(format string, a ...any)) (*domain.Project, error) {
 	project, err := svc.GetProject(prdPath)
 	if err != nil {
-		// Try to initialize from PRD
 		project, err = svc.InitProject(prdPath)
 		if err != nil {
-			return fmt.Errorf("could not load project: %w", err)
+			return nil, fmt.Errorf("could not load project: %w", err)
 		}
-		fmt.Printf("Initialized project: %s\n", project.Name)
+		printf("Initialized project: %s\n", project.Name)
+	}
+
+	if ralphWorkDir != "" {
+		if project, err = svc.SetWorkDir(project.ID, ralphWorkDir); err != nil {
+			return nil, err
+		}
+	}
+	return project, nil
+}
+
+// ralphProjectOutputDir returns the directory a project's run artifacts go in
+// with --output-dir, or "" without it
+func ralphProjectOutputDir(project *domain.Project) string {
+	if ralphOutputDir == "" || !ralphOutputNested {
+		return ralphOutputDir
+	}
+	return filepath.Join(ralphOutputDir, project.ID)
+}
+
+// writeRalphRunReport saves the markdown report of a run in its --output-dir
+func writeRalphRunReport(project *domain.Project) error {
+	dir := ralphProjectOutputDir(project)
+	if dir == "" {
+		return nil
+	}
+	path := filepath.Join(dir, "report.md")
+	if err := os.WriteFile(path, []byte(renderRalphMarkdownReport(project)), 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// configureRalphService applies the run flags to a project service
+func configureRalphService(svc *service.ProjectService, project *domain.Project) error {
+	if dir := ralphProjectOutputDir(project); dir != "" {
+		recorder, err := adapters.NewTranscriptRecorder(dir)
+		if err != nil {
+			return err
+		}
+		svc.SetRecorder(recorder)
+	}
+	svc.SetStopOnFailure(ralphStopOnFailure)
+	svc.SetMaxPasses(ralphMaxPasses)
+	if ralphCommitPerStory {
+		committer := adapters.NewGitCommitter()
+		committer.SetPush(ralphPushPerStory)
+		svc.SetCommitter(committer)
+	}
+	return nil
+}
+
+// runRalphPRD runs a PRD's project in the TUI and returns its final state, and
+// whether the run finished rather than the user quitting part way through
+func runRalphPRD(prdPath string) (*domain.Project, bool, error) {
+	// Create service
+	svc, err := createRalphService()
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Try to load existing project, or initialize from PRD
+	project, err := loadRalphProject(svc, prdPath, func(format string, a ...any) { fmt.Printf(format, a...) })
+	if err != nil {
+		return nil, false, err
 	}
 
 	// Check if already complete
 	if project.IsComplete() {
-		fmt.Println("All stories already complete!")
-		return nil
+		fmt.Printf("%s: all stories already complete!\n", project.Name)
+		return project, true, nil
+	}
+
+	if err := configureRalphService(svc, project); err != nil {
+		return nil, false, err
 	}
 
 	// Run TUI
 	model := ui.NewModel(svc, project.ID)
-	p := tea.NewProgram(model, tea.WithAltScreen())
+	logTUIToFileIn(ralphProjectOutputDir(project))
+	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
 	finalModel, err := p.Run()
 	if err != nil {
-		return fmt.Errorf("TUI error: %w", err)
+		return nil, false, fmt.Errorf("TUI error: %w", err)
 	}
 
 	// Final status
+	finished := true
 	if m, ok := finalModel.(*ui.Model); ok {
-		project := m.GetProject()
-		if project != nil {
+		finished = m.IsComplete()
+		if m.GetProject() != nil {
+			project = m.GetProject()
 			fmt.Printf("\nProject: %s\n", project.Name)
 			fmt.Printf("Completed: %d/%d stories\n", project.CompletedStories(), project.TotalStories())
 			if project.IsComplete() {
@@ -220,10 +1039,256 @@ func runRalphProject(cmd *cobra.Command, args []string) error {
 			} else if project.HasFailures() {
 				fmt.Printf("%d stories failed\n", project.FailedStories())
 			}
+			printStalledStories(project, service.NewScheduler().GetStalledStories(project))
 		}
 	}
+	if err := writeRalphRunReport(project); err != nil {
+		return project, finished, err
+	}
+	if dir := ralphProjectOutputDir(project); dir != "" {
+		fmt.Printf("Run record: %s\n", dir)
+	}
 
-	return nil
+	return project, finished, nil
+}
+
+// ralphParallelRun is a PRD's project loaded for a --parallel run
+type ralphParallelRun struct {
+	index   int // Of the PRD in the arguments
+	prdPath string
+	svc     *service.ProjectService
+	project *domain.Project
+}
+
+// runRalphPRDsParallel runs every PRD's project at once without the TUI, printing
+// each story's progress prefixed with its project's name. Ctrl+C stops them all.
+// Projects that would edit each other's files in a shared checkout run one after
+// another instead (see ralphParallelLanes).
+func runRalphPRDsParallel(ctx context.Context, prdPaths []string) []ralphRunResult {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	results := make([]ralphRunResult, len(prdPaths))
+	var (
+		wg    sync.WaitGroup
+		outMu sync.Mutex
+	)
+	printf := func(format string, a ...any) {
+		outMu.Lock()
+		defer outMu.Unlock()
+		fmt.Printf(format, a...)
+	}
+
+	var runs []*ralphParallelRun
+	for i, prdPath := range prdPaths {
+		svc, err := createRalphService()
+		if err == nil {
+			var project *domain.Project
+			if project, err = loadRalphProject(svc, prdPath, printf); err == nil {
+				runs = append(runs, &ralphParallelRun{index: i, prdPath: prdPath, svc: svc, project: project})
+				continue
+			}
+		}
+		results[i] = ralphRunResult{PRD: prdPath, Err: err}
+	}
+
+	for _, lane := range ralphParallelLanes(runs, printf) {
+		wg.Add(1)
+		go func(lane []*ralphParallelRun) {
+			defer wg.Done()
+			for _, run := range lane {
+				if ctx.Err() != nil {
+					results[run.index] = ralphRunResult{PRD: run.prdPath, Project: run.project, Err: ctx.Err()}
+					continue
+				}
+				project, err := runRalphPRDHeadless(ctx, run.svc, run.project, printf)
+				results[run.index] = ralphRunResult{PRD: run.prdPath, Project: project, Err: err}
+			}
+		}(lane)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// ralphParallelLanes groups the runs into lanes that can run at the same time,
+// each running its projects one after another. Projects whose work dirs are in
+// the same checkout share a lane unless every unfinished story declares a scope
+// and none overlap, since their Claude runs would edit each other's files;
+// with --commit-per-story, each commit would take the other project's changes too.
+func ralphParallelLanes(runs []*ralphParallelRun, printf func(format string, a ...any)) [][]*ralphParallelRun {
+	scheduler := service.NewScheduler()
+	checkouts := make(map[*ralphParallelRun]string, len(runs))
+	for _, run := range runs {
+		checkouts[run] = ralphCheckout(run.project.WorkDir)
+	}
+
+	var lanes [][]*ralphParallelRun
+	for _, run := range runs {
+		// Merge every lane the run conflicts with into one, followed by the run
+		var merged []*ralphParallelRun
+		kept := lanes[:0]
+		for _, lane := range lanes {
+			if reason := ralphLaneConflict(scheduler, lane, run, checkouts); reason != "" {
+				printf("[%s] %s; running it after %s\n", run.project.Name, reason, lane[len(lane)-1].project.Name)
+				merged = append(merged, lane...)
+				continue
+			}
+			kept = append(kept, lane)
+		}
+		lanes = append(kept, append(merged, run))
+	}
+	return lanes
+}
+
+// ralphLaneConflict returns why run can't run alongside a project of the lane,
+// or "" if it can
+func ralphLaneConflict(scheduler *service.Scheduler, lane []*ralphParallelRun, run *ralphParallelRun, checkouts map[*ralphParallelRun]string) string {
+	if run.project.IsComplete() {
+		return ""
+	}
+	for _, other := range lane {
+		if other.project.IsComplete() || checkouts[other] != checkouts[run] {
+			continue
+		}
+		if ralphCommitPerStory {
+			return fmt.Sprintf("shares %s with %s and commits per story", checkouts[run], other.project.Name)
+		}
+		story, otherStory := scheduler.ConflictingStories(run.project, other.project)
+		if story == nil {
+			continue
+		}
+		switch {
+		case len(story.Scope()) == 0:
+			return fmt.Sprintf("shares %s with %s, and story %s declares no scope", checkouts[run], other.project.Name, story.ID)
+		case len(otherStory.Scope()) == 0:
+			return fmt.Sprintf("shares %s with %s, whose story %s declares no scope", checkouts[run], other.project.Name, otherStory.ID)
+		}
+		return fmt.Sprintf("shares %s with %s, and story %s's scope overlaps its story %s's", checkouts[run], other.project.Name, story.ID, otherStory.ID)
+	}
+	return ""
+}
+
+// ralphCheckout returns the root of the git checkout dir is in, or dir itself outside one
+func ralphCheckout(dir string) string {
+	if root := gitOutput("-C", dir, "rev-parse", "--show-toplevel"); root != "" {
+		return root
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		return abs
+	}
+	return dir
+}
+
+// runRalphPRDHeadless runs a loaded project to the end, reporting story events through printf
+func runRalphPRDHeadless(ctx context.Context, svc *service.ProjectService, project *domain.Project, printf func(format string, a ...any)) (*domain.Project, error) {
+	if project.IsComplete() {
+		printf("[%s] all stories already complete\n", project.Name)
+		return project, nil
+	}
+
+	if err := configureRalphService(svc, project); err != nil {
+		return project, err
+	}
+	events, err := svc.RunProject(ctx, project.ID)
+	if err != nil {
+		return project, err
+	}
+
+	for event := range events {
+		if line := ralphEventLine(event); line != "" {
+			printf("[%s] %s\n", project.Name, line)
+		}
+	}
+
+	// RunProject saved the final state
+	if updated, err := svc.GetProject(project.ID); err == nil {
+		project = updated
+	}
+	if err := writeRalphRunReport(project); err != nil {
+		return project, err
+	}
+	if dir := ralphProjectOutputDir(project); dir != "" {
+		printf("[%s] run record: %s\n", project.Name, dir)
+	}
+	return project, nil
+}
+
+// ralphEventLine describes a story or project event on one line for headless runs,
+// or returns "" for the thoughts and tool calls in between
+func ralphEventLine(event domain.ExecutionEvent) string {
+	content, _, _ := strings.Cut(strings.TrimSpace(event.Content), "\n")
+	switch event.Type {
+	case domain.EventTypeStoryStarted:
+		return fmt.Sprintf("▶ %s: %s", event.StoryID, content)
+	case domain.EventTypeStoryCompleted:
+		return fmt.Sprintf("✓ %s: %s", event.StoryID, content)
+	case domain.EventTypeStoryFailed:
+		return fmt.Sprintf("✗ %s failed: %s", event.StoryID, content)
+	case domain.EventTypeProjectComplete:
+		return "✓ all stories complete"
+	case domain.EventTypeProjectFailed:
+		return "✗ " + content
+	case domain.EventTypeError:
+		if event.StoryID != "" {
+			return fmt.Sprintf("! %s: %s", event.StoryID, content)
+		}
+		return "! " + content
+	}
+	return ""
+}
+
+// sortedStoryIDs returns the story IDs of a map in order
+func sortedStoryIDs(stories map[string]string) []string {
+	ids := make([]string, 0, len(stories))
+	for id := range stories {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// printRalphRunSummary prints one line per PRD of a multi-PRD run and returns an
+// exit code like `ralph status`: 2 if any story failed or a PRD couldn't run,
+// 3 if stories remain, 0 when everything is complete
+func printRalphRunSummary(cmd *cobra.Command, prdPaths []string, results []ralphRunResult) error {
+	fmt.Printf("\nSummary (%d PRDs)\n", len(prdPaths))
+
+	code := 0
+	for i, prdPath := range prdPaths {
+		if i >= len(results) {
+			fmt.Printf("  - %s: not run\n", prdPath)
+			code = max(code, 3)
+			continue
+		}
+
+		result := results[i]
+		project := result.Project
+		switch {
+		case result.Err != nil:
+			fmt.Printf("  ✗ %s: %v\n", prdPath, result.Err)
+			code = 2
+		case project.IsComplete():
+			fmt.Printf("  ✓ %s: %d/%d stories\n", project.Name, project.CompletedStories(), project.TotalStories())
+		case project.HasFailures() || project.Status == domain.ProjectStatusFailed:
+			line := fmt.Sprintf("  ✗ %s: %d/%d stories, %d failed", project.Name, project.CompletedStories(), project.TotalStories(), project.FailedStories())
+			if stalled := service.NewScheduler().GetStalledStories(project); len(stalled) > 0 {
+				line += fmt.Sprintf(", %d stalled (%s)", len(stalled), strings.Join(sortedStoryIDs(stalled), ", "))
+			}
+			fmt.Println(line)
+			code = 2
+		default:
+			fmt.Printf("  ◐ %s: %d/%d stories\n", project.Name, project.CompletedStories(), project.TotalStories())
+			code = max(code, 3)
+		}
+	}
+
+	if code == 0 {
+		return nil
+	}
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	return &exitCodeError{code: code}
 }
 
 // runRalphList lists all projects
@@ -260,19 +1325,159 @@ func runRalphList(cmd *cobra.Command, args []string) error {
 		}
 
 		fmt.Printf("  %s\n", p.Name)
+		fmt.Printf("    ID: %s\n", p.ID)
 		fmt.Printf("    Status: %s (%d/%d stories)\n", status, p.CompletedStories, p.TotalStories)
 		fmt.Printf("    PRD: %s\n", p.PRDPath)
 		fmt.Printf("    Updated: %s\n\n", p.UpdatedAt)
 	}
 
+	fmt.Println("Remove a project with 'dtools ralph delete <id>' or clear finished ones with --all-completed.")
+
 	return nil
 }
 
+// runRalphRefresh merges PRD edits into a saved project
+func runRalphRefresh(cmd *cobra.Command, args []string) error {
+	prdPath := ralphPRDFile
+	if len(args) > 0 {
+		prdPath = args[0]
+	}
+
+	svc, err := createRalphService()
+	if err != nil {
+		return err
+	}
+
+	project, err := svc.GetProject(prdPath)
+	if err != nil {
+		return fmt.Errorf("could not load project (run 'dtools ralph run' or 'status' to create it first): %w", err)
+	}
+
+	project, report, err := svc.RefreshProject(project.ID)
+	if err != nil {
+		return fmt.Errorf("could not refresh project: %w", err)
+	}
+
+	if !report.HasChanges() {
+		fmt.Printf("%s is up to date with %s\n", project.Name, project.PRDPath)
+		return nil
+	}
+
+	fmt.Printf("Refreshed %s from %s\n", project.Name, project.PRDPath)
+	printStoryIDs("Added", report.Added)
+	printStoryIDs("Removed", report.Removed)
+	printStoryIDs("Changed", report.Changed)
+	printStoryIDs("Reset to pending (dependencies changed)", report.Reset)
+	fmt.Printf("\nProgress: %d/%d stories completed\n", project.CompletedStories(), project.TotalStories())
+
+	return nil
+}
+
+// printStoryIDs prints a labelled list of story IDs, skipping empty lists
+func printStoryIDs(label string, ids []string) {
+	if len(ids) == 0 {
+		return
+	}
+	fmt.Printf("  %s: %s\n", label, strings.Join(ids, ", "))
+}
+
+// runRalphDelete removes one project, or all completed projects
+func runRalphDelete(cmd *cobra.Command, args []string) error {
+	if ralphDeleteAllCompleted == (len(args) > 0) {
+		return fmt.Errorf("specify either a project ID or --all-completed")
+	}
+
+	svc, err := createRalphService()
+	if err != nil {
+		return err
+	}
+
+	// Collect the projects to delete
+	var targets []ports.ProjectInfo
+	if ralphDeleteAllCompleted {
+		projects, err := svc.ListProjects()
+		if err != nil {
+			return err
+		}
+		for _, p := range projects {
+			if p.Status == domain.ProjectStatusCompleted {
+				targets = append(targets, p)
+			}
+		}
+		if len(targets) == 0 {
+			fmt.Println("No completed projects to delete.")
+			return nil
+		}
+	} else {
+		project, err := svc.GetProject(args[0])
+		if err != nil {
+			return fmt.Errorf("could not find project: %w", err)
+		}
+		targets = append(targets, ports.ProjectInfo{ID: project.ID, Name: project.Name, PRDPath: project.PRDPath})
+	}
+
+	fmt.Println("Projects to delete:")
+	for _, p := range targets {
+		fmt.Printf("  %s (%s)\n", p.Name, p.ID)
+	}
+
+	if !ralphDeleteYes {
+		confirmed, err := dtui.Confirm(fmt.Sprintf("Delete %d project(s)? PRD files are kept.", len(targets)))
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	for _, p := range targets {
+		if err := svc.DeleteProject(p.ID); err != nil {
+			return fmt.Errorf("could not delete %s: %w", p.ID, err)
+		}
+		fmt.Printf("Deleted %s\n", p.ID)
+	}
+
+	return nil
+}
+
+// ralphClaudeEnv collects the --env-file and --env assignments for the Claude
+// process, with --env taking precedence
+func ralphClaudeEnv() ([]string, error) {
+	var env []string
+	if ralphEnvFile != "" {
+		fileEnv, err := envfile.Read(ralphEnvFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read env file: %w", err)
+		}
+		env = append(env, fileEnv...)
+	}
+	for _, assignment := range ralphEnv {
+		kv, err := envfile.ParseAssignment(assignment)
+		if err != nil {
+			return nil, err
+		}
+		env = append(env, kv)
+	}
+	return env, nil
+}
+
 // createRalphService creates the project service with all dependencies
 func createRalphService() (*service.ProjectService, error) {
 	// Create adapters
 	parser := adapters.NewMarkdownPRDParser(ports.DefaultPRDParseOptions())
-	executor := adapters.NewClaudeExecutor()
+	command, err := resolveAICommand()
+	if err != nil {
+		return nil, err
+	}
+	executor := adapters.NewClaudeExecutorWithCommand(command)
+	executor.SetTimeout(ralphTimeout)
+	env, err := ralphClaudeEnv()
+	if err != nil {
+		return nil, err
+	}
+	executor.SetEnv(env)
 	repo, err := adapters.NewJSONRepository()
 	if err != nil {
 		return nil, fmt.Errorf("could not create repository: %w", err)
@@ -1,28 +1,60 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"sort"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 
+	"github.com/DylanSharp/dtools/internal/aicmd"
 	"github.com/DylanSharp/dtools/internal/coderabbit/adapters"
+	"github.com/DylanSharp/dtools/internal/coderabbit/config"
+	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
+	"github.com/DylanSharp/dtools/internal/coderabbit/ports"
 	"github.com/DylanSharp/dtools/internal/coderabbit/service"
 	"github.com/DylanSharp/dtools/internal/coderabbit/ui"
 )
 
 var (
 	reviewPRNumber         int
+	reviewBranch           string
+	reviewSince            string
+	reviewBots             []string
 	reviewWatchMode        bool
 	reviewIncludeNits      bool
 	reviewIncludeOutdated  bool
 	reviewPollInterval     int
 	reviewCooldownDuration int
+	reviewBatchWait        int
+	reviewBatchWaitMax     int
+	reviewAdaptiveBatch    bool
 	reviewNoManualConfirm  bool
+	reviewConfirmFirst     bool
 	reviewResetState       bool
 	reviewMarkAddressed    bool
+	reviewApplySuggestions bool
+	reviewAllowClosed      bool
+	reviewRerunFailedCI    bool
 	reviewDebug            bool
+	reviewDumpPrompt       bool
+	reviewPromptTemplate   string
+	reviewMaxComments      int
+	reviewMaxIterations    int
+	reviewHistoryFile      string
+	reviewWithDiff         bool
+	reviewMaxDiffMb        float64
+	reviewMaxPromptKb      float64
+	reviewIncludeSummary   bool
+	reviewIncludePRContext bool
+	reviewListJSON         bool
+	reviewNoReply          bool
+	reviewTimeout          time.Duration
 )
 
 var reviewCmd = &cobra.Command{
@@ -34,33 +66,170 @@ This tool fetches CodeRabbit review comments from a GitHub PR, generates
 a prompt for Claude, and displays Claude's analysis in a terminal UI.
 
 In watch mode, it continuously monitors for new comments and CI failures,
-automatically triggering Claude reviews until CodeRabbit is satisfied.`,
+automatically triggering Claude reviews until CodeRabbit is satisfied.
+Watching stops once the PR is merged or closed; merged and closed PRs are
+only reviewed with --allow-closed.
+Restarting a watch session resumes the last one's cooldown and what it had
+already seen, so Claude isn't rerun on the same comments; --reset clears it.
+
+The prompt tells Claude which formatters, linters and tests to run based on
+the repository's language (Go, Python, Rust or JavaScript). Set
+prompt_instructions in the review config file to replace them.
+
+To change the whole prompt, point --prompt-template (or prompt_template in
+the config file) at a Go text/template. It's executed with the review, so
+fields like .Comments, .CIFailures and .Title are available, along with
+.Instructions, .DeclinedMarker and .Default (the built-in prompt).
+.Description holds the PR description when --include-pr-context is set.
+
+GitHub is reached through the gh CLI. Where gh isn't installed, such as in
+containers and CI, set GH_TOKEN or GITHUB_TOKEN and dtools calls GitHub's
+API directly (GITHUB_API_URL points it at GitHub Enterprise Server).`,
 	Example: `  # Review current branch's PR
   dtools review
 
   # Review specific PR
   dtools review 123
 
+  # Only review comments added since a commit, without relying on state
+  dtools review 123 --watch=false --since abc1234
+
+  # Review the PR for another branch
+  dtools review --branch feature/login
+
   # Watch mode with auto-review
   dtools review --watch
 
+  # Unattended watch mode, but approve the first batch before Claude edits anything
+  dtools review --watch --no-manual-confirm --confirm-first
+
+  # Give failed CI one rerun before sending it to Claude, in case it's flaky
+  dtools review --watch --rerun-failed-ci
+
+  # Print the prompt Claude would get, without running it
+  dtools review 123 --dump-prompt --include-nits=false > prompt.md
+
+  # Render the prompt with a team template and check the result
+  dtools review --prompt-template .github/review-prompt.tmpl --dump-prompt
+
+  # Work through a large review 15 comments at a time
+  dtools review --max-comments 15
+
+  # Give up after 5 rounds with CodeRabbit rather than burning tokens
+  dtools review --watch --max-iterations 5
+
+  # Keep a record of every watch iteration (press h in the TUI to see this session's)
+  dtools review --watch --history-file ~/review-history.jsonl
+
+  # Address leftover comments after the PR was merged
+  dtools review 123 --watch=false --allow-closed
+
   # Watch mode with custom settings
   dtools review --watch --poll-interval 30 --cooldown 120`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runReview,
 }
 
+var reviewListCmd = &cobra.Command{
+	Use:   "list [pr-number]",
+	Short: "List CodeRabbit comments and CI status without running Claude",
+	Long: `List all CodeRabbit comments on a PR grouped by file, including
+resolved, nitpick and outdated flags, along with the current CI status.
+
+Claude is not invoked and no state is modified.`,
+	Example: `  # List comments on the current branch's PR
+  dtools review list
+
+  # JSON output for scripting
+  dtools review list 123 --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runReviewList,
+}
+
+var reviewCICmd = &cobra.Command{
+	Use:   "ci [pr-number]",
+	Short: "List the CI checks and workflow runs on a PR",
+	Long: `List every CI check on a PR with its workflow, status and conclusion,
+and a link to its run.
+
+Claude is not invoked and no state is modified.`,
+	Example: `  # List CI checks on the current branch's PR
+  dtools review ci
+
+  # Rerun the failed GitHub Actions runs on PR 123
+  dtools review ci rerun 123`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runReviewCI,
+}
+
+var reviewCIRerunCmd = &cobra.Command{
+	Use:   "rerun [pr-number]",
+	Short: "Rerun the failed jobs of failed GitHub Actions runs on a PR",
+	Long: `Rerun the failed jobs of every failed GitHub Actions workflow run on a
+PR, for when a failure looks flaky. Checks from other CI apps can't be rerun
+and are skipped.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runReviewCIRerun,
+}
+
+var reviewStateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Manage stored comment state",
+	Long: `Manage the processed-comment state kept per repository in
+$XDG_CONFIG_HOME/dtools/review-state/ (~/.config/dtools/review-state/ by
+default, $DTOOLS_STATE_DIR/review-state/ if set).`,
+}
+
+var reviewStatePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Drop stored state for closed or merged PRs",
+	Long: `Check every PR with stored comment state and drop the entries for
+PRs that are no longer open. PRs whose status can't be fetched are kept.`,
+	Args: cobra.NoArgs,
+	RunE: runReviewStatePrune,
+}
+
 func init() {
+	reviewListCmd.Flags().BoolVar(&reviewListJSON, "json", false, "Output as JSON")
+	reviewCmd.AddCommand(reviewListCmd)
+	reviewCICmd.AddCommand(reviewCIRerunCmd)
+	reviewCmd.AddCommand(reviewCICmd)
+	reviewStateCmd.AddCommand(reviewStatePruneCmd)
+	reviewCmd.AddCommand(reviewStateCmd)
+
+	reviewCmd.PersistentFlags().StringSliceVar(&reviewBots, "reviewer-bot", nil,
+		"Reviewer bot login to match, repeatable (default: reviewer_bots from "+config.Path()+", else coderabbit)")
 	reviewCmd.Flags().IntVarP(&reviewPRNumber, "pr", "p", 0, "PR number (auto-detected if not specified)")
+	reviewCmd.Flags().StringVarP(&reviewBranch, "branch", "b", "", "Review the open PR for this head branch instead of the current branch's")
 	reviewCmd.Flags().BoolVarP(&reviewWatchMode, "watch", "w", true, "Enable watch mode for continuous review (use --watch=false for single run)")
 	reviewCmd.Flags().BoolVar(&reviewIncludeNits, "include-nits", true, "Include nitpick comments")
 	reviewCmd.Flags().BoolVar(&reviewIncludeOutdated, "include-outdated", true, "Include outdated comments")
 	reviewCmd.Flags().IntVar(&reviewPollInterval, "poll-interval", 15, "Watch mode poll interval in seconds")
 	reviewCmd.Flags().IntVar(&reviewCooldownDuration, "cooldown", 180, "Watch mode cooldown after review in seconds")
+	reviewCmd.Flags().IntVar(&reviewBatchWait, "batch-wait", 30, "Watch mode wait for more comments before processing, in seconds")
+	reviewCmd.Flags().IntVar(&reviewBatchWaitMax, "batch-wait-max", 120, "Watch mode cap on an adaptive batch wait, in seconds")
+	reviewCmd.Flags().BoolVar(&reviewAdaptiveBatch, "adaptive-batch", true, "Keep extending the batch wait while comments are still arriving (use --adaptive-batch=false for a fixed wait)")
 	reviewCmd.Flags().BoolVar(&reviewNoManualConfirm, "no-manual-confirm", false, "Skip manual confirmation in watch mode")
+	reviewCmd.Flags().BoolVar(&reviewConfirmFirst, "confirm-first", false, "Watch mode: show the first batch of comments and CI failures and wait for approval before Claude edits, then run unattended")
+	reviewCmd.Flags().StringVar(&reviewSince, "since", "", "Only review comments created after this commit (SHA)")
 	reviewCmd.Flags().BoolVar(&reviewResetState, "reset", false, "Reset state and re-process all comments")
 	reviewCmd.Flags().BoolVar(&reviewMarkAddressed, "mark-addressed", true, "Mark comments as resolved on GitHub after addressing")
+	reviewCmd.Flags().BoolVar(&reviewApplySuggestions, "apply-suggestions", false, "Apply trivial CodeRabbit suggestions with git apply before invoking Claude")
+	reviewCmd.Flags().BoolVar(&reviewAllowClosed, "allow-closed", false, "Review a merged or closed PR, and keep watching one (for post-merge cleanup)")
+	reviewCmd.Flags().BoolVar(&reviewRerunFailedCI, "rerun-failed-ci", false, "Watch mode: rerun each failed GitHub Actions run once before treating it as a real failure")
+	reviewCmd.Flags().BoolVar(&reviewWithDiff, "with-diff", false, "Include the PR diff for commented files in the prompt")
+	reviewCmd.Flags().Float64Var(&reviewMaxDiffMb, "max-diff-mb", 1, "Maximum size of the diff included in the prompt, in MB")
+	reviewCmd.Flags().Float64Var(&reviewMaxPromptKb, "max-prompt-kb", 256, "Maximum total prompt size in KB; long comments and background context are truncated to fit")
+	reviewCmd.Flags().IntVar(&reviewMaxIterations, "max-iterations", 0, "Stop watching after this many Claude runs without CodeRabbit being satisfied (0 means no limit)")
+	reviewCmd.Flags().IntVar(&reviewMaxComments, "max-comments", 0, "Address at most this many comments per Claude run, working through the rest in later batches (0 means no cap)")
+	reviewCmd.Flags().StringVar(&reviewHistoryFile, "history-file", "", "Watch mode: append each iteration (trigger, commit, counts, outcome) to this file as JSON lines")
+	reviewCmd.Flags().BoolVar(&reviewIncludeSummary, "include-summary", false, "Include CodeRabbit's walkthrough/summary as background context")
+	reviewCmd.Flags().BoolVar(&reviewIncludePRContext, "include-pr-context", false, "Include the PR title and description as background context")
+	reviewCmd.Flags().BoolVar(&reviewNoReply, "no-reply", false, "Don't reply to comments Claude declines to address")
+	reviewCmd.Flags().DurationVar(&reviewTimeout, "timeout", aicmd.DefaultTimeout, "Maximum time for a single Claude review run (0 disables)")
 	reviewCmd.Flags().BoolVar(&reviewDebug, "debug", false, "Print debug info about comments without starting TUI")
+	reviewCmd.Flags().StringVar(&reviewPromptTemplate, "prompt-template", "", "text/template file that renders Claude's prompt instead of the built-in one (default: prompt_template from the config file)")
+	reviewCmd.Flags().BoolVar(&reviewDumpPrompt, "dump-prompt", false, "Print the prompt Claude would receive and exit without invoking Claude")
 	rootCmd.AddCommand(reviewCmd)
 }
 
@@ -74,35 +243,98 @@ func runReview(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create adapters
-	githubClient := adapters.NewGitHubCLIClient()
-	ciProvider := adapters.NewGitHubCIAdapter()
-	claudeClient := adapters.NewClaudeClient()
+	githubClient, ciProvider, err := newGitHubAdapters()
+	if err != nil {
+		return err
+	}
+	command, err := resolveAICommand()
+	if err != nil {
+		return err
+	}
+	claudeClient := adapters.NewClaudeClientWithCommand(command)
+	claudeClient.SetTimeout(reviewTimeout)
 
-	// Check if Claude is available
-	if !claudeClient.IsAvailable() {
+	// Check if the AI CLI is available; dumping the prompt or debug info doesn't run it
+	runsAI := !reviewDumpPrompt && !reviewDebug
+	if runsAI && !claudeClient.IsAvailable() {
+		if aiCommand != "" {
+			return fmt.Errorf("AI command %q not found in PATH", command.Binary)
+		}
 		return fmt.Errorf("Claude CLI not found. Please install Claude Code first.")
 	}
+	if runsAI {
+		if err := confirmSkipPermissions(command); err != nil {
+			return err
+		}
+		checkAIVersion(cmd.Context(), command)
+	}
+
+	if err := githubClient.CheckAuth(cmd.Context()); err != nil {
+		return err
+	}
 
 	// Create review service
 	reviewService := service.NewReviewService(githubClient, ciProvider, claudeClient)
+	if err := configureReviewPrompt(cmd.Context(), reviewService); err != nil {
+		return err
+	}
 
-	// Auto-detect PR if not specified
-	if reviewPRNumber == 0 {
+	// Keep stdout for the prompt alone when dumping it, so it can be piped
+	status := os.Stdout
+	if reviewDumpPrompt {
+		status = os.Stderr
+	}
+
+	// Resolve the PR from --branch, or auto-detect it if not specified
+	if reviewBranch != "" {
+		if reviewPRNumber != 0 {
+			return fmt.Errorf("--branch can't be combined with a PR number")
+		}
+		detected, err := reviewService.DetectPRForBranch(cmd.Context(), reviewBranch)
+		if err != nil {
+			return err
+		}
+		reviewPRNumber = detected
+		fmt.Fprintf(status, "Found PR #%d for branch %s\n", reviewPRNumber, reviewBranch)
+	} else if reviewPRNumber == 0 {
 		detected, err := reviewService.DetectCurrentPR(cmd.Context())
 		if err != nil {
 			return fmt.Errorf("could not detect PR number: %w\nUse --pr flag to specify the PR number", err)
 		}
 		reviewPRNumber = detected
-		fmt.Printf("Detected PR #%d\n", reviewPRNumber)
+		fmt.Fprintf(status, "Detected PR #%d\n", reviewPRNumber)
 	}
 
 	// Create config
 	config := service.ReviewConfig{
-		PRNumber:        reviewPRNumber,
-		IncludeNits:     reviewIncludeNits,
-		IncludeOutdated: reviewIncludeOutdated,
-		ResetState:      reviewResetState,
-		MarkAddressed:   reviewMarkAddressed,
+		PRNumber:         reviewPRNumber,
+		IncludeNits:      reviewIncludeNits,
+		IncludeOutdated:  reviewIncludeOutdated,
+		ResetState:       reviewResetState,
+		MarkAddressed:    reviewMarkAddressed,
+		WithDiff:         reviewWithDiff,
+		MaxDiffMb:        reviewMaxDiffMb,
+		MaxPromptKb:      reviewMaxPromptKb,
+		IncludeSummary:   reviewIncludeSummary,
+		IncludePRContext: reviewIncludePRContext,
+		ReplyToDeclined:  !reviewNoReply,
+		Since:            reviewSince,
+		ApplySuggestions: reviewApplySuggestions,
+		AllowClosed:      reviewAllowClosed,
+		MaxComments:      reviewMaxComments,
+	}
+
+	// Dump mode - print the prompt Claude would receive without running it
+	if reviewDumpPrompt {
+		prompt, review, err := reviewService.BuildPrompt(cmd.Context(), config)
+		if err != nil {
+			return fmt.Errorf("failed to build prompt: %w", err)
+		}
+		if len(review.Comments) == 0 && len(review.CIFailures) == 0 {
+			fmt.Fprintln(os.Stderr, "No unprocessed comments or CI failures; Claude wouldn't be invoked")
+		}
+		fmt.Print(prompt)
+		return nil
 	}
 
 	// Debug mode - print what would be processed without TUI
@@ -113,6 +345,7 @@ func runReview(cmd *cobra.Command, args []string) error {
 		}
 
 		fmt.Printf("\n=== DEBUG: PR #%d ===\n", review.PRNumber)
+		fmt.Printf("Head commit: %s\n", review.HeadCommit)
 		fmt.Printf("Total comments found: %d\n", review.TotalFoundCount)
 		fmt.Printf("Already addressed: %d\n", review.AlreadyAddressed)
 		fmt.Printf("New comments to process: %d\n", review.NewCommentsCount)
@@ -140,9 +373,27 @@ func runReview(cmd *cobra.Command, args []string) error {
 		watchOpts := service.WatchOptions{
 			PollInterval:         time.Duration(reviewPollInterval) * time.Second,
 			CooldownDuration:     time.Duration(reviewCooldownDuration) * time.Second,
+			BatchWaitDuration:    time.Duration(reviewBatchWait) * time.Second,
+			BatchWaitMax:         time.Duration(reviewBatchWaitMax) * time.Second,
+			AdaptiveBatchWait:    reviewAdaptiveBatch,
 			RequireManualConfirm: !reviewNoManualConfirm,
 			IncludeNits:          reviewIncludeNits,
 			IncludeOutdated:      reviewIncludeOutdated,
+			WithDiff:             reviewWithDiff,
+			MaxDiffMb:            reviewMaxDiffMb,
+			MaxPromptKb:          reviewMaxPromptKb,
+			IncludeSummary:       reviewIncludeSummary,
+			IncludePRContext:     reviewIncludePRContext,
+			ReplyToDeclined:      !reviewNoReply,
+			Since:                reviewSince,
+			ApplySuggestions:     reviewApplySuggestions,
+			AllowClosed:          reviewAllowClosed,
+			RerunFailedCI:        reviewRerunFailedCI,
+			ConfirmFirstBatch:    reviewConfirmFirst,
+			MaxComments:          reviewMaxComments,
+			HistoryFile:          reviewHistoryFile,
+			ResetState:           reviewResetState,
+			MaxIterations:        reviewMaxIterations,
 		}
 		model = ui.NewWatchModel(reviewService, config, watchOpts)
 	} else {
@@ -150,7 +401,8 @@ func runReview(cmd *cobra.Command, args []string) error {
 	}
 
 	// Run the TUI
-	p := tea.NewProgram(model, tea.WithAltScreen())
+	logTUIToFile()
+	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
 	finalModel, err := p.Run()
 	if err != nil {
 		return fmt.Errorf("TUI error: %w", err)
@@ -160,7 +412,11 @@ func runReview(cmd *cobra.Command, args []string) error {
 	if m, ok := finalModel.(*ui.Model); ok {
 		review := m.GetReview()
 		if review != nil {
-			fmt.Printf("\nReview complete for PR #%d\n", review.PRNumber)
+			fmt.Printf("\nReview complete for PR #%d", review.PRNumber)
+			if commit := review.ShortCommit(); commit != "" {
+				fmt.Printf(" at %s", commit)
+			}
+			fmt.Println()
 			if review.Satisfied {
 				fmt.Println("CodeRabbit is satisfied!")
 			}
@@ -169,3 +425,340 @@ func runReview(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// reviewListComment is the JSON representation of a comment in `review list`
+type reviewListComment struct {
+	ID          int    `json:"id"`
+	File        string `json:"file"`
+	Line        int    `json:"line,omitempty"`
+	EndLine     int    `json:"end_line,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Resolved    bool   `json:"resolved"`
+	Nit         bool   `json:"nit"`
+	Outdated    bool   `json:"outdated"`
+	OutsideDiff bool   `json:"outside_diff"`
+	Body        string `json:"body"`
+	Suggestion  string `json:"suggestion,omitempty"`
+}
+
+// reviewListOutput is the JSON output of `review list`
+type reviewListOutput struct {
+	PRNumber int                 `json:"pr_number"`
+	Comments []reviewListComment `json:"comments"`
+	CI       struct {
+		Passed   int      `json:"passed"`
+		Pending  []string `json:"pending"`
+		Failures []string `json:"failures"`
+		Total    int      `json:"total"`
+	} `json:"ci"`
+}
+
+// newGitHubAdapters creates the GitHub adapters, matching the reviewer bots from
+// --reviewer-bot, the config file, or the CodeRabbit default, in that order
+func newGitHubAdapters() (adapters.GitHub, ports.CIProvider, error) {
+	bots := domain.ReviewerBots(reviewBots)
+	if len(bots) == 0 {
+		cfg, err := config.Load()
+		if err != nil {
+			return nil, nil, err
+		}
+		bots = cfg.ReviewerBots
+	}
+
+	client, ciProvider := adapters.NewGitHubAdapters(bots)
+	return client, ciProvider, nil
+}
+
+// configureReviewPrompt sets up the review prompt from the config file and
+// --prompt-template, which takes precedence over prompt_template
+func configureReviewPrompt(ctx context.Context, reviewService *service.ReviewService) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	return reviewService.ConfigurePrompt(ctx, cfg, reviewPromptTemplate)
+}
+
+// newCIReviewService builds a review service for the CI subcommands and
+// resolves the PR from args, auto-detecting it if not given
+func newCIReviewService(cmd *cobra.Command, args []string) (*service.ReviewService, int, error) {
+	prNumber := 0
+	if len(args) > 0 {
+		if _, err := fmt.Sscanf(args[0], "%d", &prNumber); err != nil {
+			return nil, 0, fmt.Errorf("invalid PR number: %s", args[0])
+		}
+	}
+
+	githubClient, ciProvider, err := newGitHubAdapters()
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := githubClient.CheckAuth(cmd.Context()); err != nil {
+		return nil, 0, err
+	}
+
+	reviewService := service.NewReviewService(
+		githubClient,
+		ciProvider,
+		adapters.NewClaudeClient(),
+	)
+
+	if prNumber == 0 {
+		detected, err := reviewService.DetectCurrentPR(cmd.Context())
+		if err != nil {
+			return nil, 0, fmt.Errorf("could not detect PR number: %w\nSpecify the PR number as an argument", err)
+		}
+		prNumber = detected
+	}
+
+	return reviewService, prNumber, nil
+}
+
+// runReviewCI prints the CI checks and workflow runs on a PR
+func runReviewCI(cmd *cobra.Command, args []string) error {
+	reviewService, prNumber, err := newCIReviewService(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	runs, err := reviewService.GetWorkflowRuns(cmd.Context(), prNumber)
+	if err != nil {
+		return fmt.Errorf("failed to get workflow runs: %w", err)
+	}
+
+	if len(runs) == 0 {
+		fmt.Printf("PR #%d: no CI checks\n", prNumber)
+		return nil
+	}
+
+	fmt.Printf("PR #%d: %d CI check(s)\n\n", prNumber, len(runs))
+	failed := 0
+	for _, run := range runs {
+		status := run.Status
+		if run.Conclusion != "" {
+			status = run.Conclusion
+		}
+		if run.IsFailed() {
+			failed++
+		}
+		name := run.Name
+		if run.Workflow != "" {
+			name = run.Workflow + " / " + run.Name
+		}
+		fmt.Printf("  %-12s %s\n", status, name)
+		if run.LogURL != "" {
+			fmt.Printf("  %-12s %s\n", "", run.LogURL)
+		}
+	}
+
+	if failed > 0 {
+		fmt.Printf("\n%d failed; rerun with: dtools review ci rerun %d\n", failed, prNumber)
+	}
+	return nil
+}
+
+// runReviewCIRerun reruns the failed GitHub Actions runs on a PR
+func runReviewCIRerun(cmd *cobra.Command, args []string) error {
+	reviewService, prNumber, err := newCIReviewService(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	rerun, err := reviewService.RerunFailedWorkflows(cmd.Context(), prNumber)
+	for _, run := range rerun {
+		name := run.Workflow
+		if name == "" {
+			name = run.Name
+		}
+		fmt.Printf("  Rerunning %s (run %d)\n", name, run.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to rerun workflows: %w", err)
+	}
+
+	if len(rerun) == 0 {
+		fmt.Printf("PR #%d: no failed workflow runs to rerun\n", prNumber)
+		return nil
+	}
+	fmt.Printf("Rerunning failed jobs in %d workflow run(s) on PR #%d\n", len(rerun), prNumber)
+	return nil
+}
+
+// runReviewList prints CodeRabbit comments and CI status for a PR
+func runReviewList(cmd *cobra.Command, args []string) error {
+	prNumber := 0
+	if len(args) > 0 {
+		if _, err := fmt.Sscanf(args[0], "%d", &prNumber); err != nil {
+			return fmt.Errorf("invalid PR number: %s", args[0])
+		}
+	}
+
+	githubClient, ciProvider, err := newGitHubAdapters()
+	if err != nil {
+		return err
+	}
+	if err := githubClient.CheckAuth(cmd.Context()); err != nil {
+		return err
+	}
+
+	reviewService := service.NewReviewService(
+		githubClient,
+		ciProvider,
+		adapters.NewClaudeClient(),
+	)
+
+	if prNumber == 0 {
+		detected, err := reviewService.DetectCurrentPR(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("could not detect PR number: %w\nSpecify the PR number as an argument", err)
+		}
+		prNumber = detected
+	}
+
+	comments, ciStatus, err := reviewService.ListAllComments(cmd.Context(), prNumber)
+	if err != nil {
+		return fmt.Errorf("failed to list comments: %w", err)
+	}
+
+	if reviewListJSON {
+		return printReviewListJSON(prNumber, comments, ciStatus)
+	}
+
+	fmt.Printf("PR #%d: %d CodeRabbit comment(s)\n", prNumber, len(comments))
+
+	// Group by file, in sorted order
+	grouped := make(map[string][]domain.Comment)
+	for _, c := range comments {
+		file := c.FilePath
+		if file == "" {
+			file = "GENERAL"
+		}
+		grouped[file] = append(grouped[file], c)
+	}
+	files := make([]string, 0, len(grouped))
+	for file := range grouped {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		fmt.Printf("\n%s\n", file)
+		for _, c := range grouped[file] {
+			var flags []string
+			if c.IsResolved {
+				flags = append(flags, "resolved")
+			}
+			if c.IsNit {
+				flags = append(flags, "nit")
+			}
+			if c.IsOutdated {
+				flags = append(flags, "outdated")
+			}
+			if c.IsOutsideDiff {
+				flags = append(flags, "outside-diff")
+			}
+			if c.HasSuggestion() {
+				flags = append(flags, "suggestion")
+			}
+
+			line := "-"
+			if c.LineNumber > 0 {
+				line = c.LineRange()
+			}
+			flagStr := ""
+			if len(flags) > 0 {
+				flagStr = " [" + strings.Join(flags, ", ") + "]"
+			}
+
+			fmt.Printf("  %s%s\n", line, flagStr)
+			if c.URL != "" {
+				fmt.Printf("    %s\n", c.URL)
+			}
+			fmt.Printf("    %.100s\n", strings.ReplaceAll(c.EffectiveBody(), "\n", " "))
+		}
+	}
+
+	fmt.Printf("\nCI: %d passed, %d pending, %d failed (of %d)\n",
+		ciStatus.PassedCount, ciStatus.PendingCount, len(ciStatus.Failures), ciStatus.TotalCount)
+	for _, f := range ciStatus.Failures {
+		fmt.Printf("  ✗ %s\n", f.CheckName)
+	}
+	for _, name := range ciStatus.PendingNames {
+		fmt.Printf("  ◐ %s\n", name)
+	}
+
+	return nil
+}
+
+// runReviewStatePrune drops stored review state for PRs that are no longer open
+func runReviewStatePrune(cmd *cobra.Command, args []string) error {
+	githubClient, ciProvider, err := newGitHubAdapters()
+	if err != nil {
+		return err
+	}
+	if err := githubClient.CheckAuth(cmd.Context()); err != nil {
+		return err
+	}
+
+	reviewService := service.NewReviewService(
+		githubClient,
+		ciProvider,
+		adapters.NewClaudeClient(),
+	)
+
+	pruned, err := reviewService.PruneState(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to prune state: %w", err)
+	}
+
+	if len(pruned) == 0 {
+		fmt.Println("No state to prune")
+		return nil
+	}
+
+	sort.Strings(pruned)
+	for _, key := range pruned {
+		fmt.Printf("  Pruned %s\n", key)
+	}
+	fmt.Printf("Pruned state for %d closed PR(s)\n", len(pruned))
+	return nil
+}
+
+// printReviewListJSON prints the `review list` output as JSON
+func printReviewListJSON(prNumber int, comments []domain.Comment, ciStatus domain.CIStatus) error {
+	output := reviewListOutput{
+		PRNumber: prNumber,
+		Comments: []reviewListComment{},
+	}
+
+	for _, c := range comments {
+		output.Comments = append(output.Comments, reviewListComment{
+			ID:          c.ID,
+			File:        c.FilePath,
+			Line:        c.LineNumber,
+			EndLine:     c.EndLine,
+			URL:         c.URL,
+			Resolved:    c.IsResolved,
+			Nit:         c.IsNit,
+			Outdated:    c.IsOutdated,
+			OutsideDiff: c.IsOutsideDiff,
+			Body:        c.Body,
+			Suggestion:  c.SuggestedChange,
+		})
+	}
+
+	output.CI.Passed = ciStatus.PassedCount
+	output.CI.Pending = append([]string{}, ciStatus.PendingNames...)
+	output.CI.Failures = []string{}
+	for _, f := range ciStatus.Failures {
+		output.CI.Failures = append(output.CI.Failures, f.CheckName)
+	}
+	output.CI.Total = ciStatus.TotalCount
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
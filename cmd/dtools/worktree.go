@@ -1,7 +1,9 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/DylanSharp/dtools/internal/ui"
 	"github.com/DylanSharp/dtools/internal/worktree"
@@ -19,14 +21,48 @@ Each worktree gets:
   - Isolated Docker containers (unique COMPOSE_PROJECT_NAME)
   - Unique host ports (auto-detected from docker-compose.yml)
   - Separate volumes (fresh database per worktree)
-  - A ./dev helper script for common commands`,
+  - A ./dev helper script for common commands
+
+Port variables listed under "shared_ports" in a .worktree.json file at the
+repository root keep their default port in every worktree. Compose profiles
+listed under "profiles" are enabled by ./dev up; services behind other
+profiles only get offset ports if their profile is listed under "port_profiles".`,
 }
 
+var (
+	worktreeReallocate bool
+	worktreeFrom       string
+	worktreeAllowProd  bool
+	worktreeQuiet      bool
+
+	worktreeCleanContainers bool
+)
+
 var worktreeCreateCmd = &cobra.Command{
 	Use:   "create [branch]",
 	Short: "Create a new worktree",
-	Long:  "Create a new worktree. If no branch is specified, interactive mode will guide you.",
-	Args:  cobra.MaximumNArgs(1),
+	Long: `Create a new worktree. If no branch is specified, interactive mode will guide you.
+
+A branch that doesn't exist yet is created from the current HEAD, or from
+--from, which takes any branch, tag or commit.
+
+The repository's .env is copied into the worktree. If it looks like a
+production config (APP_ENV=production, or a database on a remote host),
+create refuses unless --allow-prod-env is given.
+
+Containers left under the new worktree's Docker project by an earlier run (for
+example one that crashed before ./dev down) are reported before the worktree is
+created, or removed with --clean-containers.
+
+With --quiet, only the worktree path and its environment are printed, as
+WORKTREE_PATH:<path> followed by KEY=VALUE lines for COMPOSE_PROJECT_NAME and
+each allocated port. Warnings go to stderr.`,
+	Example: `  # Start a feature branch off the latest main
+  git fetch && dtools worktree create feature/login --from origin/main
+
+  # cd into a new worktree from a shell function
+  cd "$(dtools worktree create feature/login --quiet | sed -n 's/^WORKTREE_PATH://p')"`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		repo, err := worktree.NewRepo()
 		if err != nil {
@@ -47,7 +83,8 @@ var worktreeCreateCmd = &cobra.Command{
 			}
 		}
 
-		return repo.CreateWorktree(branch)
+		repo.Quiet = worktreeQuiet
+		return repo.CreateWorktree(branch, worktreeFrom, worktreeReallocate, worktreeAllowProd, worktreeCleanContainers)
 	},
 }
 
@@ -91,16 +128,155 @@ var worktreeRemoveCmd = &cobra.Command{
 	},
 }
 
+var worktreeOpenCmd = &cobra.Command{
+	Use:   "open <branch>",
+	Short: "Print the path of a branch's worktree",
+	Long: `Print the path of the worktree for a branch, and nothing else.
+
+A program can't change its parent shell's directory, so wrap this in a shell
+function to cd into the worktree:
+
+  wto() { local dir; dir=$(dtools worktree open "$1") && cd "$dir"; }`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, err := worktree.NewRepo()
+		if err != nil {
+			return err
+		}
+
+		path, err := repo.WorktreePath(args[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(path)
+		return nil
+	},
+}
+
+var (
+	worktreePruneDryRun bool
+	worktreePruneYes    bool
+)
+
+var worktreePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Clean up stale worktrees",
+	Long: `Clean up worktrees that git and the filesystem disagree about:
+  - git references whose directory no longer exists (git worktree prune)
+  - directories in .worktrees that git no longer tracks (their Docker services
+    are stopped, then they're deleted)
+
+Asks before removing anything unless --yes is given.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, err := worktree.NewRepo()
+		if err != nil {
+			return err
+		}
+		confirm := ui.Confirm
+		if worktreePruneYes {
+			confirm = nil
+		}
+		return repo.PruneWorktrees(worktreePruneDryRun, confirm)
+	},
+}
+
+var (
+	worktreeUpWait    bool
+	worktreeUpTimeout time.Duration
+)
+
+var worktreeUpCmd = &cobra.Command{
+	Use:   "up [branch]",
+	Short: "Start a worktree's services",
+	Long: `Start a worktree's services with its ./dev script. If no branch is specified
+and you're inside a worktree, starts the current one.
+
+With --wait, blocks until every running service's ports accept connections and
+its healthcheck passes, printing readiness per service, and fails if that takes
+longer than --timeout.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, err := worktree.NewRepo()
+		if err != nil {
+			return err
+		}
+
+		var branch string
+		if len(args) > 0 {
+			branch = args[0]
+		} else {
+			branch = repo.CurrentWorktree()
+			if branch == "" {
+				return fmt.Errorf("not inside a worktree. Usage: dtools worktree up <branch>")
+			}
+		}
+
+		// A service failing to come up isn't a usage error
+		cmd.SilenceUsage = true
+		return repo.Up(branch, worktreeUpWait, worktreeUpTimeout)
+	},
+}
+
+var worktreeLogsCmd = &cobra.Command{
+	Use:   "logs <branch> [service...]",
+	Short: "Follow a worktree's service logs",
+	Long: `Follow the logs of a worktree's services from anywhere in the repository,
+like running ./dev logs inside it. Without a service, follows every service.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, err := worktree.NewRepo()
+		if err != nil {
+			return err
+		}
+
+		// docker-compose reports its own errors
+		cmd.SilenceUsage = true
+		return repo.Logs(args[0], args[1:])
+	},
+}
+
+var worktreePortsJSON bool
+
 var worktreePortsCmd = &cobra.Command{
-	Use:   "ports <branch>",
+	Use:   "ports [branch]",
 	Short: "Show ports that would be allocated for a branch",
-	Args:  cobra.ExactArgs(1),
+	Long: `Show the ports allocated, or that would be allocated, for a branch's worktree
+and whether each is in use. If no branch is specified and you're inside a
+worktree, shows the current one.
+
+With --json, prints the worktree's path and Docker project and each port
+variable with its services, host port and a guessed http://localhost URL, for
+editors and other tooling.`,
+	Example: `  # Open the current worktree's web service
+  open "$(dtools worktree ports --json | jq -r '.ports[] | select(.var == "WEB_PORT") | .url')"`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		repo, err := worktree.NewRepo()
 		if err != nil {
 			return err
 		}
-		return repo.ShowPorts(args[0])
+
+		var branch string
+		if len(args) > 0 {
+			branch = args[0]
+		} else {
+			branch = repo.CurrentWorktree()
+			if branch == "" {
+				return fmt.Errorf("not inside a worktree. Usage: dtools worktree ports <branch>")
+			}
+		}
+
+		if worktreePortsJSON {
+			data, err := json.MarshalIndent(repo.Ports(branch), "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to encode ports: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+		return repo.ShowPorts(branch)
 	},
 }
 
@@ -108,6 +284,21 @@ func init() {
 	worktreeCmd.AddCommand(worktreeCreateCmd)
 	worktreeCmd.AddCommand(worktreeListCmd)
 	worktreeCmd.AddCommand(worktreeRemoveCmd)
+	worktreeCmd.AddCommand(worktreeOpenCmd)
+	worktreeCmd.AddCommand(worktreePruneCmd)
+
+	worktreeCreateCmd.Flags().BoolVar(&worktreeReallocate, "reallocate", false, "Allocate fresh ports instead of reusing a previous worktree's")
+	worktreeCreateCmd.Flags().BoolVar(&worktreeAllowProd, "allow-prod-env", false, "Copy .env even if it looks like a production config")
+	worktreeCreateCmd.Flags().BoolVarP(&worktreeQuiet, "quiet", "q", false, "Print only the worktree path and KEY=VALUE environment lines")
+	worktreeCreateCmd.Flags().BoolVar(&worktreeCleanContainers, "clean-containers", false, "Remove containers left under the worktree's Docker project by an earlier run")
+	worktreeCreateCmd.Flags().StringVar(&worktreeFrom, "from", "", "Branch, tag or commit to base a new branch on (default: current HEAD)")
+	worktreePruneCmd.Flags().BoolVar(&worktreePruneDryRun, "dry-run", false, "Show what would be removed without removing it")
+	worktreePruneCmd.Flags().BoolVarP(&worktreePruneYes, "yes", "y", false, "Skip the confirmation prompt")
 	worktreeCmd.AddCommand(worktreePortsCmd)
+	worktreePortsCmd.Flags().BoolVar(&worktreePortsJSON, "json", false, "Output as JSON")
+	worktreeCmd.AddCommand(worktreeUpCmd)
+	worktreeCmd.AddCommand(worktreeLogsCmd)
+	worktreeUpCmd.Flags().BoolVar(&worktreeUpWait, "wait", false, "Wait until services are reachable and healthy")
+	worktreeUpCmd.Flags().DurationVar(&worktreeUpTimeout, "timeout", 2*time.Minute, "How long --wait waits for services")
 	rootCmd.AddCommand(worktreeCmd)
 }
@@ -0,0 +1,83 @@
+// Package aicmd describes the external AI CLI that review and ralph shell out to.
+package aicmd
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// PromptPlaceholder marks where the prompt is inserted in a command template.
+// If a template doesn't contain it, the prompt is appended as the last argument.
+const PromptPlaceholder = "{prompt}"
+
+// SkipPermissionsFlag lets the Claude CLI edit files and run commands without asking
+const SkipPermissionsFlag = "--dangerously-skip-permissions"
+
+// DefaultTemplate runs the Claude CLI with streaming JSON output
+const DefaultTemplate = "claude -p " + SkipPermissionsFlag + " --output-format stream-json -- " + PromptPlaceholder
+
+// DefaultTimeout bounds a single AI run (one review or one story)
+const DefaultTimeout = 30 * time.Minute
+
+// Command is a parsed AI CLI invocation
+type Command struct {
+	Binary string
+	Args   []string
+}
+
+// Default returns the Claude CLI command
+func Default() Command {
+	cmd, _ := Parse(DefaultTemplate)
+	return cmd
+}
+
+// Parse splits a command template into a binary and its arguments.
+// Arguments are separated by whitespace; quoting is not supported.
+func Parse(template string) (Command, error) {
+	fields := strings.Fields(template)
+	if len(fields) == 0 {
+		return Command{}, fmt.Errorf("AI command is empty")
+	}
+
+	return Command{
+		Binary: fields[0],
+		Args:   fields[1:],
+	}, nil
+}
+
+// IsAvailable checks if the command's binary is on the PATH
+func (c Command) IsAvailable() bool {
+	_, err := exec.LookPath(c.Binary)
+	return err == nil
+}
+
+// SkipsPermissions returns true if the command runs the AI without permission prompts
+func (c Command) SkipsPermissions() bool {
+	for _, arg := range c.Args {
+		if arg == SkipPermissionsFlag {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildArgs returns the arguments for a run with the given prompt
+func (c Command) BuildArgs(prompt string) []string {
+	args := make([]string, 0, len(c.Args)+1)
+	replaced := false
+	for _, arg := range c.Args {
+		if arg == PromptPlaceholder {
+			args = append(args, prompt)
+			replaced = true
+			continue
+		}
+		args = append(args, arg)
+	}
+
+	if !replaced {
+		args = append(args, prompt)
+	}
+	return args
+}
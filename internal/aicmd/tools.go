@@ -0,0 +1,40 @@
+package aicmd
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// toolArgKeys are the tool input fields that best describe a call, in order of preference
+var toolArgKeys = []string{"file_path", "notebook_path", "path", "command", "pattern", "url", "query", "description"}
+
+// DescribeToolUse summarizes a tool_use block from the stream as one line,
+// such as "Read internal/foo.go" or "Bash go test ./..."
+func DescribeToolUse(name string, input json.RawMessage) string {
+	if arg := toolArg(input, toolArgKeys...); arg != "" {
+		if i := strings.IndexByte(arg, '\n'); i >= 0 {
+			arg = arg[:i] + " …"
+		}
+		return name + " " + arg
+	}
+	return name
+}
+
+// ToolUseFile returns the file a tool call reads or writes, if any
+func ToolUseFile(input json.RawMessage) string {
+	return toolArg(input, "file_path", "notebook_path")
+}
+
+// toolArg returns the first of keys that's a non-empty string in a tool's input
+func toolArg(input json.RawMessage, keys ...string) string {
+	var fields map[string]any
+	if len(input) == 0 || json.Unmarshal(input, &fields) != nil {
+		return ""
+	}
+	for _, key := range keys {
+		if value, ok := fields[key].(string); ok && strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
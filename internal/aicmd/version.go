@@ -0,0 +1,82 @@
+package aicmd
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DylanSharp/dtools/internal/logging"
+)
+
+// The Claude CLI versions whose stream-json output dtools has been checked
+// against: the 2.0 and 2.1 releases. Other versions may change the format,
+// leaving thoughts empty; widen the range only after checking a release.
+const (
+	TestedMinVersion = "2.0.0" // Inclusive
+	TestedMaxVersion = "2.2.0" // Exclusive
+)
+
+// versionProbeTimeout bounds `claude --version`
+const versionProbeTimeout = 10 * time.Second
+
+var versionPattern = regexp.MustCompile(`\d+\.\d+(\.\d+)?`)
+
+// IsClaude returns true if the command runs the Claude CLI, whose output
+// format dtools knows, rather than some other AI CLI
+func (c Command) IsClaude() bool {
+	return strings.TrimSuffix(filepath.Base(c.Binary), ".exe") == "claude"
+}
+
+// Version runs the command's binary with --version and returns the version
+// number it reports, e.g. "1.0.43"
+func (c Command) Version(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, versionProbeTimeout)
+	defer cancel()
+
+	out, err := logging.Output(exec.CommandContext(ctx, c.Binary, "--version"))
+	if err != nil {
+		return "", fmt.Errorf("failed to get %s version: %w", c.Binary, err)
+	}
+	version := ParseVersion(string(out))
+	if version == "" {
+		return "", fmt.Errorf("no version number in %s --version output %q", c.Binary, strings.TrimSpace(string(out)))
+	}
+	return version, nil
+}
+
+// ParseVersion returns the version number in --version output, or "" if it has none
+func ParseVersion(output string) string {
+	return versionPattern.FindString(output)
+}
+
+// IsTestedVersion returns true if a Claude CLI version is in the tested range
+func IsTestedVersion(version string) bool {
+	return compareVersions(version, TestedMinVersion) >= 0 && compareVersions(version, TestedMaxVersion) < 0
+}
+
+// compareVersions compares dotted version numbers numerically, treating
+// missing parts as 0, and returns -1, 0 or 1
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
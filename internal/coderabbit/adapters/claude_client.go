@@ -4,35 +4,57 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"fmt"
 	"os/exec"
+	"time"
 
+	"github.com/DylanSharp/dtools/internal/aicmd"
 	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
 	"github.com/DylanSharp/dtools/internal/coderabbit/ports"
+	"github.com/DylanSharp/dtools/internal/logging"
 )
 
-// ClaudeClient implements ports.AIProvider using the Claude CLI
+// ClaudeClient implements ports.AIProvider using the Claude CLI,
+// or any CLI that accepts a prompt and emits compatible stream-json
 type ClaudeClient struct {
-	binaryPath string
+	command aicmd.Command
+	timeout time.Duration
 }
 
 // NewClaudeClient creates a new Claude CLI client
 func NewClaudeClient() *ClaudeClient {
 	return &ClaudeClient{
-		binaryPath: "claude",
+		command: aicmd.Default(),
+		timeout: aicmd.DefaultTimeout,
 	}
 }
 
 // NewClaudeClientWithPath creates a new Claude CLI client with a custom binary path
 func NewClaudeClientWithPath(binaryPath string) *ClaudeClient {
+	command := aicmd.Default()
+	command.Binary = binaryPath
 	return &ClaudeClient{
-		binaryPath: binaryPath,
+		command: command,
+		timeout: aicmd.DefaultTimeout,
 	}
 }
 
-// IsAvailable checks if the Claude CLI is available
+// NewClaudeClientWithCommand creates a client that runs a custom AI command
+func NewClaudeClientWithCommand(command aicmd.Command) *ClaudeClient {
+	return &ClaudeClient{
+		command: command,
+		timeout: aicmd.DefaultTimeout,
+	}
+}
+
+// SetTimeout sets the deadline for a single review run (0 disables it)
+func (c *ClaudeClient) SetTimeout(timeout time.Duration) {
+	c.timeout = timeout
+}
+
+// IsAvailable checks if the AI CLI is available
 func (c *ClaudeClient) IsAvailable() bool {
-	_, err := exec.LookPath(c.binaryPath)
-	return err == nil
+	return c.command.IsAvailable()
 }
 
 // StreamReview starts a review and returns a channel of stream chunks
@@ -41,28 +63,34 @@ func (c *ClaudeClient) StreamReview(ctx context.Context, prompt string) (<-chan
 		return nil, domain.ErrClaudeNotFound()
 	}
 
-	// Build the Claude command with streaming JSON output
-	cmd := exec.CommandContext(ctx, c.binaryPath,
-		"-p",
-		"--dangerously-skip-permissions",
-		"--output-format", "stream-json",
-		"--",
-		prompt,
-	)
+	// Bound the run so a hung process can't block forever; the process is killed on expiry
+	cancel := context.CancelFunc(func() {})
+	if c.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+	}
+
+	// Build the AI command with streaming JSON output
+	cmd := exec.CommandContext(ctx, c.command.Binary, c.command.BuildArgs(prompt)...)
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
+		cancel()
 		return nil, domain.ErrClaudeError("failed to create stdout pipe", err)
 	}
 
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
+		cancel()
 		return nil, domain.ErrClaudeError("failed to create stderr pipe", err)
 	}
 
+	start := time.Now()
 	if err := cmd.Start(); err != nil {
+		cancel()
+		logging.Finished(cmd, err, time.Since(start))
 		return nil, domain.ErrClaudeError("failed to start Claude CLI", err)
 	}
+	logging.Started(cmd)
 
 	chunks := make(chan ports.StreamChunk, 100)
 
@@ -78,7 +106,10 @@ func (c *ClaudeClient) StreamReview(ctx context.Context, prompt string) (<-chan
 	// Read JSONL from stdout
 	go func() {
 		defer close(chunks)
-		defer cmd.Wait()
+		defer cancel()
+		defer func() {
+			logging.Finished(cmd, cmd.Wait(), time.Since(start))
+		}()
 
 		scanner := bufio.NewScanner(stdout)
 		// Increase buffer size for potentially large JSON objects
@@ -107,6 +138,17 @@ func (c *ClaudeClient) StreamReview(ctx context.Context, prompt string) (<-chan
 			chunks <- chunk
 		}
 
+		if ctx.Err() == context.DeadlineExceeded {
+			chunks <- ports.StreamChunk{
+				Type: "error",
+				Error: &ports.StreamError{
+					Type:    ports.StreamErrorTimeout,
+					Message: domain.ErrClaudeTimeout(fmt.Errorf("no result after %s", c.timeout)).Error(),
+				},
+			}
+			return
+		}
+
 		if err := scanner.Err(); err != nil {
 			chunks <- ports.StreamChunk{
 				Type: "error",
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/DylanSharp/dtools/internal/aicmd"
 	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
 	"github.com/DylanSharp/dtools/internal/coderabbit/ports"
 )
@@ -15,6 +16,8 @@ type ClaudeStreamParser struct {
 	codePatterns []*regexp.Regexp
 	// Buffer for accumulating text chunks
 	textBuffer strings.Builder
+	// Block type of the buffered text ("text" or "thinking")
+	bufferKind string
 	// Current file being discussed
 	currentFile string
 }
@@ -54,46 +57,87 @@ func (p *ClaudeStreamParser) FilterThoughts(chunks <-chan ports.StreamChunk) <-c
 				continue
 			}
 
-			// Extract text content
-			text := chunk.GetText()
-			if text == "" {
-				continue
+			for _, block := range chunk.ContentBlocks() {
+				switch block.Type {
+				case "tool_use":
+					p.flush(filtered)
+					filtered <- p.toolThought(block)
+
+				case "text", "thinking":
+					text := block.Text
+					if block.Type == "thinking" {
+						text = block.Thinking
+					}
+					if text == "" {
+						continue
+					}
+
+					// Don't join a line of thinking with a line of reply
+					if block.Type != p.bufferKind {
+						p.flush(filtered)
+						p.bufferKind = block.Type
+					}
+
+					// Accumulate text and process line by line
+					p.textBuffer.WriteString(text)
+					p.processLines(filtered)
+				}
 			}
 
-			// Accumulate text and process line by line
-			p.textBuffer.WriteString(text)
-			buffered := p.textBuffer.String()
+			// Each chunk is a whole message, so its last line ends with it rather
+			// than running into the next message's text
+			p.flush(filtered)
+		}
+	}()
 
-			// Process complete lines
-			for {
-				idx := strings.Index(buffered, "\n")
-				if idx == -1 {
-					break
-				}
+	return filtered
+}
 
-				line := buffered[:idx]
-				buffered = buffered[idx+1:]
-				p.textBuffer.Reset()
-				p.textBuffer.WriteString(buffered)
+// processLines sends the complete lines in the buffer, keeping any partial line
+func (p *ClaudeStreamParser) processLines(filtered chan<- domain.ThoughtChunk) {
+	buffered := p.textBuffer.String()
 
-				// Process the line
-				if thought := p.processLine(line); thought != nil {
-					filtered <- *thought
-				}
-			}
+	for {
+		idx := strings.Index(buffered, "\n")
+		if idx == -1 {
+			break
+		}
 
-			// If this is the last chunk, flush the buffer
-			if chunk.IsComplete() && p.textBuffer.Len() > 0 {
-				remaining := p.textBuffer.String()
-				if thought := p.processLine(remaining); thought != nil {
-					filtered <- *thought
-				}
-				p.textBuffer.Reset()
-			}
+		line := buffered[:idx]
+		buffered = buffered[idx+1:]
+		p.textBuffer.Reset()
+		p.textBuffer.WriteString(buffered)
+
+		// Process the line
+		if thought := p.processLine(line); thought != nil {
+			filtered <- *thought
 		}
-	}()
+	}
+}
 
-	return filtered
+// flush sends any partial line left in the buffer
+func (p *ClaudeStreamParser) flush(filtered chan<- domain.ThoughtChunk) {
+	if p.textBuffer.Len() == 0 {
+		return
+	}
+	remaining := p.textBuffer.String()
+	p.textBuffer.Reset()
+	if thought := p.processLine(remaining); thought != nil {
+		filtered <- *thought
+	}
+}
+
+// toolThought describes a tool call, making the file it touches the current file
+func (p *ClaudeStreamParser) toolThought(block ports.ContentBlock) domain.ThoughtChunk {
+	if file := aicmd.ToolUseFile(block.Input); file != "" {
+		p.currentFile = file
+	}
+	return domain.ThoughtChunk{
+		Timestamp: time.Now(),
+		Content:   aicmd.DescribeToolUse(block.Name, block.Input),
+		Type:      domain.ThoughtTypeTool,
+		File:      p.currentFile,
+	}
 }
 
 // processLine filters a single line and returns a ThoughtChunk if displayable
@@ -109,8 +153,11 @@ func (p *ClaudeStreamParser) processLine(line string) *domain.ThoughtChunk {
 		return nil
 	}
 
-	// Determine thought type
-	thoughtType := p.classifyThought(trimmed)
+	// Thinking is tagged by its block type; replies are classified by content
+	thoughtType := domain.ThoughtTypeThinking
+	if p.bufferKind != "thinking" {
+		thoughtType = p.classifyThought(trimmed)
+	}
 
 	// Extract file reference if present
 	if file := p.extractFileReference(trimmed); file != "" {
@@ -154,7 +201,7 @@ func (p *ClaudeStreamParser) isCode(line string) bool {
 	return false
 }
 
-// classifyThought determines the type of thought based on content
+// classifyThought guesses the type of a line of reply from its content
 func (p *ClaudeStreamParser) classifyThought(line string) domain.ThoughtType {
 	lower := strings.ToLower(line)
 
@@ -185,8 +232,7 @@ func (p *ClaudeStreamParser) classifyThought(line string) domain.ThoughtType {
 		return domain.ThoughtTypeAnalysis
 	}
 
-	// Default to thinking
-	return domain.ThoughtTypeThinking
+	return domain.ThoughtTypeSpeech
 }
 
 // extractFileReference extracts a file path reference from text
@@ -211,5 +257,6 @@ func (p *ClaudeStreamParser) extractFileReference(line string) string {
 // Reset clears the parser state
 func (p *ClaudeStreamParser) Reset() {
 	p.textBuffer.Reset()
+	p.bufferKind = ""
 	p.currentFile = ""
 }
@@ -0,0 +1,132 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
+	"github.com/DylanSharp/dtools/internal/exec"
+)
+
+// runGH executes a gh CLI command and returns its output. Failures are mapped
+// to domain errors by kind, so a missing gh, an expired login, and a rate limit
+// each surface as such instead of a bare exit status.
+func runGH(ctx context.Context, args ...string) ([]byte, error) {
+	out, err := exec.Output(ctx, "gh", args...)
+	if err == nil {
+		return out, nil
+	}
+
+	switch exec.KindOf(err) {
+	case exec.KindNotFound:
+		return nil, domain.ErrGitHubAPI("GitHub CLI (gh) not found, install it from https://cli.github.com", err)
+	case exec.KindAuth:
+		return nil, domain.ErrGitHubAuth(err)
+	case exec.KindRateLimit:
+		return nil, domain.ErrGitHubRateLimit(err)
+	}
+	return nil, err
+}
+
+// githubAPI sends requests to GitHub's REST and GraphQL APIs. The adapters use
+// gh api by default; without gh, a token-authenticated HTTP client stands in.
+type githubAPI interface {
+	// REST sends a request to a path such as "repos/o/r/pulls/1", with fields as
+	// the request body. With paginate every page is fetched and their lists merged.
+	REST(ctx context.Context, method, path string, fields map[string]string, paginate bool) ([]byte, error)
+
+	// GraphQL runs a query, passing values as variables
+	GraphQL(ctx context.Context, query string, stringVars map[string]string, intVars map[string]int) ([]byte, error)
+}
+
+// ghAPI implements githubAPI with gh api
+type ghAPI struct{}
+
+// REST implements githubAPI
+func (ghAPI) REST(ctx context.Context, method, path string, fields map[string]string, paginate bool) ([]byte, error) {
+	args := []string{"api", path}
+	if method != "" && method != "GET" {
+		args = append(args, "--method", method)
+	}
+	if paginate {
+		args = append(args, "--paginate")
+	}
+	for _, name := range sortedKeys(fields) {
+		args = append(args, "-f", name+"="+fields[name])
+	}
+	return runGH(ctx, args...)
+}
+
+// GraphQL implements githubAPI
+func (ghAPI) GraphQL(ctx context.Context, query string, stringVars map[string]string, intVars map[string]int) ([]byte, error) {
+	return runGH(ctx, graphQLArgs(query, stringVars, intVars)...)
+}
+
+// graphQLArgs builds the arguments for a gh api graphql call. Values are passed
+// as GraphQL variables instead of being interpolated into the query, so a name
+// with quotes or braces can't change it. Strings are sent as-is with -f; ints
+// with -F so gh types them as numbers.
+func graphQLArgs(query string, stringVars map[string]string, intVars map[string]int) []string {
+	args := []string{"api", "graphql", "-f", "query=" + query}
+	for _, name := range sortedKeys(stringVars) {
+		args = append(args, "-f", name+"="+stringVars[name])
+	}
+	for _, name := range sortedKeys(intVars) {
+		args = append(args, "-F", fmt.Sprintf("%s=%d", name, intVars[name]))
+	}
+	return args
+}
+
+// sortedKeys returns a map's keys in order, so gh arguments are stable
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// graphQLError is an entry in a GraphQL response's errors list
+type graphQLError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	Path    []any  `json:"path"`
+}
+
+// checkGraphQLErrors returns an error if a GraphQL response reports any errors.
+// GitHub can answer with errors and null or partial data, which would otherwise
+// decode into an empty result.
+func checkGraphQLErrors(out []byte, action string) error {
+	var response struct {
+		Errors []graphQLError `json:"errors"`
+	}
+	if err := json.Unmarshal(out, &response); err != nil || len(response.Errors) == 0 {
+		return nil
+	}
+	return graphQLErrors(response.Errors, action)
+}
+
+// graphQLErrors joins a GraphQL response's errors into one error described by action
+func graphQLErrors(errs []graphQLError, action string) error {
+	messages := make([]string, 0, len(errs))
+	for _, e := range errs {
+		message := e.Message
+		if e.Type != "" {
+			message = e.Type + ": " + message
+		}
+		if len(e.Path) > 0 {
+			path := make([]string, len(e.Path))
+			for i, p := range e.Path {
+				path[i] = fmt.Sprint(p)
+			}
+			message += " (at " + strings.Join(path, ".") + ")"
+		}
+		messages = append(messages, message)
+	}
+	return domain.ErrGitHubGraphQL(action, errors.New(strings.Join(messages, "; ")))
+}
@@ -0,0 +1,54 @@
+package adapters
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// quotedRepo is a repository name that would break out of a string literal if
+// it were interpolated into a query
+const quotedRepo = `we"ird) { viewer { login } } #`
+
+func TestGraphQLArgs(t *testing.T) {
+	query := "query($owner: String!, $name: String!, $number: Int!) { x }"
+	got := graphQLArgs(query,
+		map[string]string{"owner": "o", "name": quotedRepo},
+		map[string]int{"number": 7})
+
+	want := []string{
+		"api", "graphql",
+		"-f", "query=" + query,
+		"-f", "name=" + quotedRepo,
+		"-f", "owner=o",
+		"-F", "number=7",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("graphQLArgs() = %q, want %q", got, want)
+	}
+}
+
+func TestListCodeRabbitCommentsPassesRepoAsVariable(t *testing.T) {
+	var queries []string
+	api := &fakeGitHubAPI{graphQL: func(query string, vars map[string]string) (map[string]any, error) {
+		queries = append(queries, query)
+		return fakeThreadsResponse(map[string]any{
+			"pageInfo": map[string]any{},
+			"nodes":    []any{map[string]any{"id": "thread", "comments": fakeCommentPage(1, 1, "")}},
+		}), nil
+	}}
+
+	client := NewGitHubCLIClient()
+	client.api = api
+	if _, err := client.ListCodeRabbitComments(context.Background(), "owner", quotedRepo, 1); err != nil {
+		t.Fatalf("ListCodeRabbitComments() error = %v", err)
+	}
+
+	if api.calls[0]["name"] != quotedRepo {
+		t.Errorf("name variable = %q, want %q", api.calls[0]["name"], quotedRepo)
+	}
+	if strings.Contains(queries[0], quotedRepo) {
+		t.Error("repository name was interpolated into the query")
+	}
+}
@@ -0,0 +1,35 @@
+package adapters
+
+import (
+	"context"
+
+	"github.com/DylanSharp/dtools/internal/coderabbit/ports"
+	"github.com/DylanSharp/dtools/internal/exec"
+	"github.com/DylanSharp/dtools/internal/logging"
+)
+
+// GitHub is what a review needs from a GitHub adapter: the port plus an auth check
+type GitHub interface {
+	ports.GitHubClient
+	CheckAuth(ctx context.Context) error
+}
+
+// NewGitHubAdapters creates the GitHub and CI adapters, matching the given reviewer
+// bots (CodeRabbit if there are none). They go through gh, or straight to GitHub's
+// API with GH_TOKEN or GITHUB_TOKEN when gh isn't installed (as in containers and CI).
+func NewGitHubAdapters(bots []string) (GitHub, ports.CIProvider) {
+	if token := TokenFromEnv(); token != "" && !exec.Available("gh") {
+		logging.Debug("gh not found, using the GitHub API with a token")
+		client := NewGitHubHTTPClient(token)
+		client.SetReviewerBots(bots)
+		ciProvider := NewGitHubHTTPCIAdapter(token)
+		ciProvider.SetReviewerBots(bots)
+		return client, ciProvider
+	}
+
+	client := NewGitHubCLIClient()
+	client.SetReviewerBots(bots)
+	ciProvider := NewGitHubCIAdapter()
+	ciProvider.SetReviewerBots(bots)
+	return client, ciProvider
+}
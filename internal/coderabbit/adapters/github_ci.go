@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
 	"strings"
 
 	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
@@ -12,11 +11,21 @@ import (
 )
 
 // GitHubCIAdapter implements ports.CIProvider using the gh CLI
-type GitHubCIAdapter struct{}
+type GitHubCIAdapter struct {
+	api  githubAPI
+	bots domain.ReviewerBots
+}
 
 // NewGitHubCIAdapter creates a new GitHub CI adapter
 func NewGitHubCIAdapter() *GitHubCIAdapter {
-	return &GitHubCIAdapter{}
+	return &GitHubCIAdapter{api: ghAPI{}, bots: domain.DefaultReviewerBots()}
+}
+
+// SetReviewerBots sets the bot names used to recognize the reviewer's check
+func (a *GitHubCIAdapter) SetReviewerBots(bots domain.ReviewerBots) {
+	if len(bots) > 0 {
+		a.bots = bots
+	}
 }
 
 // ghCheckRun represents a GitHub check run from the API
@@ -57,13 +66,7 @@ type ghCheckRuns struct {
 // GetTestFailures retrieves failed CI checks for a commit
 func (a *GitHubCIAdapter) GetTestFailures(ctx context.Context, owner, repo, commitSHA string) ([]domain.CITestFailure, error) {
 	// Get all check runs for the commit
-	args := []string{
-		"api",
-		fmt.Sprintf("repos/%s/%s/commits/%s/check-runs", owner, repo, commitSHA),
-		"--paginate",
-	}
-
-	out, err := a.runGH(ctx, args...)
+	out, err := a.api.REST(ctx, "GET", fmt.Sprintf("repos/%s/%s/commits/%s/check-runs", owner, repo, commitSHA), nil, true)
 	if err != nil {
 		return nil, domain.ErrGitHubAPI("failed to fetch check runs", err)
 	}
@@ -127,13 +130,7 @@ type ghCommitStatus struct {
 // GetCIStatus retrieves the full CI status including pending, passed, and failed checks
 func (a *GitHubCIAdapter) GetCIStatus(ctx context.Context, owner, repo, commitSHA string) (domain.CIStatus, error) {
 	// First, check the Check Runs API (GitHub Actions, GitHub Apps)
-	args := []string{
-		"api",
-		fmt.Sprintf("repos/%s/%s/commits/%s/check-runs", owner, repo, commitSHA),
-		"--paginate",
-	}
-
-	out, err := a.runGH(ctx, args...)
+	out, err := a.api.REST(ctx, "GET", fmt.Sprintf("repos/%s/%s/commits/%s/check-runs", owner, repo, commitSHA), nil, true)
 	if err != nil {
 		return domain.CIStatus{}, domain.ErrGitHubAPI("failed to fetch check runs", err)
 	}
@@ -149,9 +146,9 @@ func (a *GitHubCIAdapter) GetCIStatus(ctx context.Context, owner, repo, commitSH
 
 	for _, run := range checkRuns.CheckRuns {
 		// Check if this is a CodeRabbit check
-		isCodeRabbit := strings.Contains(strings.ToLower(run.Name), "coderabbit") ||
-			strings.Contains(strings.ToLower(run.App.Name), "coderabbit") ||
-			strings.Contains(strings.ToLower(run.App.Slug), "coderabbit")
+		isCodeRabbit := a.bots.Matches(run.Name) ||
+			a.bots.Matches(run.App.Name) ||
+			a.bots.Matches(run.App.Slug)
 
 		if isCodeRabbit {
 			status.CodeRabbitFound = true
@@ -201,17 +198,12 @@ func (a *GitHubCIAdapter) GetCIStatus(ctx context.Context, owner, repo, commitSH
 
 	// Also check the Commit Status API (for integrations like CodeRabbit that use statuses instead of checks)
 	// This is separate from Check Runs - some integrations use one or the other
-	statusArgs := []string{
-		"api",
-		fmt.Sprintf("repos/%s/%s/commits/%s/status", owner, repo, commitSHA),
-	}
-
-	statusOut, err := a.runGH(ctx, statusArgs...)
+	statusOut, err := a.api.REST(ctx, "GET", fmt.Sprintf("repos/%s/%s/commits/%s/status", owner, repo, commitSHA), nil, false)
 	if err == nil {
 		var commitStatus ghCommitStatus
 		if json.Unmarshal(statusOut, &commitStatus) == nil {
 			for _, s := range commitStatus.Statuses {
-				isCodeRabbit := strings.Contains(strings.ToLower(s.Context), "coderabbit")
+				isCodeRabbit := a.bots.Matches(s.Context)
 
 				if isCodeRabbit {
 					status.CodeRabbitFound = true
@@ -248,31 +240,42 @@ func (a *GitHubCIAdapter) GetWorkflowRuns(ctx context.Context, owner, repo strin
 	args := []string{
 		"pr", "checks", fmt.Sprintf("%d", prNumber),
 		"--repo", fmt.Sprintf("%s/%s", owner, repo),
-		"--json", "name,state,conclusion,link",
+		"--json", "name,state,bucket,link,workflow",
 	}
 
-	out, err := a.runGH(ctx, args...)
+	out, err := runGH(ctx, args...)
 	if err != nil {
 		return nil, domain.ErrGitHubAPI("failed to fetch workflow runs", err)
 	}
 
 	var checks []struct {
-		Name       string `json:"name"`
-		State      string `json:"state"`
-		Conclusion string `json:"conclusion"`
-		Link       string `json:"link"`
+		Name     string `json:"name"`
+		State    string `json:"state"`
+		Bucket   string `json:"bucket"` // pass, fail, pending, skipping or cancel
+		Link     string `json:"link"`
+		Workflow string `json:"workflow"`
 	}
 
 	if err := json.Unmarshal(out, &checks); err != nil {
 		return nil, domain.ErrJSONParse("failed to parse workflow runs", err)
 	}
 
+	// gh pr checks reports one state per check; split it into the API's status and conclusion
 	var runs []ports.WorkflowRun
 	for _, check := range checks {
+		status, conclusion := "completed", strings.ToLower(check.State)
+		switch check.Bucket {
+		case "pending":
+			status, conclusion = "in_progress", ""
+		case "fail":
+			conclusion = "failure"
+		}
 		runs = append(runs, ports.WorkflowRun{
+			ID:         domain.ParseWorkflowRunID(check.Link),
 			Name:       check.Name,
-			Status:     check.State,
-			Conclusion: check.Conclusion,
+			Workflow:   check.Workflow,
+			Status:     status,
+			Conclusion: conclusion,
 			LogURL:     check.Link,
 		})
 	}
@@ -280,14 +283,23 @@ func (a *GitHubCIAdapter) GetWorkflowRuns(ctx context.Context, owner, repo strin
 	return runs, nil
 }
 
-// getAnnotations fetches annotations for a specific check run
-func (a *GitHubCIAdapter) getAnnotations(ctx context.Context, owner, repo string, checkRunID int64) ([]domain.CIAnnotation, error) {
+// RerunFailedJobs reruns the failed jobs of a GitHub Actions workflow run
+func (a *GitHubCIAdapter) RerunFailedJobs(ctx context.Context, owner, repo string, runID int64) error {
 	args := []string{
-		"api",
-		fmt.Sprintf("repos/%s/%s/check-runs/%d/annotations", owner, repo, checkRunID),
+		"run", "rerun", fmt.Sprintf("%d", runID),
+		"--failed",
+		"--repo", fmt.Sprintf("%s/%s", owner, repo),
 	}
 
-	out, err := a.runGH(ctx, args...)
+	if _, err := runGH(ctx, args...); err != nil {
+		return domain.ErrGitHubAPI(fmt.Sprintf("failed to rerun workflow run %d", runID), err)
+	}
+	return nil
+}
+
+// getAnnotations fetches annotations for a specific check run
+func (a *GitHubCIAdapter) getAnnotations(ctx context.Context, owner, repo string, checkRunID int64) ([]domain.CIAnnotation, error) {
+	out, err := a.api.REST(ctx, "GET", fmt.Sprintf("repos/%s/%s/check-runs/%d/annotations", owner, repo, checkRunID), nil, false)
 	if err != nil {
 		return nil, err
 	}
@@ -316,16 +328,3 @@ func (a *GitHubCIAdapter) getAnnotations(ctx context.Context, owner, repo string
 
 	return annotations, nil
 }
-
-// runGH executes a gh CLI command and returns the output
-func (a *GitHubCIAdapter) runGH(ctx context.Context, args ...string) ([]byte, error) {
-	cmd := exec.CommandContext(ctx, "gh", args...)
-	out, err := cmd.Output()
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("gh command failed: %s", string(exitErr.Stderr))
-		}
-		return nil, err
-	}
-	return out, nil
-}
@@ -3,22 +3,45 @@ package adapters
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os/exec"
+	"io"
+	"net"
+	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
 	"github.com/DylanSharp/dtools/internal/coderabbit/ports"
+	"github.com/DylanSharp/dtools/internal/coderabbit/state"
+	"github.com/DylanSharp/dtools/internal/exec"
+	"github.com/DylanSharp/dtools/internal/logging"
 )
 
 // GitHubCLIClient implements ports.GitHubClient using the gh CLI
-type GitHubCLIClient struct{}
+type GitHubCLIClient struct {
+	api      githubAPI
+	bots     domain.ReviewerBots
+	authOnce sync.Once
+	authErr  error
+
+	resolvedMu      sync.Mutex
+	resolvedThreads map[string]bool // Thread IDs resolved during this run
+}
 
 // NewGitHubCLIClient creates a new GitHub CLI client
 func NewGitHubCLIClient() *GitHubCLIClient {
-	return &GitHubCLIClient{}
+	return &GitHubCLIClient{api: ghAPI{}, bots: domain.DefaultReviewerBots(), resolvedThreads: make(map[string]bool)}
+}
+
+// SetReviewerBots sets the bot logins whose comments are fetched
+func (c *GitHubCLIClient) SetReviewerBots(bots domain.ReviewerBots) {
+	if len(bots) > 0 {
+		c.bots = bots
+	}
 }
 
 // ghPR is the JSON structure returned by gh pr view
@@ -33,8 +56,9 @@ type ghPR struct {
 	Author     struct {
 		Login string `json:"login"`
 	} `json:"author"`
-	State string `json:"state"`
-	URL   string `json:"url"`
+	State   string `json:"state"`
+	IsDraft bool   `json:"isDraft"`
+	URL     string `json:"url"`
 }
 
 // ghReview is the JSON structure for a PR review
@@ -43,6 +67,7 @@ type ghReview struct {
 	Body        string `json:"body"`
 	State       string `json:"state"`
 	SubmittedAt string `json:"submitted_at"`
+	HTMLURL     string `json:"html_url"`
 	User        struct {
 		Login string `json:"login"`
 	} `json:"user"`
@@ -69,10 +94,10 @@ func (c *GitHubCLIClient) GetPullRequest(ctx context.Context, owner, repo string
 	args := []string{
 		"pr", "view", fmt.Sprintf("%d", number),
 		"--repo", fmt.Sprintf("%s/%s", owner, repo),
-		"--json", "number,title,body,headRefName,baseRefName,headRefOid,baseRefOid,author,state,url",
+		"--json", "number,title,body,headRefName,baseRefName,headRefOid,baseRefOid,author,state,isDraft,url",
 	}
 
-	out, err := c.runGH(ctx, args...)
+	out, err := runGH(ctx, args...)
 	if err != nil {
 		return nil, domain.ErrGitHubAPI("failed to fetch PR", err)
 	}
@@ -92,144 +117,316 @@ func (c *GitHubCLIClient) GetPullRequest(ctx context.Context, owner, repo string
 		BaseCommit: pr.BaseRefOid,
 		Author:     pr.Author.Login,
 		State:      pr.State,
+		IsDraft:    pr.IsDraft,
 		URL:        pr.URL,
 	}, nil
 }
 
-// ListCodeRabbitComments fetches all CodeRabbit review comments for a PR using GraphQL
-// This includes the thread's isResolved status which is not available via REST API
-func (c *GitHubCLIClient) ListCodeRabbitComments(ctx context.Context, owner, repo string, number int) ([]domain.Comment, error) {
-	// Use GraphQL to fetch review threads with resolved status
-	query := fmt.Sprintf(`
-	{
-		repository(owner: "%s", name: "%s") {
-			pullRequest(number: %d) {
-				reviewThreads(first: 100) {
+// threadComment is a review thread comment in the GraphQL response
+type threadComment struct {
+	DatabaseID int       `json:"databaseId"`
+	Body       string    `json:"body"`
+	Path       string    `json:"path"`
+	Line       int       `json:"line"`
+	StartLine  *int      `json:"startLine"`
+	DiffHunk   string    `json:"diffHunk"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+	URL        string    `json:"url"`
+	Author     struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+// pageInfo is a GraphQL connection's cursor state
+type pageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+// threadCommentPage is one page of a review thread's comments
+type threadCommentPage struct {
+	PageInfo pageInfo        `json:"pageInfo"`
+	Nodes    []threadComment `json:"nodes"`
+}
+
+// threadNode is a PR review thread with all of its comments
+type threadNode struct {
+	ID         string            `json:"id"`
+	IsResolved bool              `json:"isResolved"`
+	IsOutdated bool              `json:"isOutdated"`
+	Comments   threadCommentPage `json:"comments"`
+}
+
+// threadCommentFragment selects the review comment fields threadComment decodes
+const threadCommentFragment = `
+	fragment threadComment on PullRequestReviewComment {
+		databaseId
+		body
+		path
+		line: originalLine
+		startLine: originalStartLine
+		diffHunk
+		createdAt
+		updatedAt
+		url
+		author {
+			login
+		}
+	}`
+
+// listThreads fetches every review thread on a PR, following the cursors of both
+// the thread list and each thread's comments so long PRs and deep threads are
+// returned whole
+func (c *GitHubCLIClient) listThreads(ctx context.Context, owner, repo string, number int) ([]threadNode, error) {
+	query := `
+	query($owner: String!, $name: String!, $number: Int!, $cursor: String) {
+		repository(owner: $owner, name: $name) {
+			pullRequest(number: $number) {
+				reviewThreads(first: 100, after: $cursor) {
+					pageInfo {
+						hasNextPage
+						endCursor
+					}
 					nodes {
 						id
 						isResolved
 						isOutdated
-						comments(first: 10) {
+						comments(first: 100) {
+							pageInfo {
+								hasNextPage
+								endCursor
+							}
 							nodes {
-								databaseId
-								body
-								path
-								line: originalLine
-								createdAt
-								updatedAt
-								url
-								author {
-									login
-								}
+								...threadComment
 							}
 						}
 					}
 				}
 			}
 		}
-	}`, owner, repo, number)
+	}` + threadCommentFragment
+
+	var threads []threadNode
+	cursor := ""
+	for {
+		vars := map[string]string{"owner": owner, "name": repo}
+		if cursor != "" {
+			vars["cursor"] = cursor
+		}
+		out, err := c.graphQL(ctx, query, vars, map[string]int{"number": number}, "failed to fetch review threads")
+		if err != nil {
+			return nil, err
+		}
 
-	args := []string{"api", "graphql", "-f", fmt.Sprintf("query=%s", query)}
-	out, err := c.runGH(ctx, args...)
-	if err != nil {
-		return nil, domain.ErrGitHubAPI("failed to fetch review threads", err)
+		var response struct {
+			Data struct {
+				Repository *struct {
+					PullRequest *struct {
+						ReviewThreads struct {
+							PageInfo pageInfo     `json:"pageInfo"`
+							Nodes    []threadNode `json:"nodes"`
+						} `json:"reviewThreads"`
+					} `json:"pullRequest"`
+				} `json:"repository"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(out, &response); err != nil {
+			return nil, domain.ErrJSONParse("failed to parse review threads", err)
+		}
+		if response.Data.Repository == nil {
+			return nil, domain.ErrGitHubGraphQL(fmt.Sprintf("repository %s/%s missing from GraphQL response", owner, repo), nil)
+		}
+		if response.Data.Repository.PullRequest == nil {
+			return nil, domain.ErrPRNotFound(number)
+		}
+
+		page := response.Data.Repository.PullRequest.ReviewThreads
+		for _, thread := range page.Nodes {
+			if thread.Comments.PageInfo.HasNextPage {
+				rest, err := c.listThreadComments(ctx, thread.ID, thread.Comments.PageInfo.EndCursor)
+				if err != nil {
+					return nil, err
+				}
+				thread.Comments.Nodes = append(thread.Comments.Nodes, rest...)
+			}
+			threads = append(threads, thread)
+		}
+		if !page.PageInfo.HasNextPage || page.PageInfo.EndCursor == "" {
+			return threads, nil
+		}
+		cursor = page.PageInfo.EndCursor
 	}
+}
 
-	var response struct {
-		Data struct {
-			Repository struct {
-				PullRequest struct {
-					ReviewThreads struct {
-						Nodes []struct {
-							ID         string `json:"id"`
-							IsResolved bool   `json:"isResolved"`
-							IsOutdated bool   `json:"isOutdated"`
-							Comments   struct {
-								Nodes []struct {
-									DatabaseID int       `json:"databaseId"`
-									Body       string    `json:"body"`
-									Path       string    `json:"path"`
-									Line       int       `json:"line"`
-									CreatedAt  time.Time `json:"createdAt"`
-									UpdatedAt  time.Time `json:"updatedAt"`
-									URL        string    `json:"url"`
-									Author     struct {
-										Login string `json:"login"`
-									} `json:"author"`
-								} `json:"nodes"`
-							} `json:"comments"`
-						} `json:"nodes"`
-					} `json:"reviewThreads"`
-				} `json:"pullRequest"`
-			} `json:"repository"`
-		} `json:"data"`
+// listThreadComments fetches the comments of a review thread that follow cursor
+func (c *GitHubCLIClient) listThreadComments(ctx context.Context, threadID, cursor string) ([]threadComment, error) {
+	query := `
+	query($thread: ID!, $cursor: String) {
+		node(id: $thread) {
+			... on PullRequestReviewThread {
+				comments(first: 100, after: $cursor) {
+					pageInfo {
+						hasNextPage
+						endCursor
+					}
+					nodes {
+						...threadComment
+					}
+				}
+			}
+		}
+	}` + threadCommentFragment
+
+	var comments []threadComment
+	for {
+		out, err := c.graphQL(ctx, query,
+			map[string]string{"thread": threadID, "cursor": cursor}, nil,
+			"failed to fetch review thread comments")
+		if err != nil {
+			return nil, err
+		}
+
+		var response struct {
+			Data struct {
+				Node *struct {
+					Comments threadCommentPage `json:"comments"`
+				} `json:"node"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(out, &response); err != nil {
+			return nil, domain.ErrJSONParse("failed to parse review thread comments", err)
+		}
+		if response.Data.Node == nil {
+			return nil, domain.ErrGitHubGraphQL(fmt.Sprintf("review thread %s missing from GraphQL response", threadID), nil)
+		}
+
+		page := response.Data.Node.Comments
+		comments = append(comments, page.Nodes...)
+		if !page.PageInfo.HasNextPage || page.PageInfo.EndCursor == "" {
+			return comments, nil
+		}
+		cursor = page.PageInfo.EndCursor
 	}
+}
 
-	if err := json.Unmarshal(out, &response); err != nil {
-		return nil, domain.ErrJSONParse("failed to parse GraphQL response", err)
+// graphQL runs a query with retries, turning transport and GraphQL errors into
+// review errors described by action
+func (c *GitHubCLIClient) graphQL(ctx context.Context, query string, stringVars map[string]string, intVars map[string]int, action string) ([]byte, error) {
+	out, err := c.withRetry(ctx, func() ([]byte, error) {
+		return c.api.GraphQL(ctx, query, stringVars, intVars)
+	})
+	if err != nil {
+		return nil, domain.ErrGitHubAPI(action, err)
+	}
+	if err := checkGraphQLErrors(out, action); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListCodeRabbitComments fetches all CodeRabbit review comments for a PR using GraphQL
+// This includes the thread's isResolved status which is not available via REST API
+func (c *GitHubCLIClient) ListCodeRabbitComments(ctx context.Context, owner, repo string, number int) ([]domain.Comment, error) {
+	// Use GraphQL to fetch review threads with resolved status
+	threads, err := c.listThreads(ctx, owner, repo, number)
+	if err != nil {
+		return nil, err
 	}
 
 	var allComments []domain.Comment
-	for _, thread := range response.Data.Repository.PullRequest.ReviewThreads.Nodes {
+	for _, thread := range threads {
 		for _, comment := range thread.Comments.Nodes {
 			// Only include CodeRabbit comments
-			if !strings.Contains(strings.ToLower(comment.Author.Login), "coderabbit") {
+			if !c.bots.Matches(comment.Author.Login) {
 				continue
 			}
 
+			// Multi-line comments report the range as originalStartLine..originalLine
+			lineNumber, endLine := comment.Line, 0
+			if comment.StartLine != nil && *comment.StartLine > 0 && *comment.StartLine < comment.Line {
+				lineNumber, endLine = *comment.StartLine, comment.Line
+			}
+
 			domainComment := domain.Comment{
-				ID:         comment.DatabaseID,
-				FilePath:   comment.Path,
-				LineNumber: comment.Line,
-				Body:       comment.Body,
-				AIPrompt:   extractAIPrompt(comment.Body),
-				Author:     comment.Author.Login,
-				CreatedAt:  comment.CreatedAt,
-				UpdatedAt:  comment.UpdatedAt,
-				URL:        comment.URL,
-				IsNit:      isNit(comment.Body),
-				IsOutdated: thread.IsOutdated,
-				IsResolved: thread.IsResolved, // Now properly set from thread!
+				ID:              comment.DatabaseID,
+				FilePath:        comment.Path,
+				LineNumber:      lineNumber,
+				EndLine:         endLine,
+				Body:            comment.Body,
+				AIPrompt:        extractAIPrompt(comment.Body),
+				SuggestedChange: extractSuggestion(comment.Body),
+				DiffHunk:        comment.DiffHunk,
+				Author:          comment.Author.Login,
+				CreatedAt:       comment.CreatedAt,
+				UpdatedAt:       comment.UpdatedAt,
+				URL:             comment.URL,
+				IsNit:           isNit(comment.Body),
+				IsOutdated:      thread.IsOutdated,
+				IsResolved:      thread.IsResolved, // Now properly set from thread!
 			}
 			allComments = append(allComments, domainComment)
 		}
 	}
 
-	// Also fetch general PR comments (issue comments) - these don't have threads
-	issueCommentsArgs := []string{
-		"api",
-		fmt.Sprintf("repos/%s/%s/issues/%d/comments", owner, repo, number),
-		"--paginate",
+	// Also fetch general PR comments (issue comments) - these don't have threads.
+	// A failure fails the listing, as a partial set would look like fewer open comments.
+	issueComments, err := c.listIssueComments(ctx, owner, repo, number)
+	if err != nil {
+		return nil, err
 	}
+	for _, comment := range issueComments {
+		if !c.bots.Matches(comment.User.Login) {
+			continue
+		}
+		// Skip auto-generated summary comments
+		if isAutoGeneratedComment(comment.Body) {
+			continue
+		}
 
-	issueCommentsOut, err := c.runGH(ctx, issueCommentsArgs...)
-	if err == nil {
-		var issueComments []ghComment
-		if json.Unmarshal(issueCommentsOut, &issueComments) == nil {
-			for _, comment := range issueComments {
-				if !strings.Contains(strings.ToLower(comment.User.Login), "coderabbit") {
-					continue
-				}
-				// Skip auto-generated summary comments
-				if isAutoGeneratedComment(comment.Body) {
-					continue
-				}
+		createdAt, _ := time.Parse(time.RFC3339, comment.CreatedAt)
+		updatedAt, _ := time.Parse(time.RFC3339, comment.UpdatedAt)
+
+		domainComment := domain.Comment{
+			ID:        comment.ID,
+			Body:      comment.Body,
+			AIPrompt:  extractAIPrompt(comment.Body),
+			Author:    comment.User.Login,
+			CreatedAt: createdAt,
+			UpdatedAt: updatedAt,
+			URL:       comment.HTMLURL,
+			IsNit:     isNit(comment.Body),
+		}
+		allComments = append(allComments, domainComment)
+	}
 
-				createdAt, _ := time.Parse(time.RFC3339, comment.CreatedAt)
-				updatedAt, _ := time.Parse(time.RFC3339, comment.UpdatedAt)
-
-				domainComment := domain.Comment{
-					ID:        comment.ID,
-					Body:      comment.Body,
-					AIPrompt:  extractAIPrompt(comment.Body),
-					Author:    comment.User.Login,
-					CreatedAt: createdAt,
-					UpdatedAt: updatedAt,
-					URL:       comment.HTMLURL,
-					IsNit:     isNit(comment.Body),
-				}
-				allComments = append(allComments, domainComment)
+	// Nitpicks and outside-diff comments only appear in the review bodies.
+	// Reviews repeat them, but their synthetic IDs are stable so each is kept once.
+	reviews, err := c.listReviews(ctx, owner, repo, number)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[int]bool)
+	for _, review := range reviews {
+		if !c.bots.Matches(review.User.Login) {
+			continue
+		}
+
+		submittedAt, _ := time.Parse(time.RFC3339, review.SubmittedAt)
+		parsed := append(parseNitpicksFromReview(review.Body), parseOutsideDiffFromReview(review.Body)...)
+		for _, comment := range parsed {
+			if seen[comment.ID] {
+				continue
+			}
+			seen[comment.ID] = true
+
+			comment.Author = review.User.Login
+			comment.URL = review.HTMLURL
+			if !submittedAt.IsZero() {
+				comment.CreatedAt = submittedAt
+				comment.UpdatedAt = submittedAt
 			}
+			allComments = append(allComments, comment)
 		}
 	}
 
@@ -240,6 +437,56 @@ func (c *GitHubCLIClient) ListCodeRabbitComments(ctx context.Context, owner, rep
 	return allComments, nil
 }
 
+// listReviews fetches the reviews submitted on a PR
+func (c *GitHubCLIClient) listReviews(ctx context.Context, owner, repo string, number int) ([]ghReview, error) {
+	out, err := c.api.REST(ctx, "GET", fmt.Sprintf("repos/%s/%s/pulls/%d/reviews", owner, repo, number), nil, true)
+	if err != nil {
+		return nil, domain.ErrGitHubAPI("failed to fetch reviews", err)
+	}
+
+	var reviews []ghReview
+	if err := json.Unmarshal(out, &reviews); err != nil {
+		return nil, domain.ErrJSONParse("failed to parse reviews", err)
+	}
+
+	return reviews, nil
+}
+
+// GetCodeRabbitSummary returns the walkthrough/summary section of CodeRabbit's
+// auto-generated PR comment, or an empty string if there is none
+func (c *GitHubCLIClient) GetCodeRabbitSummary(ctx context.Context, owner, repo string, number int) (string, error) {
+	issueComments, err := c.listIssueComments(ctx, owner, repo, number)
+	if err != nil {
+		return "", err
+	}
+
+	for _, comment := range issueComments {
+		if !c.bots.Matches(comment.User.Login) {
+			continue
+		}
+		if summary := extractSummary(comment.Body); summary != "" {
+			return summary, nil
+		}
+	}
+
+	return "", nil
+}
+
+// listIssueComments fetches the general (non-review) comments on a PR
+func (c *GitHubCLIClient) listIssueComments(ctx context.Context, owner, repo string, number int) ([]ghComment, error) {
+	out, err := c.api.REST(ctx, "GET", fmt.Sprintf("repos/%s/%s/issues/%d/comments", owner, repo, number), nil, true)
+	if err != nil {
+		return nil, domain.ErrGitHubAPI("failed to fetch issue comments", err)
+	}
+
+	var comments []ghComment
+	if err := json.Unmarshal(out, &comments); err != nil {
+		return nil, domain.ErrJSONParse("failed to parse issue comments", err)
+	}
+
+	return comments, nil
+}
+
 // GetLatestCommit returns the HEAD commit SHA of the PR
 func (c *GitHubCLIClient) GetLatestCommit(ctx context.Context, owner, repo string, number int) (string, error) {
 	args := []string{
@@ -249,7 +496,7 @@ func (c *GitHubCLIClient) GetLatestCommit(ctx context.Context, owner, repo strin
 		"-q", ".headRefOid",
 	}
 
-	out, err := c.runGH(ctx, args...)
+	out, err := runGH(ctx, args...)
 	if err != nil {
 		return "", domain.ErrGitHubAPI("failed to get latest commit", err)
 	}
@@ -257,6 +504,27 @@ func (c *GitHubCLIClient) GetLatestCommit(ctx context.Context, owner, repo strin
 	return strings.TrimSpace(string(out)), nil
 }
 
+// GetCommitTime returns when a commit was committed
+func (c *GitHubCLIClient) GetCommitTime(ctx context.Context, owner, repo, sha string) (time.Time, error) {
+	out, err := c.api.REST(ctx, "GET", fmt.Sprintf("repos/%s/%s/commits/%s", owner, repo, sha), nil, false)
+	if err != nil {
+		return time.Time{}, domain.ErrGitHubAPI(fmt.Sprintf("failed to look up commit %s", sha), err)
+	}
+
+	var commit struct {
+		Commit struct {
+			Committer struct {
+				Date time.Time `json:"date"`
+			} `json:"committer"`
+		} `json:"commit"`
+	}
+	if err := json.Unmarshal(out, &commit); err != nil {
+		return time.Time{}, domain.ErrJSONParse("failed to parse commit date", err)
+	}
+
+	return commit.Commit.Committer.Date, nil
+}
+
 // GetDiff returns the diff for the PR
 func (c *GitHubCLIClient) GetDiff(ctx context.Context, owner, repo string, number int) (string, error) {
 	args := []string{
@@ -264,7 +532,7 @@ func (c *GitHubCLIClient) GetDiff(ctx context.Context, owner, repo string, numbe
 		"--repo", fmt.Sprintf("%s/%s", owner, repo),
 	}
 
-	out, err := c.runGH(ctx, args...)
+	out, err := runGH(ctx, args...)
 	if err != nil {
 		return "", domain.ErrGitHubAPI("failed to get diff", err)
 	}
@@ -276,7 +544,7 @@ func (c *GitHubCLIClient) GetDiff(ctx context.Context, owner, repo string, numbe
 func (c *GitHubCLIClient) GetCurrentPR(ctx context.Context) (int, error) {
 	args := []string{"pr", "view", "--json", "number", "-q", ".number"}
 
-	out, err := c.runGH(ctx, args...)
+	out, err := runGH(ctx, args...)
 	if err != nil {
 		return 0, domain.ErrGitHubAPI("failed to detect current PR", err)
 	}
@@ -289,10 +557,39 @@ func (c *GitHubCLIClient) GetCurrentPR(ctx context.Context) (int, error) {
 	return number, nil
 }
 
+// GetPRForBranch finds the open PR whose head is the given branch
+func (c *GitHubCLIClient) GetPRForBranch(ctx context.Context, branch string) (int, error) {
+	args := []string{"pr", "list", "--head", branch, "--state", "open", "--json", "number"}
+
+	out, err := runGH(ctx, args...)
+	if err != nil {
+		return 0, domain.ErrGitHubAPI("failed to find PR for branch", err)
+	}
+
+	var prs []struct {
+		Number int `json:"number"`
+	}
+	if err := json.Unmarshal(out, &prs); err != nil {
+		return 0, domain.ErrJSONParse("failed to parse PR list", err)
+	}
+
+	switch len(prs) {
+	case 0:
+		return 0, domain.ErrNoPRForBranch(branch)
+	case 1:
+		return prs[0].Number, nil
+	default:
+		numbers := make([]int, len(prs))
+		for i, pr := range prs {
+			numbers[i] = pr.Number
+		}
+		return 0, domain.ErrMultiplePRsForBranch(branch, numbers)
+	}
+}
+
 // GetRepoInfo returns the owner and repo from the current git remote
 func (c *GitHubCLIClient) GetRepoInfo(ctx context.Context) (owner, repo string, err error) {
-	cmd := exec.CommandContext(ctx, "git", "config", "--get", "remote.origin.url")
-	out, err := cmd.Output()
+	out, err := exec.Output(ctx, "git", "config", "--get", "remote.origin.url")
 	if err != nil {
 		return "", "", domain.ErrGitHubAPI("failed to get remote URL", err)
 	}
@@ -313,8 +610,7 @@ func (c *GitHubCLIClient) GetRepoInfo(ctx context.Context) (owner, repo string,
 
 // GetCurrentBranch returns the current git branch name
 func (c *GitHubCLIClient) GetCurrentBranch(ctx context.Context) (string, error) {
-	cmd := exec.CommandContext(ctx, "git", "branch", "--show-current")
-	out, err := cmd.Output()
+	out, err := exec.Output(ctx, "git", "branch", "--show-current")
 	if err != nil {
 		return "", domain.ErrGitHubAPI("failed to get current branch", err)
 	}
@@ -326,13 +622,8 @@ func (c *GitHubCLIClient) GetCurrentBranch(ctx context.Context) (string, error)
 func (c *GitHubCLIClient) ReplyToComment(ctx context.Context, owner, repo string, prNumber, commentID int, body string) error {
 	// Use the GraphQL API to reply to a review comment
 	// The REST API doesn't support replying directly to review comments
-	args := []string{
-		"api",
-		fmt.Sprintf("repos/%s/%s/pulls/%d/comments/%d/replies", owner, repo, prNumber, commentID),
-		"-f", fmt.Sprintf("body=%s", body),
-	}
-
-	_, err := c.runGH(ctx, args...)
+	path := fmt.Sprintf("repos/%s/%s/pulls/%d/comments/%d/replies", owner, repo, prNumber, commentID)
+	_, err := c.api.REST(ctx, "POST", path, map[string]string{"body": body}, false)
 	if err != nil {
 		return domain.ErrGitHubAPI("failed to reply to comment", err)
 	}
@@ -340,141 +631,419 @@ func (c *GitHubCLIClient) ReplyToComment(ctx context.Context, owner, repo string
 	return nil
 }
 
+// resolveAttempts and resolveBackoff bound the retries around the GraphQL calls in
+// ResolveComments; the backoff doubles after each failed attempt
+const resolveAttempts = 3
+
+var resolveBackoff = time.Second
+
+// resolveBatchSize caps how many threads one aliased resolve mutation covers
+const resolveBatchSize = 50
+
 // ResolveComment marks a review comment thread as resolved using GraphQL
 func (c *GitHubCLIClient) ResolveComment(ctx context.Context, owner, repo string, prNumber, commentID int) error {
-	// First, we need to get the thread ID for this comment via GraphQL
-	// The REST API doesn't support resolving comments directly
-	query := fmt.Sprintf(`
-		query {
-			repository(owner: "%s", name: "%s") {
-				pullRequest(number: %d) {
-					reviewThreads(first: 100) {
-						nodes {
-							id
-							isResolved
-							comments(first: 1) {
-								nodes {
-									databaseId
-								}
-							}
-						}
-					}
+	_, failed := c.ResolveComments(ctx, owner, repo, prNumber, []int{commentID})
+	return failed[commentID]
+}
+
+// ResolveComments marks the threads of several review comments as resolved. The
+// PR's threads are listed once and the unresolved ones are resolved with batched,
+// aliased GraphQL mutations, so resolving is idempotent. Transient gh failures are
+// retried with backoff. It returns the comments whose threads this call resolved,
+// and an ErrResolveFailed for each comment that still failed.
+func (c *GitHubCLIClient) ResolveComments(ctx context.Context, owner, repo string, prNumber int, commentIDs []int) (resolved []int, failed map[int]error) {
+	if len(commentIDs) == 0 {
+		return nil, nil
+	}
+
+	failed = make(map[int]error)
+	threads, err := c.listReviewThreads(ctx, owner, repo, prNumber)
+	if err != nil {
+		for _, id := range commentIDs {
+			failed[id] = domain.ErrResolveFailed(id, err)
+		}
+		return nil, failed
+	}
+
+	c.resolvedMu.Lock()
+	defer c.resolvedMu.Unlock()
+
+	// Several comments can share a thread; resolve each thread only once per run
+	var threadIDs []string
+	commentsByThread := make(map[string][]int)
+	for _, id := range commentIDs {
+		thread, ok := threads[id]
+		if !ok || thread.IsResolved || c.resolvedThreads[thread.ID] {
+			// Comment not found or already resolved
+			continue
+		}
+		if _, seen := commentsByThread[thread.ID]; !seen {
+			threadIDs = append(threadIDs, thread.ID)
+		}
+		commentsByThread[thread.ID] = append(commentsByThread[thread.ID], id)
+	}
+
+	for start := 0; start < len(threadIDs); start += resolveBatchSize {
+		batch := threadIDs[start:min(start+resolveBatchSize, len(threadIDs))]
+		failedThreads := c.resolveThreads(ctx, batch)
+		for _, threadID := range batch {
+			if err, ok := failedThreads[threadID]; ok {
+				for _, id := range commentsByThread[threadID] {
+					failed[id] = domain.ErrResolveFailed(id, err)
 				}
+				continue
 			}
+			c.resolvedThreads[threadID] = true
+			resolved = append(resolved, commentsByThread[threadID]...)
+		}
+	}
+
+	if len(failed) == 0 {
+		return resolved, nil
+	}
+	return resolved, failed
+}
+
+// reviewThread identifies a PR review thread and whether it's resolved
+type reviewThread struct {
+	ID         string
+	IsResolved bool
+}
+
+// listReviewThreads maps the database ID of every comment on a PR to its thread
+func (c *GitHubCLIClient) listReviewThreads(ctx context.Context, owner, repo string, prNumber int) (map[int]reviewThread, error) {
+	// The REST API doesn't expose threads, so they're listed via GraphQL
+	nodes, err := c.listThreads(ctx, owner, repo, prNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	threads := make(map[int]reviewThread)
+	for _, thread := range nodes {
+		for _, comment := range thread.Comments.Nodes {
+			threads[comment.DatabaseID] = reviewThread{ID: thread.ID, IsResolved: thread.IsResolved}
 		}
-	`, owner, repo, prNumber)
+	}
+	return threads, nil
+}
+
+// resolveThreads resolves review threads in a single mutation, one alias per
+// thread, and returns the error for each thread left unresolved. GraphQL errors
+// are matched to threads by their alias; if the request fails outright, a batch
+// is retried one thread at a time so a bad thread can't fail the rest.
+func (c *GitHubCLIClient) resolveThreads(ctx context.Context, threadIDs []string) map[string]error {
+	var params []string
+	var fields strings.Builder
+	vars := make(map[string]string, len(threadIDs))
+	aliases := make(map[string]string, len(threadIDs))
+	for i, threadID := range threadIDs {
+		alias := fmt.Sprintf("t%d", i)
+		params = append(params, fmt.Sprintf("$%s: ID!", alias))
+		vars[alias] = threadID
+		aliases[alias] = threadID
+		fmt.Fprintf(&fields, `
+			%s: resolveReviewThread(input: {threadId: $%s}) {
+				thread {
+					isResolved
+				}
+			}`, alias, alias)
+	}
+	mutation := fmt.Sprintf("mutation(%s) {%s\n}", strings.Join(params, ", "), fields.String())
 
-	args := []string{"api", "graphql", "-f", fmt.Sprintf("query=%s", query)}
-	out, err := c.runGH(ctx, args...)
+	failed := make(map[string]error)
+	out, err := c.withRetry(ctx, func() ([]byte, error) {
+		return c.api.GraphQL(ctx, mutation, vars, nil)
+	})
 	if err != nil {
-		return domain.ErrGitHubAPI("failed to fetch review threads", err)
+		if len(threadIDs) == 1 {
+			failed[threadIDs[0]] = domain.ErrGitHubAPI("failed to resolve comment thread", err)
+			return failed
+		}
+		for _, threadID := range threadIDs {
+			for id, err := range c.resolveThreads(ctx, []string{threadID}) {
+				failed[id] = err
+			}
+		}
+		return failed
 	}
 
-	// Parse response to find the thread ID for our comment
 	var response struct {
-		Data struct {
-			Repository struct {
-				PullRequest struct {
-					ReviewThreads struct {
-						Nodes []struct {
-							ID         string `json:"id"`
-							IsResolved bool   `json:"isResolved"`
-							Comments   struct {
-								Nodes []struct {
-									DatabaseID int `json:"databaseId"`
-								} `json:"nodes"`
-							} `json:"comments"`
-						} `json:"nodes"`
-					} `json:"reviewThreads"`
-				} `json:"pullRequest"`
-			} `json:"repository"`
+		Data map[string]*struct {
+			Thread *struct {
+				IsResolved bool `json:"isResolved"`
+			} `json:"thread"`
 		} `json:"data"`
+		Errors []graphQLError `json:"errors"`
 	}
-
 	if err := json.Unmarshal(out, &response); err != nil {
-		return domain.ErrJSONParse("failed to parse review threads", err)
+		for _, threadID := range threadIDs {
+			failed[threadID] = domain.ErrJSONParse("failed to parse resolve response", err)
+		}
+		return failed
 	}
 
-	// Find the thread containing our comment
-	var threadID string
-	for _, thread := range response.Data.Repository.PullRequest.ReviewThreads.Nodes {
-		if thread.IsResolved {
+	// Errors without an alias in their path can't be pinned on one thread, so
+	// they're reported for every thread the response doesn't show as resolved
+	var unattributed []graphQLError
+	for _, e := range response.Errors {
+		alias := ""
+		if len(e.Path) > 0 {
+			alias, _ = e.Path[0].(string)
+		}
+		if threadID, ok := aliases[alias]; ok {
+			failed[threadID] = graphQLErrors([]graphQLError{e}, "failed to resolve comment thread")
 			continue
 		}
-		for _, comment := range thread.Comments.Nodes {
-			if comment.DatabaseID == commentID {
-				threadID = thread.ID
-				break
-			}
+		unattributed = append(unattributed, e)
+	}
+	for alias, threadID := range aliases {
+		if _, ok := failed[threadID]; ok {
+			continue
+		}
+		result := response.Data[alias]
+		if result != nil && result.Thread != nil && result.Thread.IsResolved {
+			continue
+		}
+		if len(unattributed) > 0 {
+			failed[threadID] = graphQLErrors(unattributed, "failed to resolve comment thread")
+		} else {
+			failed[threadID] = domain.ErrGitHubGraphQL("thread still unresolved after resolve mutation", nil)
+		}
+	}
+	return failed
+}
+
+// withRetry runs a GitHub request, retrying failures with exponential backoff
+// until resolveAttempts is reached or the context is cancelled
+func (c *GitHubCLIClient) withRetry(ctx context.Context, request func() ([]byte, error)) ([]byte, error) {
+	backoff := resolveBackoff
+	var lastErr error
+	for attempt := 1; attempt <= resolveAttempts; attempt++ {
+		out, err := request()
+		if err == nil {
+			return out, nil
 		}
-		if threadID != "" {
+		lastErr = err
+		if attempt == resolveAttempts || !retryableGHError(err) {
 			break
 		}
+
+		logging.Debug("GitHub request failed, retrying", "attempt", attempt, "backoff", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
 	}
+	return nil, lastErr
+}
 
-	if threadID == "" {
-		// Comment not found or already resolved
-		return nil
+// ghServerError matches the HTTP status gh reports for a GitHub server error
+var ghServerError = regexp.MustCompile(`(?i)http 5\d\d`)
+
+// ghNetworkMarkers are lowercase gh error messages for a request that didn't
+// reach GitHub or whose response was cut off
+var ghNetworkMarkers = []string{
+	"error connecting to",
+	"connection reset",
+	"connection refused",
+	"i/o timeout",
+	"tls handshake timeout",
+	"unexpected eof",
+	"no such host",
+}
+
+// retryableGHError returns true for transient GitHub failures another attempt
+// may fix: network errors, server errors, and rate limits. Anything else, such
+// as a GraphQL or validation error, fails on the first attempt.
+func retryableGHError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
 	}
 
-	// Resolve the thread
-	mutation := fmt.Sprintf(`
-		mutation {
-			resolveReviewThread(input: {threadId: "%s"}) {
-				thread {
-					isResolved
-				}
+	var reviewErr *domain.ReviewError
+	if errors.As(err, &reviewErr) {
+		return reviewErr.Code == domain.ErrCodeGitHubRateLimit
+	}
+
+	// Failed responses from the HTTP client
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	// Failed gh commands, known only by what gh printed
+	var execErr *exec.Error
+	if errors.As(err, &execErr) {
+		if execErr.Kind != exec.KindGeneric {
+			return execErr.Kind == exec.KindRateLimit
+		}
+		stderr := strings.ToLower(execErr.Stderr)
+		for _, marker := range ghNetworkMarkers {
+			if strings.Contains(stderr, marker) {
+				return true
 			}
 		}
-	`, threadID)
+		return ghServerError.MatchString(stderr)
+	}
 
-	args = []string{"api", "graphql", "-f", fmt.Sprintf("query=%s", mutation)}
-	_, err = c.runGH(ctx, args...)
-	if err != nil {
-		return domain.ErrGitHubAPI("failed to resolve comment thread", err)
+	// Transport failures from the HTTP client
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// CheckAuth verifies that the gh CLI is installed and logged in, so an
+// unauthenticated user gets a clear error instead of a failed API call.
+// The check runs once per client; later calls return the first result.
+func (c *GitHubCLIClient) CheckAuth(ctx context.Context) error {
+	c.authOnce.Do(func() {
+		_, err := exec.Output(ctx, "gh", "auth", "status")
+		if err == nil {
+			return
+		}
+
+		if exec.IsNotFound(err) {
+			c.authErr = domain.ErrGitHubAPI("GitHub CLI (gh) not found, install it from https://cli.github.com", err)
+			return
+		}
+		c.authErr = domain.ErrGitHubAuth(fmt.Errorf("gh is not logged in, run `gh auth login` and try again"))
+	})
+	return c.authErr
+}
+
+// aiPromptMarker labels the collapsible block holding CodeRabbit's agent prompt
+const aiPromptMarker = "Prompt for AI Agents"
+
+// extractAIPrompt extracts the "Prompt for AI Agents" section from a CodeRabbit comment.
+// The section is usually a <details> block whose body holds one or more fenced segments;
+// language identifiers are dropped and multiple segments are joined in order.
+func extractAIPrompt(body string) string {
+	idx := strings.Index(body, aiPromptMarker)
+	if idx == -1 {
+		return ""
 	}
 
-	return nil
+	section := aiPromptSection(body, idx)
+	segments := fencedSegments(section)
+	if len(segments) == 0 {
+		return ""
+	}
+
+	return strings.TrimSpace(strings.Join(segments, "\n\n"))
 }
 
-// runGH executes a gh CLI command and returns the output
-func (c *GitHubCLIClient) runGH(ctx context.Context, args ...string) ([]byte, error) {
-	cmd := exec.CommandContext(ctx, "gh", args...)
-	out, err := cmd.Output()
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("gh command failed: %s", string(exitErr.Stderr))
+// aiPromptSection returns the text after the marker up to the end of its enclosing
+// <details> block, honoring nested <details>. Without a wrapper it runs to the end of the body.
+func aiPromptSection(body string, markerIdx int) string {
+	rest := body[markerIdx+len(aiPromptMarker):]
+
+	// Only scope to <details> if the marker sits inside one that is still open
+	before := strings.ToLower(body[:markerIdx])
+	if strings.Count(before, "<details") <= strings.Count(before, "</details>") {
+		return rest
+	}
+
+	lower := strings.ToLower(rest)
+	depth := 1
+	for i := 0; i < len(lower); i++ {
+		switch {
+		case strings.HasPrefix(lower[i:], "<details"):
+			depth++
+		case strings.HasPrefix(lower[i:], "</details>"):
+			depth--
+			if depth == 0 {
+				return rest[:i]
+			}
 		}
-		return nil, err
 	}
-	return out, nil
+	return rest
 }
 
-// extractAIPrompt extracts the "Prompt for AI Agents" section from a comment body
-func extractAIPrompt(body string) string {
-	patterns := []string{
-		`🤖\s*Prompt for AI Agents[\s\S]*?` + "```" + `([\s\S]*?)` + "```",
-		`<summary>🤖\s*Prompt for AI Agents</summary>[\s\S]*?` + "```" + `([\s\S]*?)` + "```",
-		`Prompt for AI Agents[\s\S]*?` + "```" + `([\s\S]*?)` + "```",
+// fencedBlock is a top-level fenced code block
+type fencedBlock struct {
+	info    string // Text after the opening fence, e.g. a language or "suggestion"
+	content string
+}
+
+// fencedSegments returns the non-empty contents of each top-level ``` or ~~~ fenced block in text
+func fencedSegments(text string) []string {
+	var segments []string
+	for _, block := range fencedBlocks(text) {
+		if strings.TrimSpace(block.content) != "" {
+			segments = append(segments, block.content)
+		}
 	}
+	return segments
+}
+
+// fencedBlocks returns each closed top-level ``` or ~~~ fenced block in text.
+// A block closes on a fence of the same character at least as long as the opener,
+// so shorter inner fences are kept as content.
+func fencedBlocks(text string) []fencedBlock {
+	var blocks []fencedBlock
+	var current []string
+	var info string
+	fenceChar, fenceLen := byte(0), 0
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		char, n := fenceRun(trimmed)
+
+		if fenceLen == 0 {
+			if n >= 3 {
+				// Opening fence - anything after it is the info string
+				fenceChar, fenceLen = char, n
+				info = strings.TrimSpace(trimmed[n:])
+				current = nil
+			}
+			continue
+		}
 
-	for _, pattern := range patterns {
-		re := regexp.MustCompile(pattern)
-		matches := re.FindStringSubmatch(body)
-		if len(matches) > 1 {
-			return strings.TrimSpace(matches[1])
+		if char == fenceChar && n >= fenceLen && strings.TrimSpace(trimmed[n:]) == "" {
+			blocks = append(blocks, fencedBlock{info: info, content: strings.Join(current, "\n")})
+			fenceLen = 0
+			continue
 		}
+		current = append(current, line)
 	}
 
-	return ""
+	return blocks
+}
+
+// fenceRun returns the fence character and run length at the start of a line
+func fenceRun(line string) (byte, int) {
+	if line == "" || (line[0] != '`' && line[0] != '~') {
+		return 0, 0
+	}
+	n := 0
+	for n < len(line) && line[n] == line[0] {
+		n++
+	}
+	return line[0], n
 }
 
-// isNit checks if a comment is a nitpick
+// codeRabbitNitLabel matches the label CodeRabbit puts on nitpick comments,
+// e.g. "_🧹 Nitpick (assertive)_"
+var codeRabbitNitLabel = regexp.MustCompile(`(?i)🧹\s*\**_?\s*nitpick`)
+
+// nitPrefix matches a comment that opens by calling itself a nit, e.g. "nit:",
+// "**Nitpick**:" or "[nit]", but not words that merely contain "nit" such as
+// "unit:" or "initialize"
+var nitPrefix = regexp.MustCompile(`(?i)^[\s*_>]*(?:[\[(]\s*nit(?:pick|picky)?\s*[\])]|nit(?:pick|picky)?\b[*_]*\s*(?:[:\-](?:[^=]|$)|[,–—]|$))`)
+
+// isNit checks if a comment is a nitpick, by CodeRabbit's label or by opening
+// with "nit". Code and mentions later in the comment don't count.
 func isNit(body string) bool {
-	lower := strings.ToLower(body)
-	return strings.Contains(lower, "nit:") ||
-		strings.Contains(lower, "nitpick") ||
-		regexp.MustCompile(`\b(nit|nitpick)\b`).MatchString(lower)
+	if codeRabbitNitLabel.MatchString(body) {
+		return true
+	}
+	for _, line := range strings.Split(body, "\n") {
+		if strings.TrimSpace(line) != "" {
+			return nitPrefix.MatchString(line)
+		}
+	}
+	return false
 }
 
 // isAutoGeneratedComment checks if a comment is auto-generated
@@ -496,6 +1065,30 @@ func isAutoGeneratedComment(body string) bool {
 	return false
 }
 
+// extractSummary extracts the "## Walkthrough" or "## Summary" section from a
+// CodeRabbit auto-generated comment, stopping at the next heading or details block
+func extractSummary(body string) string {
+	for _, heading := range []string{"## Walkthrough", "## Summary"} {
+		idx := strings.Index(body, heading)
+		if idx == -1 {
+			continue
+		}
+
+		section := body[idx+len(heading):]
+		end := len(section)
+		for _, marker := range []string{"\n## ", "<details", "<!--"} {
+			if i := strings.Index(section, marker); i != -1 && i < end {
+				end = i
+			}
+		}
+
+		if summary := strings.TrimSpace(section[:end]); summary != "" {
+			return summary
+		}
+	}
+	return ""
+}
+
 // parseNitpicksFromReview extracts nitpick comments from the review body HTML
 func parseNitpicksFromReview(body string) []domain.Comment {
 	// Look for the nitpicks section
@@ -519,6 +1112,10 @@ func parseNitpicksFromReview(body string) []domain.Comment {
 		}
 
 		lineStart := content[matchIdx[2]:matchIdx[3]]
+		lineEnd := 0
+		if matchIdx[4] >= 0 {
+			lineEnd = parseInt(content[matchIdx[4]:matchIdx[5]])
+		}
 		title := content[matchIdx[6]:matchIdx[7]]
 
 		// Get body: from end of title to next comment or end
@@ -545,19 +1142,30 @@ func parseNitpicksFromReview(body string) []domain.Comment {
 		}
 
 		comment := domain.Comment{
-			ID:        -i - 1000, // Synthetic ID for nitpicks
-			FilePath:  filePath,
+			FilePath:   filePath,
 			LineNumber: parseInt(lineStart),
-			Body:      fmt.Sprintf("**%s** %s", title, body),
-			IsNit:     true,
-			CreatedAt: time.Now(),
+			EndLine:    lineEnd,
+			Body:       fmt.Sprintf("**%s** %s", title, body),
+			IsNit:      true,
+			CreatedAt:  time.Now(),
 		}
+		comment.ID = syntheticCommentID(comment)
 		comments = append(comments, comment)
 	}
 
 	return comments
 }
 
+// syntheticCommentID derives a negative ID for a comment parsed from a review
+// body from its file, line and content, so the same nitpick keeps its identity
+// across fetches however the list around it changes
+func syntheticCommentID(comment domain.Comment) int {
+	hash := state.HashComment(comment.FilePath, comment.LineNumber, comment.Body)
+	// 28 bits keeps the ID within int on 32-bit platforms
+	n, _ := strconv.ParseInt(hash[:7], 16, 64)
+	return -int(n) - 1
+}
+
 // parseInt parses a string to int, returning 0 on error
 func parseInt(s string) int {
 	var n int
@@ -588,6 +1196,10 @@ func parseOutsideDiffFromReview(body string) []domain.Comment {
 		}
 
 		lineStart := content[matchIdx[2]:matchIdx[3]]
+		lineEnd := 0
+		if matchIdx[4] >= 0 {
+			lineEnd = parseInt(content[matchIdx[4]:matchIdx[5]])
+		}
 		title := content[matchIdx[6]:matchIdx[7]]
 
 		// Get body: from end of title to next comment or end
@@ -612,13 +1224,14 @@ func parseOutsideDiffFromReview(body string) []domain.Comment {
 		}
 
 		comment := domain.Comment{
-			ID:            -i - 2000, // Synthetic ID for outside-diff
 			FilePath:      filePath,
 			LineNumber:    parseInt(lineStart),
+			EndLine:       lineEnd,
 			Body:          fmt.Sprintf("**%s** %s", title, commentBody),
 			IsOutsideDiff: true,
 			CreatedAt:     time.Now(),
 		}
+		comment.ID = syntheticCommentID(comment)
 		comments = append(comments, comment)
 	}
 
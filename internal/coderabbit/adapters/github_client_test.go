@@ -0,0 +1,353 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
+	"github.com/DylanSharp/dtools/internal/exec"
+)
+
+func TestExtractAIPrompt(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "inline comment",
+			body: "_⚠️ Potential issue_\n\n" +
+				"**Handle the error from `os.ReadFile`.**\n\n" +
+				"The error is discarded, so a missing file looks like an empty config.\n\n" +
+				"<details>\n<summary>🤖 Prompt for AI Agents</summary>\n\n" +
+				"```\nIn internal/config/config.go around lines 41 to 44, the error returned by\nos.ReadFile is discarded; return it wrapped with the path instead.\n```\n\n" +
+				"</details>\n\n<!-- fingerprinting:phantom:medusa:lion -->\n\n<!-- This is an auto-generated comment by CodeRabbit -->",
+			want: "In internal/config/config.go around lines 41 to 44, the error returned by\nos.ReadFile is discarded; return it wrapped with the path instead.",
+		},
+		{
+			name: "language hint",
+			body: "<details>\n<summary>🤖 Prompt for AI Agents</summary>\n\n" +
+				"```text\nIn cmd/main.go at line 12, rename cfg to config.\n```\n\n</details>",
+			want: "In cmd/main.go at line 12, rename cfg to config.",
+		},
+		{
+			name: "committable suggestion first",
+			body: "_🛠️ Refactor suggestion_\n\n**Use `errors.Is` for the sentinel.**\n\n" +
+				"<details>\n<summary>📝 Committable suggestion</summary>\n\n" +
+				"> ‼️ **IMPORTANT**\n> Carefully review the code before committing.\n\n" +
+				"```suggestion\n\tif errors.Is(err, io.EOF) {\n```\n\n</details>\n\n" +
+				"<details>\n<summary>🤖 Prompt for AI Agents</summary>\n\n" +
+				"```\nIn reader.go around line 88, compare err with errors.Is(err, io.EOF).\n```\n\n</details>",
+			want: "In reader.go around line 88, compare err with errors.Is(err, io.EOF).",
+		},
+		{
+			name: "tools section after the prompt",
+			body: "<details>\n<summary>🤖 Prompt for AI Agents</summary>\n\n" +
+				"```\nIn a.go at line 3, close the file.\n```\n\n</details>\n\n" +
+				"<details>\n<summary>🧰 Tools</summary>\n\n<details>\n<summary>🪛 golangci-lint</summary>\n\n" +
+				"```\n3-3: Error return value of `f.Close` is not checked (errcheck)\n```\n\n</details>\n\n</details>",
+			want: "In a.go at line 3, close the file.",
+		},
+		{
+			name: "several fenced segments",
+			body: "<details>\n<summary>🤖 Prompt for AI Agents</summary>\n\n" +
+				"```\nIn a.go at line 3, close the file.\n```\n\n" +
+				"```\nIn b.go at line 9, check the error from Close.\n```\n\n</details>",
+			want: "In a.go at line 3, close the file.\n\nIn b.go at line 9, check the error from Close.",
+		},
+		{
+			name: "longer fence around a nested one",
+			body: "<details>\n<summary>🤖 Prompt for AI Agents</summary>\n\n" +
+				"````markdown\nIn README.md around lines 5 to 7, fix the example:\n```go\nfmt.Println(\"hi\")\n```\n````\n\n</details>",
+			want: "In README.md around lines 5 to 7, fix the example:\n```go\nfmt.Println(\"hi\")\n```",
+		},
+		{
+			name: "tilde fence",
+			body: "<details>\n<summary>🤖 Prompt for AI Agents</summary>\n\n~~~\nIn x.go at line 1, add a doc comment.\n~~~\n\n</details>",
+			want: "In x.go at line 1, add a doc comment.",
+		},
+		{
+			name: "outside a details block",
+			body: "**Prompt for AI Agents**\n\n```\nIn x.go at line 1, add a doc comment.\n```",
+			want: "In x.go at line 1, add a doc comment.",
+		},
+		{
+			name: "no prompt",
+			body: "_💡 Verification agent_\n\n```suggestion\nreturn nil\n```",
+			want: "",
+		},
+		{
+			name: "prompt without a fence",
+			body: "<details>\n<summary>🤖 Prompt for AI Agents</summary>\n\nnothing fenced here\n</details>",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractAIPrompt(tt.body); got != tt.want {
+				t.Errorf("extractAIPrompt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeGitHubAPI serves canned GraphQL responses and records the variables of each call
+type fakeGitHubAPI struct {
+	graphQL func(query string, stringVars map[string]string) (map[string]any, error)
+	rest    func(path string) ([]byte, error) // Answers every REST request with an empty list, if nil
+	calls   []map[string]string
+}
+
+func (f *fakeGitHubAPI) REST(ctx context.Context, method, path string, fields map[string]string, paginate bool) ([]byte, error) {
+	if f.rest != nil {
+		return f.rest(path)
+	}
+	return []byte("[]"), nil
+}
+
+func (f *fakeGitHubAPI) GraphQL(ctx context.Context, query string, stringVars map[string]string, intVars map[string]int) ([]byte, error) {
+	f.calls = append(f.calls, stringVars)
+	response, err := f.graphQL(query, stringVars)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(response)
+}
+
+// fakeCommentPage builds a page of CodeRabbit comments numbered from first
+func fakeCommentPage(first, count int, next string) map[string]any {
+	nodes := make([]map[string]any, count)
+	for i := range nodes {
+		nodes[i] = map[string]any{
+			"databaseId": first + i,
+			"body":       fmt.Sprintf("comment %d", first+i),
+			"author":     map[string]any{"login": "coderabbitai[bot]"},
+		}
+	}
+	return map[string]any{
+		"pageInfo": map[string]any{"hasNextPage": next != "", "endCursor": next},
+		"nodes":    nodes,
+	}
+}
+
+func TestListCodeRabbitCommentsPaginates(t *testing.T) {
+	api := &fakeGitHubAPI{graphQL: func(query string, vars map[string]string) (map[string]any, error) {
+		if strings.Contains(query, "node(id: $thread)") {
+			// The rest of the deep thread, in two more pages
+			page := fakeCommentPage(101, 100, "comments-2")
+			if vars["cursor"] == "comments-2" {
+				page = fakeCommentPage(201, 50, "")
+			}
+			return map[string]any{"data": map[string]any{"node": map[string]any{"comments": page}}}, nil
+		}
+
+		threads := map[string]any{
+			"pageInfo": map[string]any{"hasNextPage": true, "endCursor": "threads-1"},
+			"nodes": []any{map[string]any{
+				"id":       "deep",
+				"comments": fakeCommentPage(1, 100, "comments-1"),
+			}},
+		}
+		if vars["cursor"] == "threads-1" {
+			threads = map[string]any{
+				"pageInfo": map[string]any{"hasNextPage": false},
+				"nodes": []any{map[string]any{
+					"id":         "later",
+					"isResolved": true,
+					"comments":   fakeCommentPage(1000, 1, ""),
+				}},
+			}
+		}
+		return fakeThreadsResponse(threads), nil
+	}}
+
+	client := NewGitHubCLIClient()
+	client.api = api
+	comments, err := client.ListCodeRabbitComments(context.Background(), "owner", "repo", 1)
+	if err != nil {
+		t.Fatalf("ListCodeRabbitComments() error = %v", err)
+	}
+
+	if len(comments) != 251 {
+		t.Fatalf("got %d comments, want 251", len(comments))
+	}
+	for i, comment := range comments[:250] {
+		if comment.ID != i+1 {
+			t.Fatalf("comment %d has ID %d, want %d", i, comment.ID, i+1)
+		}
+	}
+	if last := comments[250]; last.ID != 1000 || !last.IsResolved {
+		t.Errorf("last comment = %+v, want the resolved thread's comment 1000", last)
+	}
+	if api.calls[1]["thread"] != "deep" || api.calls[1]["cursor"] != "comments-1" {
+		t.Errorf("thread comments fetched with %v, want thread deep after comments-1", api.calls[1])
+	}
+}
+
+func TestListCodeRabbitCommentsFailsOnPartialListing(t *testing.T) {
+	threads := fakeThreadsResponse(map[string]any{
+		"pageInfo": map[string]any{},
+		"nodes":    []any{map[string]any{"id": "thread", "comments": fakeCommentPage(1, 1, "")}},
+	})
+
+	listings := map[string]string{"issue comments": "/issues/1/comments", "reviews": "/pulls/1/reviews"}
+	for name, failing := range listings {
+		t.Run(name, func(t *testing.T) {
+			client := NewGitHubCLIClient()
+			client.api = &fakeGitHubAPI{
+				graphQL: func(query string, vars map[string]string) (map[string]any, error) { return threads, nil },
+				rest: func(path string) ([]byte, error) {
+					if strings.HasSuffix(path, failing) {
+						return nil, errors.New("gh: Bad credentials (HTTP 401)")
+					}
+					return []byte("[]"), nil
+				},
+			}
+
+			comments, err := client.ListCodeRabbitComments(context.Background(), "owner", "repo", 1)
+			if err == nil {
+				t.Fatalf("ListCodeRabbitComments() = %d comment(s), want the failure listing %s", len(comments), name)
+			}
+		})
+	}
+}
+
+// fakeThreadsResponse wraps a reviewThreads connection in a PR query response
+func fakeThreadsResponse(threads map[string]any) map[string]any {
+	return map[string]any{"data": map[string]any{
+		"repository": map[string]any{"pullRequest": map[string]any{"reviewThreads": threads}},
+	}}
+}
+
+func TestResolveCommentsReportsEachThread(t *testing.T) {
+	defer func(backoff time.Duration) { resolveBackoff = backoff }(resolveBackoff)
+	resolveBackoff = time.Millisecond
+
+	threads := fakeThreadsResponse(map[string]any{
+		"pageInfo": map[string]any{"hasNextPage": false},
+		"nodes": []any{
+			map[string]any{"id": "ok", "comments": fakeCommentPage(1, 1, "")},
+			map[string]any{"id": "locked", "comments": fakeCommentPage(2, 1, "")},
+			map[string]any{"id": "gone", "comments": fakeCommentPage(3, 1, "")},
+		},
+	})
+
+	resolved := map[string]any{"thread": map[string]any{"isResolved": true}}
+	tests := []struct {
+		name   string
+		mutate func(vars map[string]string) (map[string]any, error)
+	}{
+		{
+			name: "errors matched by alias",
+			mutate: func(vars map[string]string) (map[string]any, error) {
+				return map[string]any{
+					"data": map[string]any{"t0": resolved, "t1": nil, "t2": nil},
+					"errors": []any{
+						map[string]any{"type": "FORBIDDEN", "message": "thread is locked", "path": []any{"t1"}},
+						map[string]any{"type": "NOT_FOUND", "message": "no thread", "path": []any{"t2"}},
+					},
+				}, nil
+			},
+		},
+		{
+			name: "failed batch retried per thread",
+			mutate: func(vars map[string]string) (map[string]any, error) {
+				if len(vars) > 1 {
+					return nil, errors.New("gh: exit status 1")
+				}
+				if vars["t0"] != "ok" {
+					return map[string]any{
+						"data":   map[string]any{"t0": nil},
+						"errors": []any{map[string]any{"message": "cannot resolve", "path": []any{"t0"}}},
+					}, nil
+				}
+				return map[string]any{"data": map[string]any{"t0": resolved}}, nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewGitHubCLIClient()
+			client.api = &fakeGitHubAPI{graphQL: func(query string, vars map[string]string) (map[string]any, error) {
+				if strings.HasPrefix(query, "mutation") {
+					return tt.mutate(vars)
+				}
+				return threads, nil
+			}}
+
+			got, failed := client.ResolveComments(context.Background(), "owner", "repo", 1, []int{1, 2, 3})
+			if len(got) != 1 || got[0] != 1 {
+				t.Errorf("resolved = %v, want [1]", got)
+			}
+			if len(failed) != 2 || failed[2] == nil || failed[3] == nil {
+				t.Errorf("failed = %v, want comments 2 and 3", failed)
+			}
+		})
+	}
+}
+
+func TestRetryableGHError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"rate limit", domain.ErrGitHubRateLimit(errors.New("API rate limit exceeded")), true},
+		{"auth", domain.ErrGitHubAuth(errors.New("bad credentials")), false},
+		{"gh not found", domain.ErrGitHubAPI("GitHub CLI (gh) not found", &exec.Error{Kind: exec.KindNotFound}), false},
+		{"gh server error", &exec.Error{Stderr: "gh: Bad Gateway (HTTP 502)"}, true},
+		{"gh network error", &exec.Error{Stderr: "error connecting to api.github.com"}, true},
+		{"gh validation error", &exec.Error{Stderr: "gh: Validation Failed (HTTP 422)"}, false},
+		{"gh GraphQL error", &exec.Error{Stderr: "GraphQL: Could not resolve to a node with the global id of 'x'"}, false},
+		{"HTTP server error", &httpStatusError{StatusCode: 503, Message: "Service Unavailable"}, true},
+		{"HTTP not found", &httpStatusError{StatusCode: 404, Message: "Not Found"}, false},
+		{"network error", &url.Error{Op: "Post", URL: "https://api.github.com/graphql", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}, true},
+		{"cancelled", &url.Error{Op: "Post", URL: "https://api.github.com/graphql", Err: context.Canceled}, false},
+		{"other", errors.New("gh: exit status 1"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryableGHError(tt.err); got != tt.want {
+				t.Errorf("retryableGHError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsNit(t *testing.T) {
+	tests := []struct {
+		body string
+		want bool
+	}{
+		{"_🧹 Nitpick (assertive)_\n\n**Rename the variable.**", true},
+		{"_⚠️ Potential issue_\n\n<details>\n<summary>🧹 Nitpick comments (2)</summary>", true},
+		{"nit: trailing whitespace", true},
+		{"Nit - prefer a constant here", true},
+		{"**Nitpick**: use fmt.Errorf", true},
+		{"[nit] missing period", true},
+		{"> nit, but this reads oddly", true},
+		{"initialize the map before use", false},
+		{"unit: tests are missing for this branch", false},
+		{"Initialize: this is never set", false},
+		{"Units are wrong here", false},
+		{"_⚠️ Potential issue_\n\nThis panics.\n\n```go\nnit := 1\n```", false},
+		{"The nit: prefix in the linter config is ignored", false},
+		{"nit-=1 is never reached", false},
+	}
+
+	for _, tt := range tests {
+		if got := isNit(tt.body); got != tt.want {
+			t.Errorf("isNit(%q) = %v, want %v", tt.body, got, tt.want)
+		}
+	}
+}
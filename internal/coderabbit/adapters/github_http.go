@@ -0,0 +1,512 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
+	"github.com/DylanSharp/dtools/internal/coderabbit/ports"
+	"github.com/DylanSharp/dtools/internal/logging"
+)
+
+// tokenEnvVars are checked in order for a GitHub token, the same ones gh reads
+var tokenEnvVars = []string{"GH_TOKEN", "GITHUB_TOKEN"}
+
+const (
+	// defaultAPIURL is GitHub's REST API; GITHUB_API_URL overrides it for Enterprise Server
+	defaultAPIURL = "https://api.github.com"
+	// httpTimeout bounds a single request to GitHub
+	httpTimeout = 30 * time.Second
+	// pageSize is the page size asked for when paginating, GitHub's maximum
+	pageSize = 100
+)
+
+// nextLinkPattern finds the next page in a Link header
+var nextLinkPattern = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// TokenFromEnv returns the GitHub token in GH_TOKEN or GITHUB_TOKEN, or "" if neither is set
+func TokenFromEnv() string {
+	for _, name := range tokenEnvVars {
+		if token := os.Getenv(name); token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
+// httpAPI implements githubAPI with HTTPS requests authenticated by a token
+type httpAPI struct {
+	token      string
+	restURL    string
+	graphQLURL string
+	client     *http.Client
+}
+
+// newHTTPAPI creates an HTTP GitHub API client, honoring the GITHUB_API_URL and
+// GITHUB_GRAPHQL_URL that Actions sets on Enterprise Server
+func newHTTPAPI(token string) *httpAPI {
+	restURL := strings.TrimRight(os.Getenv("GITHUB_API_URL"), "/")
+	if restURL == "" {
+		restURL = defaultAPIURL
+	}
+	graphQLURL := os.Getenv("GITHUB_GRAPHQL_URL")
+	if graphQLURL == "" {
+		graphQLURL = restURL + "/graphql"
+	}
+
+	return &httpAPI{
+		token:      token,
+		restURL:    restURL,
+		graphQLURL: graphQLURL,
+		client:     &http.Client{Timeout: httpTimeout},
+	}
+}
+
+// REST implements githubAPI
+func (a *httpAPI) REST(ctx context.Context, method, path string, fields map[string]string, paginate bool) ([]byte, error) {
+	var body any
+	if len(fields) > 0 {
+		body = fields
+	}
+	endpoint := a.restURL + "/" + strings.TrimPrefix(path, "/")
+
+	if !paginate {
+		out, _, err := a.do(ctx, method, endpoint, body, "")
+		return out, err
+	}
+
+	var pages [][]byte
+	next := withQuery(endpoint, "per_page", fmt.Sprint(pageSize))
+	for next != "" {
+		out, header, err := a.do(ctx, method, next, body, "")
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, out)
+		next = nextPageURL(header.Get("Link"))
+	}
+	return mergePages(pages)
+}
+
+// GraphQL implements githubAPI
+func (a *httpAPI) GraphQL(ctx context.Context, query string, stringVars map[string]string, intVars map[string]int) ([]byte, error) {
+	variables := make(map[string]any, len(stringVars)+len(intVars))
+	for name, value := range stringVars {
+		variables[name] = value
+	}
+	for name, value := range intVars {
+		variables[name] = value
+	}
+
+	out, _, err := a.do(ctx, "POST", a.graphQLURL, map[string]any{"query": query, "variables": variables}, "")
+	return out, err
+}
+
+// diff returns a PR's diff
+func (a *httpAPI) diff(ctx context.Context, owner, repo string, number int) ([]byte, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/pulls/%d", a.restURL, owner, repo, number)
+	out, _, err := a.do(ctx, "GET", endpoint, nil, "application/vnd.github.diff")
+	return out, err
+}
+
+// do sends one request and returns the response body and headers. Failed responses
+// are mapped to domain errors the way runGH maps gh's.
+func (a *httpAPI) do(ctx context.Context, method, endpoint string, body any, accept string) ([]byte, http.Header, error) {
+	if method == "" {
+		method = "GET"
+	}
+	if accept == "" {
+		accept = "application/vnd.github+json"
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	req.Header.Set("Accept", accept)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	start := time.Now()
+	resp, err := a.client.Do(req)
+	if err != nil {
+		logging.Debug("GitHub request failed", "method", method, "url", endpoint, "error", err)
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	out, err := io.ReadAll(resp.Body)
+	logging.Debug("GitHub request", "method", method, "url", endpoint, "status", resp.StatusCode, "elapsed", time.Since(start))
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, resp.Header, httpError(resp, out)
+	}
+	return out, resp.Header, nil
+}
+
+// httpStatusError is a GitHub API response with a failure status
+type httpStatusError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error implements the error interface
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("GitHub API returned HTTP %d: %s", e.StatusCode, e.Message)
+}
+
+// httpError turns a failed GitHub response into an error, classifying rejected
+// tokens and rate limits like runGH does
+func httpError(resp *http.Response, body []byte) error {
+	var apiErr struct {
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(body, &apiErr)
+	message := apiErr.Message
+	if message == "" {
+		message = http.StatusText(resp.StatusCode)
+	}
+	err := &httpStatusError{StatusCode: resp.StatusCode, Message: message}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return domain.ErrGitHubAuth(err)
+	case resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode == http.StatusForbidden && (resp.Header.Get("X-RateLimit-Remaining") == "0" ||
+			strings.Contains(strings.ToLower(message), "rate limit")):
+		return domain.ErrGitHubRateLimit(err)
+	}
+	return err
+}
+
+// withQuery adds a query parameter to a URL
+func withQuery(endpoint, name, value string) string {
+	sep := "?"
+	if strings.Contains(endpoint, "?") {
+		sep = "&"
+	}
+	return endpoint + sep + url.QueryEscape(name) + "=" + url.QueryEscape(value)
+}
+
+// nextPageURL returns the next page's URL from a Link header, or "" on the last page
+func nextPageURL(link string) string {
+	if matches := nextLinkPattern.FindStringSubmatch(link); matches != nil {
+		return matches[1]
+	}
+	return ""
+}
+
+// mergePages combines paginated responses into one. Array pages are joined;
+// for object pages (like check runs) the array fields are joined and the rest
+// is taken from the first page.
+func mergePages(pages [][]byte) ([]byte, error) {
+	if len(pages) == 1 {
+		return pages[0], nil
+	}
+
+	if first := bytes.TrimSpace(pages[0]); len(first) > 0 && first[0] == '[' {
+		var all []json.RawMessage
+		for _, page := range pages {
+			var items []json.RawMessage
+			if err := json.Unmarshal(page, &items); err != nil {
+				return nil, domain.ErrJSONParse("failed to parse a page of results", err)
+			}
+			all = append(all, items...)
+		}
+		return json.Marshal(all)
+	}
+
+	var merged map[string]json.RawMessage
+	if err := json.Unmarshal(pages[0], &merged); err != nil {
+		return nil, domain.ErrJSONParse("failed to parse a page of results", err)
+	}
+	for _, page := range pages[1:] {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(page, &fields); err != nil {
+			return nil, domain.ErrJSONParse("failed to parse a page of results", err)
+		}
+		for name, value := range fields {
+			var existing, more []json.RawMessage
+			if json.Unmarshal(merged[name], &existing) != nil || json.Unmarshal(value, &more) != nil {
+				continue
+			}
+			joined, err := json.Marshal(append(existing, more...))
+			if err != nil {
+				return nil, err
+			}
+			merged[name] = joined
+		}
+	}
+	return json.Marshal(merged)
+}
+
+// restPR is the JSON structure of a pull request from the REST API
+type restPR struct {
+	Number   int     `json:"number"`
+	Title    string  `json:"title"`
+	Body     string  `json:"body"`
+	State    string  `json:"state"` // open or closed
+	Draft    bool    `json:"draft"`
+	MergedAt *string `json:"merged_at"`
+	HTMLURL  string  `json:"html_url"`
+	Head     struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"base"`
+	User struct {
+		Login string `json:"login"`
+	} `json:"user"`
+}
+
+// GitHubHTTPClient implements ports.GitHubClient with GitHub's HTTP APIs and a
+// token, for containers and CI where gh isn't installed. REST and GraphQL
+// handling is shared with GitHubCLIClient; only the calls that need gh differ.
+type GitHubHTTPClient struct {
+	*GitHubCLIClient
+	http *httpAPI
+}
+
+// NewGitHubHTTPClient creates a GitHub client that authenticates with token
+func NewGitHubHTTPClient(token string) *GitHubHTTPClient {
+	api := newHTTPAPI(token)
+	client := NewGitHubCLIClient()
+	client.api = api
+	return &GitHubHTTPClient{GitHubCLIClient: client, http: api}
+}
+
+// CheckAuth verifies that the token is accepted
+func (c *GitHubHTTPClient) CheckAuth(ctx context.Context) error {
+	if _, err := c.http.REST(ctx, "GET", "user", nil, false); err != nil {
+		if isReviewError(err, domain.ErrCodeGitHubAuth) {
+			return domain.ErrGitHubAuth(fmt.Errorf("the token in GH_TOKEN or GITHUB_TOKEN was rejected"))
+		}
+		return domain.ErrGitHubAPI("failed to reach GitHub", err)
+	}
+	return nil
+}
+
+// GetPullRequest fetches PR details
+func (c *GitHubHTTPClient) GetPullRequest(ctx context.Context, owner, repo string, number int) (*ports.PullRequest, error) {
+	pr, err := c.getPR(ctx, owner, repo, number)
+	if err != nil {
+		return nil, err
+	}
+
+	state := strings.ToUpper(pr.State)
+	if pr.MergedAt != nil {
+		state = "MERGED"
+	}
+
+	return &ports.PullRequest{
+		Number:     pr.Number,
+		Title:      pr.Title,
+		Body:       pr.Body,
+		Branch:     pr.Head.Ref,
+		BaseBranch: pr.Base.Ref,
+		HeadCommit: pr.Head.SHA,
+		BaseCommit: pr.Base.SHA,
+		Author:     pr.User.Login,
+		State:      state,
+		IsDraft:    pr.Draft,
+		URL:        pr.HTMLURL,
+	}, nil
+}
+
+// GetLatestCommit returns the HEAD commit SHA of the PR
+func (c *GitHubHTTPClient) GetLatestCommit(ctx context.Context, owner, repo string, number int) (string, error) {
+	pr, err := c.getPR(ctx, owner, repo, number)
+	if err != nil {
+		return "", err
+	}
+	return pr.Head.SHA, nil
+}
+
+// GetDiff returns the diff for the PR
+func (c *GitHubHTTPClient) GetDiff(ctx context.Context, owner, repo string, number int) (string, error) {
+	out, err := c.http.diff(ctx, owner, repo, number)
+	if err != nil {
+		return "", domain.ErrGitHubAPI("failed to get diff", err)
+	}
+	return string(out), nil
+}
+
+// GetCurrentPR detects the PR number from the current branch
+func (c *GitHubHTTPClient) GetCurrentPR(ctx context.Context) (int, error) {
+	branch, err := c.GetCurrentBranch(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if branch == "" {
+		return 0, domain.ErrGitHubAPI("failed to detect current PR: HEAD is detached", nil)
+	}
+	return c.GetPRForBranch(ctx, branch)
+}
+
+// GetPRForBranch finds the open PR whose head is the given branch
+func (c *GitHubHTTPClient) GetPRForBranch(ctx context.Context, branch string) (int, error) {
+	owner, repo, err := c.GetRepoInfo(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	path := fmt.Sprintf("repos/%s/%s/pulls?state=open&head=%s", owner, repo, url.QueryEscape(owner+":"+branch))
+	out, err := c.http.REST(ctx, "GET", path, nil, true)
+	if err != nil {
+		return 0, domain.ErrGitHubAPI("failed to find PR for branch", err)
+	}
+
+	var prs []restPR
+	if err := json.Unmarshal(out, &prs); err != nil {
+		return 0, domain.ErrJSONParse("failed to parse PR list", err)
+	}
+
+	switch len(prs) {
+	case 0:
+		return 0, domain.ErrNoPRForBranch(branch)
+	case 1:
+		return prs[0].Number, nil
+	default:
+		numbers := make([]int, len(prs))
+		for i, pr := range prs {
+			numbers[i] = pr.Number
+		}
+		return 0, domain.ErrMultiplePRsForBranch(branch, numbers)
+	}
+}
+
+// getPR fetches a pull request from the REST API
+func (c *GitHubHTTPClient) getPR(ctx context.Context, owner, repo string, number int) (*restPR, error) {
+	return fetchPR(ctx, c.http, owner, repo, number)
+}
+
+// fetchPR fetches a pull request from the REST API
+func fetchPR(ctx context.Context, api *httpAPI, owner, repo string, number int) (*restPR, error) {
+	out, err := api.REST(ctx, "GET", fmt.Sprintf("repos/%s/%s/pulls/%d", owner, repo, number), nil, false)
+	if err != nil {
+		var statusErr *httpStatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+			return nil, domain.ErrPRNotFound(number)
+		}
+		return nil, domain.ErrGitHubAPI("failed to fetch PR", err)
+	}
+
+	var pr restPR
+	if err := json.Unmarshal(out, &pr); err != nil {
+		return nil, domain.ErrJSONParse("failed to parse PR response", err)
+	}
+	return &pr, nil
+}
+
+// GitHubHTTPCIAdapter implements ports.CIProvider with GitHub's HTTP APIs and a
+// token, for environments without gh. Check and status handling is shared with
+// GitHubCIAdapter; only listing and rerunning workflow runs differ.
+type GitHubHTTPCIAdapter struct {
+	*GitHubCIAdapter
+	http *httpAPI
+}
+
+// NewGitHubHTTPCIAdapter creates a CI adapter that authenticates with token
+func NewGitHubHTTPCIAdapter(token string) *GitHubHTTPCIAdapter {
+	api := newHTTPAPI(token)
+	adapter := NewGitHubCIAdapter()
+	adapter.api = api
+	return &GitHubHTTPCIAdapter{GitHubCIAdapter: adapter, http: api}
+}
+
+// GetWorkflowRuns lists the check runs and commit statuses on a PR's head commit
+func (a *GitHubHTTPCIAdapter) GetWorkflowRuns(ctx context.Context, owner, repo string, prNumber int) ([]ports.WorkflowRun, error) {
+	pr, err := fetchPR(ctx, a.http, owner, repo, prNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := a.http.REST(ctx, "GET", fmt.Sprintf("repos/%s/%s/commits/%s/check-runs", owner, repo, pr.Head.SHA), nil, true)
+	if err != nil {
+		return nil, domain.ErrGitHubAPI("failed to fetch workflow runs", err)
+	}
+	var checkRuns ghCheckRuns
+	if err := json.Unmarshal(out, &checkRuns); err != nil {
+		return nil, domain.ErrJSONParse("failed to parse workflow runs", err)
+	}
+
+	var runs []ports.WorkflowRun
+	for _, run := range checkRuns.CheckRuns {
+		runs = append(runs, ports.WorkflowRun{
+			ID:         domain.ParseWorkflowRunID(run.HTMLURL),
+			Name:       run.Name,
+			Status:     run.Status,
+			Conclusion: run.Conclusion,
+			LogURL:     run.HTMLURL,
+		})
+	}
+
+	// Commit statuses from other integrations, as gh pr checks lists them too
+	out, err = a.http.REST(ctx, "GET", fmt.Sprintf("repos/%s/%s/commits/%s/status", owner, repo, pr.Head.SHA), nil, false)
+	if err != nil {
+		return runs, nil
+	}
+	var commitStatus ghCommitStatus
+	if json.Unmarshal(out, &commitStatus) != nil {
+		return runs, nil
+	}
+	for _, s := range commitStatus.Statuses {
+		status, conclusion := "completed", s.State
+		switch s.State {
+		case "pending":
+			status, conclusion = "in_progress", ""
+		case "error":
+			conclusion = "failure"
+		}
+		runs = append(runs, ports.WorkflowRun{
+			Name:       s.Context,
+			Status:     status,
+			Conclusion: conclusion,
+			LogURL:     s.TargetURL,
+		})
+	}
+
+	return runs, nil
+}
+
+// RerunFailedJobs reruns the failed jobs of a GitHub Actions workflow run
+func (a *GitHubHTTPCIAdapter) RerunFailedJobs(ctx context.Context, owner, repo string, runID int64) error {
+	path := fmt.Sprintf("repos/%s/%s/actions/runs/%d/rerun-failed-jobs", owner, repo, runID)
+	if _, err := a.http.REST(ctx, "POST", path, nil, false); err != nil {
+		return domain.ErrGitHubAPI(fmt.Sprintf("failed to rerun workflow run %d", runID), err)
+	}
+	return nil
+}
+
+// isReviewError returns true if err is a ReviewError with the given code
+func isReviewError(err error, code domain.ErrorCode) bool {
+	var reviewErr *domain.ReviewError
+	return errors.As(err, &reviewErr) && reviewErr.Code == code
+}
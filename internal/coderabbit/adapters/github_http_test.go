@@ -0,0 +1,143 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
+)
+
+func TestHTTPGraphQLSendsVariables(t *testing.T) {
+	var request struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		w.Write([]byte(`{"data":{}}`))
+	}))
+	defer server.Close()
+	t.Setenv("GITHUB_GRAPHQL_URL", server.URL)
+
+	query := "query($name: String!, $number: Int!) { x }"
+	_, err := newHTTPAPI("token").GraphQL(context.Background(), query,
+		map[string]string{"name": quotedRepo}, map[string]int{"number": 7})
+	if err != nil {
+		t.Fatalf("GraphQL() error = %v", err)
+	}
+
+	if request.Query != query {
+		t.Errorf("query = %q, want it sent unchanged", request.Query)
+	}
+	if request.Variables["name"] != quotedRepo || request.Variables["number"] != float64(7) {
+		t.Errorf("variables = %v, want name %q and number 7", request.Variables, quotedRepo)
+	}
+}
+
+func TestMergePages(t *testing.T) {
+	tests := []struct {
+		name  string
+		pages []string
+		want  string
+	}{
+		{
+			name:  "single page unchanged",
+			pages: []string{`{"total_count":1,"check_runs":[1]}`},
+			want:  `{"total_count":1,"check_runs":[1]}`,
+		},
+		{
+			name:  "array pages joined",
+			pages: []string{`[{"id":1},{"id":2}]`, ` [{"id":3}]`, `[]`},
+			want:  `[{"id":1},{"id":2},{"id":3}]`,
+		},
+		{
+			name: "object pages join arrays and keep the first page's other fields",
+			pages: []string{
+				`{"total_count":3,"check_runs":[{"id":1},{"id":2}]}`,
+				`{"total_count":3,"check_runs":[{"id":3}]}`,
+			},
+			want: `{"check_runs":[{"id":1},{"id":2},{"id":3}],"total_count":3}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages := make([][]byte, len(tt.pages))
+			for i, page := range tt.pages {
+				pages[i] = []byte(page)
+			}
+			got, err := mergePages(pages)
+			if err != nil {
+				t.Fatalf("mergePages() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("mergePages() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := mergePages([][]byte{[]byte(`[1]`), []byte(`{"not":"a list"}`)}); err == nil {
+		t.Error("mergePages() joined an object onto array pages")
+	}
+}
+
+func TestNextPageURL(t *testing.T) {
+	link := `<https://api.github.com/repositories/1/pulls/2/comments?per_page=100&page=3>; rel="next", ` +
+		`<https://api.github.com/repositories/1/pulls/2/comments?per_page=100&page=5>; rel="last"`
+	if got, want := nextPageURL(link), "https://api.github.com/repositories/1/pulls/2/comments?per_page=100&page=3"; got != want {
+		t.Errorf("nextPageURL() = %q, want %q", got, want)
+	}
+	if got := nextPageURL(`<https://api.github.com/x?page=1>; rel="first", <https://api.github.com/x?page=4>; rel="prev"`); got != "" {
+		t.Errorf("nextPageURL() on the last page = %q, want none", got)
+	}
+}
+
+func TestHTTPRESTPaginates(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("per_page"); got != "100" {
+			t.Errorf("per_page = %q, want 100", got)
+		}
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/repos/o/r/issues/1/comments?per_page=100&page=2>; rel="next"`, server.URL))
+			w.Write([]byte(`[{"id":1},{"id":2}]`))
+		case "2":
+			w.Write([]byte(`[{"id":3}]`))
+		default:
+			t.Errorf("unexpected page %q", r.URL.Query().Get("page"))
+		}
+	}))
+	defer server.Close()
+	t.Setenv("GITHUB_API_URL", server.URL)
+
+	out, err := newHTTPAPI("token").REST(context.Background(), "GET", "repos/o/r/issues/1/comments", nil, true)
+	if err != nil {
+		t.Fatalf("REST() error = %v", err)
+	}
+	if string(out) != `[{"id":1},{"id":2},{"id":3}]` {
+		t.Errorf("REST() = %s, want both pages", out)
+	}
+}
+
+func TestFetchPRNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"Not Found"}`))
+	}))
+	defer server.Close()
+	t.Setenv("GITHUB_API_URL", server.URL)
+
+	_, err := fetchPR(context.Background(), newHTTPAPI("token"), "o", "r", 7)
+	var reviewErr *domain.ReviewError
+	if !errors.As(err, &reviewErr) || reviewErr.Code != domain.ErrCodePRNotFound {
+		t.Errorf("fetchPR() error = %v, want PR not found", err)
+	}
+}
@@ -0,0 +1,157 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
+	"github.com/DylanSharp/dtools/internal/logging"
+)
+
+// suggestionContextLines is the number of unchanged lines around a suggestion in its patch
+const suggestionContextLines = 3
+
+// extractSuggestion returns the replacement lines of a comment's committable
+// ```suggestion block. Comments with several suggestions return "" since they
+// can't be applied as one change.
+func extractSuggestion(body string) string {
+	var found []string
+	for _, block := range fencedBlocks(body) {
+		if strings.EqualFold(block.info, "suggestion") {
+			found = append(found, block.content)
+		}
+	}
+	if len(found) != 1 {
+		return ""
+	}
+	return found[0]
+}
+
+// GitSuggestionApplier implements ports.SuggestionApplier with git apply in the
+// checkout of the current directory
+type GitSuggestionApplier struct{}
+
+// NewGitSuggestionApplier creates a suggestion applier for the current checkout
+func NewGitSuggestionApplier() *GitSuggestionApplier {
+	return &GitSuggestionApplier{}
+}
+
+// ApplySuggestion applies a comment's committable suggestion to the local working
+// tree with git apply. It refuses if the commented lines no longer match the diff
+// hunk the comment was made on.
+func (a *GitSuggestionApplier) ApplySuggestion(ctx context.Context, comment domain.Comment) error {
+	out, err := logging.Output(exec.CommandContext(ctx, "git", "rev-parse", "--show-toplevel"))
+	if err != nil {
+		return domain.ErrSuggestion("failed to find repository root", err)
+	}
+	root := strings.TrimSpace(string(out))
+
+	content, err := os.ReadFile(filepath.Join(root, comment.FilePath))
+	if err != nil {
+		return domain.ErrSuggestion(fmt.Sprintf("failed to read %s", comment.FilePath), err)
+	}
+
+	endLine := comment.EndLine
+	if endLine < comment.LineNumber {
+		endLine = comment.LineNumber
+	}
+
+	patch, err := buildSuggestionPatch(comment.FilePath, string(content), comment.LineNumber, endLine, comment.SuggestedChange, comment.DiffHunk)
+	if err != nil {
+		return domain.ErrSuggestion(fmt.Sprintf("can't apply suggestion to %s", comment.Location()), err)
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "apply", "-")
+	cmd.Dir = root
+	cmd.Stdin = strings.NewReader(patch)
+	if _, err := logging.Output(cmd); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			err = fmt.Errorf("%s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return domain.ErrSuggestion(fmt.Sprintf("git apply failed for %s", comment.Location()), err)
+	}
+
+	return nil
+}
+
+// buildSuggestionPatch builds a unified diff replacing lines start..end of a file
+// with the suggested lines
+func buildSuggestionPatch(path, content string, start, end int, replacement, diffHunk string) (string, error) {
+	if strings.Contains(content, "\r\n") {
+		return "", fmt.Errorf("file has CRLF line endings")
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		return "", fmt.Errorf("file doesn't end with a newline")
+	}
+
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if start < 1 || end < start || end > len(lines) {
+		return "", fmt.Errorf("lines %d-%d are out of range", start, end)
+	}
+
+	// The diff hunk ends on the commented lines; if they differ the file has moved on
+	hunkLines := diffHunkNewLines(diffHunk)
+	target := lines[start-1 : end]
+	if len(hunkLines) < len(target) {
+		return "", fmt.Errorf("no diff hunk to verify the commented lines against")
+	}
+	for i, line := range hunkLines[len(hunkLines)-len(target):] {
+		if line != target[i] {
+			return "", fmt.Errorf("file has changed since the comment was made")
+		}
+	}
+
+	before := start - 1 - suggestionContextLines
+	if before < 0 {
+		before = 0
+	}
+	after := end + suggestionContextLines
+	if after > len(lines) {
+		after = len(lines)
+	}
+
+	var added []string
+	if replacement != "" {
+		added = strings.Split(replacement, "\n")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "diff --git a/%s b/%s\n--- a/%s\n+++ b/%s\n", path, path, path, path)
+	oldCount := after - before
+	newCount := oldCount - len(target) + len(added)
+	fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", before+1, oldCount, before+1, newCount)
+	for _, line := range lines[before : start-1] {
+		b.WriteString(" " + line + "\n")
+	}
+	for _, line := range target {
+		b.WriteString("-" + line + "\n")
+	}
+	for _, line := range added {
+		b.WriteString("+" + line + "\n")
+	}
+	for _, line := range lines[end:after] {
+		b.WriteString(" " + line + "\n")
+	}
+
+	return b.String(), nil
+}
+
+// diffHunkNewLines returns the new-side lines of a diff hunk, without their prefixes
+func diffHunkNewLines(hunk string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(hunk, "\n"), "\n") {
+		if line == "" {
+			lines = append(lines, "")
+			continue
+		}
+		switch line[0] {
+		case ' ', '+':
+			lines = append(lines, line[1:])
+		}
+	}
+	return lines
+}
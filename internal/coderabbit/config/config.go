@@ -0,0 +1,52 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/DylanSharp/dtools/internal/statedir"
+)
+
+// configFile is the optional review configuration shared by all repositories
+var configFile = filepath.Join(statedir.ConfigDir(), "review.json")
+
+// Config holds settings for dtools review that can't be passed per run
+type Config struct {
+	// ReviewerBots are the bot logins whose comments and checks are reviewed
+	ReviewerBots []string `json:"reviewer_bots,omitempty"`
+
+	// PromptInstructions replaces the tooling instructions detected from the
+	// project's language (formatters, linters, test commands) in the review prompt
+	PromptInstructions string `json:"prompt_instructions,omitempty"`
+
+	// PromptTemplate is the path of a text/template file that renders the
+	// review prompt instead of the built-in one
+	PromptTemplate string `json:"prompt_template,omitempty"`
+}
+
+// Path returns the location of the config file
+func Path() string {
+	return configFile
+}
+
+// Load reads the config file, returning an empty config if it doesn't exist
+func Load() (*Config, error) {
+	statedir.MigrateLegacyConfig("review.json")
+
+	data, err := os.ReadFile(configFile)
+	if os.IsNotExist(err) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", configFile, err)
+	}
+
+	return &cfg, nil
+}
@@ -2,26 +2,30 @@ package domain
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 	"time"
 )
 
 // Comment represents a CodeRabbit review comment
 type Comment struct {
-	ID           int
-	FilePath     string
-	LineNumber   int
-	EndLine      int // For multi-line comments
-	Body         string
-	AIPrompt     string // Extracted "Prompt for AI Agents" section
-	ThreadID     string
-	Author       string
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
-	URL          string
-	IsResolved   bool
-	IsNit        bool
-	IsOutdated   bool
-	IsOutsideDiff bool
+	ID              int
+	FilePath        string
+	LineNumber      int
+	EndLine         int // For multi-line comments
+	Body            string
+	AIPrompt        string // Extracted "Prompt for AI Agents" section
+	SuggestedChange string // Replacement lines from a committable ```suggestion block
+	DiffHunk        string // Diff context the comment is anchored to
+	ThreadID        string
+	Author          string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	URL             string
+	IsResolved      bool
+	IsNit           bool
+	IsOutdated      bool
+	IsOutsideDiff   bool
 }
 
 // HasAIPrompt returns true if the comment has an extracted AI prompt
@@ -29,6 +33,11 @@ func (c *Comment) HasAIPrompt() bool {
 	return c.AIPrompt != ""
 }
 
+// HasSuggestion returns true if the comment carries a committable suggestion
+func (c *Comment) HasSuggestion() bool {
+	return c.SuggestedChange != ""
+}
+
 // EffectiveBody returns AIPrompt if available, otherwise the full body
 func (c *Comment) EffectiveBody() string {
 	if c.AIPrompt != "" {
@@ -45,9 +54,23 @@ func (c *Comment) Location() string {
 	if c.LineNumber == 0 {
 		return c.FilePath
 	}
+	if c.EndLine > c.LineNumber {
+		return fmt.Sprintf("%s:%d-%d", c.FilePath, c.LineNumber, c.EndLine)
+	}
 	return fmt.Sprintf("%s:%d", c.FilePath, c.LineNumber)
 }
 
+// LineRange returns the comment's lines as "L10" or "L10-15", or "" if it has no line
+func (c *Comment) LineRange() string {
+	if c.LineNumber == 0 {
+		return ""
+	}
+	if c.EndLine > c.LineNumber {
+		return fmt.Sprintf("L%d-%d", c.LineNumber, c.EndLine)
+	}
+	return fmt.Sprintf("L%d", c.LineNumber)
+}
+
 // CITestFailure represents a failed CI test or check
 type CITestFailure struct {
 	CheckName    string
@@ -59,6 +82,29 @@ type CITestFailure struct {
 	Annotations  []CIAnnotation
 }
 
+// workflowRunPattern extracts the run ID from a GitHub Actions check URL
+var workflowRunPattern = regexp.MustCompile(`/actions/runs/(\d+)`)
+
+// ParseWorkflowRunID returns the GitHub Actions run ID in a check's URL, or 0 if
+// the check isn't an Actions workflow run
+func ParseWorkflowRunID(url string) int64 {
+	matches := workflowRunPattern.FindStringSubmatch(url)
+	if matches == nil {
+		return 0
+	}
+	id, err := strconv.ParseInt(matches[1], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// WorkflowRunID returns the GitHub Actions run the failed check belongs to, or 0
+// if it isn't an Actions check and can't be rerun
+func (f CITestFailure) WorkflowRunID() int64 {
+	return ParseWorkflowRunID(f.LogURL)
+}
+
 // CIStatus represents the overall status of CI checks
 type CIStatus struct {
 	Failures            []CITestFailure
@@ -102,7 +148,9 @@ type ThoughtChunk struct {
 type ThoughtType string
 
 const (
-	ThoughtTypeThinking    ThoughtType = "thinking"
+	ThoughtTypeThinking    ThoughtType = "thinking" // Claude's extended thinking
+	ThoughtTypeSpeech      ThoughtType = "speech"   // Claude's reply, when no more specific type fits
+	ThoughtTypeTool        ThoughtType = "tool"     // A tool call, such as reading a file
 	ThoughtTypeSuggestion  ThoughtType = "suggestion"
 	ThoughtTypeAnalysis    ThoughtType = "analysis"
 	ThoughtTypeCode        ThoughtType = "code"
@@ -1,6 +1,9 @@
 package domain
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // ErrorCode represents domain-specific error codes
 type ErrorCode string
@@ -17,6 +20,10 @@ const (
 	ErrCodeStateCorrupt    ErrorCode = "state_corrupt"
 	ErrCodeNoComments      ErrorCode = "no_comments"
 	ErrCodeInvalidConfig   ErrorCode = "invalid_config"
+	ErrCodeSuggestion      ErrorCode = "suggestion_not_applied"
+	ErrCodeResolveFailed   ErrorCode = "resolve_failed"
+	ErrCodePRClosed        ErrorCode = "pr_closed"
+	ErrCodeGraphQL         ErrorCode = "github_graphql_error"
 )
 
 // ReviewError represents a domain-specific error
@@ -75,11 +82,36 @@ func ErrGitHubAuth(err error) *ReviewError {
 	return NewError(ErrCodeGitHubAuth, "GitHub authentication failed", err)
 }
 
+// ErrGitHubGraphQL creates an error for a GraphQL response that reported errors
+// or lacked the data asked for, instead of treating it as an empty result
+func ErrGitHubGraphQL(message string, err error) *ReviewError {
+	return NewError(ErrCodeGraphQL, message, err)
+}
+
 // ErrPRNotFound creates a PR not found error
 func ErrPRNotFound(prNumber int) *ReviewError {
 	return NewError(ErrCodePRNotFound, fmt.Sprintf("PR #%d not found", prNumber), nil)
 }
 
+// ErrNoPRForBranch creates an error for a branch without an open PR
+func ErrNoPRForBranch(branch string) *ReviewError {
+	return NewError(ErrCodePRNotFound, fmt.Sprintf("no open PR found for branch '%s'", branch), nil)
+}
+
+// ErrMultiplePRsForBranch creates an error for a branch with several open PRs
+func ErrMultiplePRsForBranch(branch string, numbers []int) *ReviewError {
+	refs := make([]string, len(numbers))
+	for i, n := range numbers {
+		refs[i] = fmt.Sprintf("#%d", n)
+	}
+	return NewError(ErrCodeInvalidConfig, fmt.Sprintf("multiple open PRs found for branch '%s' (%s), specify one with --pr", branch, strings.Join(refs, ", ")), nil)
+}
+
+// ErrPRClosed creates an error for a PR that has been merged or closed
+func ErrPRClosed(prNumber int, state string) *ReviewError {
+	return NewError(ErrCodePRClosed, fmt.Sprintf("PR #%d is %s, use --allow-closed to review it anyway", prNumber, strings.ToLower(state)), nil)
+}
+
 // ErrClaudeTimeout creates a Claude timeout error
 func ErrClaudeTimeout(err error) *ReviewError {
 	return NewError(ErrCodeClaudeTimeout, "Claude CLI timed out", err)
@@ -104,3 +136,13 @@ func ErrJSONParse(message string, err error) *ReviewError {
 func ErrNoComments() *ReviewError {
 	return NewError(ErrCodeNoComments, "No CodeRabbit comments found", nil)
 }
+
+// ErrSuggestion creates an error for a suggestion that couldn't be applied
+func ErrSuggestion(message string, err error) *ReviewError {
+	return NewError(ErrCodeSuggestion, message, err)
+}
+
+// ErrResolveFailed creates an error for a comment thread that couldn't be resolved on GitHub
+func ErrResolveFailed(commentID int, err error) *ReviewError {
+	return NewError(ErrCodeResolveFailed, fmt.Sprintf("failed to resolve comment %d", commentID), err)
+}
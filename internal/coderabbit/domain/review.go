@@ -2,6 +2,8 @@ package domain
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -28,6 +30,8 @@ type Review struct {
 	BaseCommit  string
 	Title       string
 	Author      string
+	PRState     string // OPEN, CLOSED or MERGED, as reported by GitHub
+	IsDraft     bool
 	Status      ReviewStatus
 	StartedAt   time.Time
 	CompletedAt *time.Time
@@ -37,6 +41,17 @@ type Review struct {
 	CIFailures []CITestFailure
 	Thoughts   []ThoughtChunk
 
+	// Comments whose committable suggestions were applied locally; Claude is only asked to check them
+	AppliedSuggestions []Comment
+
+	// Comment threads resolved on GitHub during this run
+	ResolvedCount int
+
+	// Optional background context (only populated when requested)
+	DiffContext string // Diff hunks for commented files
+	Summary     string // CodeRabbit walkthrough/summary
+	Description string // PR description, explaining what the change is for
+
 	// CI status tracking
 	CIPendingCount      int      // Number of CI checks still running
 	CIPendingNames      []string // Names of pending CI checks
@@ -53,6 +68,7 @@ type Review struct {
 	TotalFoundCount    int  // Total comments found from GitHub
 	AlreadyAddressed   int  // Comments skipped because already processed
 	NewCommentsCount   int  // New comments to address this run
+	DeferredCount      int  // New comments left for later batches by a comment cap or the prompt budget
 
 	// Satisfaction tracking
 	Satisfied       bool
@@ -84,6 +100,46 @@ func (r *Review) TotalComments() int {
 	return len(r.Comments)
 }
 
+// CommentedFiles returns the distinct files the review's comments are on, sorted
+func (r *Review) CommentedFiles() []string {
+	seen := make(map[string]bool)
+	var files []string
+	for _, comment := range r.Comments {
+		if comment.FilePath != "" && !seen[comment.FilePath] {
+			seen[comment.FilePath] = true
+			files = append(files, comment.FilePath)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// ShortCommit returns the abbreviated SHA of the head commit the review ran against
+func (r *Review) ShortCommit() string {
+	if len(r.HeadCommit) > 7 {
+		return r.HeadCommit[:7]
+	}
+	return r.HeadCommit
+}
+
+// IsClosed returns true if the PR has been merged or closed
+func (r *Review) IsClosed() bool {
+	return strings.EqualFold(r.PRState, "MERGED") || strings.EqualFold(r.PRState, "CLOSED")
+}
+
+// PRStateLabel returns a short label for a PR that isn't simply open ("merged",
+// "closed" or "draft"), or "" for an open PR
+func (r *Review) PRStateLabel() string {
+	switch {
+	case r.IsClosed():
+		return strings.ToLower(r.PRState)
+	case r.IsDraft:
+		return "draft"
+	default:
+		return ""
+	}
+}
+
 // AddThought appends a new thought chunk
 func (r *Review) AddThought(thought ThoughtChunk) {
 	r.Thoughts = append(r.Thoughts, thought)
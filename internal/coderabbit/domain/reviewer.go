@@ -0,0 +1,27 @@
+package domain
+
+import "strings"
+
+// DefaultReviewerBot is the login fragment of the CodeRabbit bot
+const DefaultReviewerBot = "coderabbit"
+
+// ReviewerBots lists login fragments identifying the reviewer bot, matched
+// case-insensitively against comment authors and CI check names
+type ReviewerBots []string
+
+// DefaultReviewerBots returns the reviewer bots matched when none are configured
+func DefaultReviewerBots() ReviewerBots {
+	return ReviewerBots{DefaultReviewerBot}
+}
+
+// Matches reports whether name contains any of the reviewer bot logins
+func (b ReviewerBots) Matches(name string) bool {
+	name = strings.ToLower(name)
+	for _, bot := range b {
+		bot = strings.ToLower(strings.TrimSpace(bot))
+		if bot != "" && strings.Contains(name, bot) {
+			return true
+		}
+	}
+	return false
+}
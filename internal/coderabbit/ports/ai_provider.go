@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"encoding/json"
 )
 
 // AIProvider abstracts AI-powered review generation
@@ -42,9 +43,11 @@ type AssistantMessage struct {
 
 // ContentBlock represents a content block in the message
 type ContentBlock struct {
-	Type     string `json:"type"` // "text" or "thinking"
-	Text     string `json:"text,omitempty"`
-	Thinking string `json:"thinking,omitempty"`
+	Type     string          `json:"type"` // "text", "thinking" or "tool_use"
+	Text     string          `json:"text,omitempty"`
+	Thinking string          `json:"thinking,omitempty"`
+	Name     string          `json:"name,omitempty"`  // Tool name, for tool_use
+	Input    json.RawMessage `json:"input,omitempty"` // Tool input, for tool_use
 }
 
 // TokenUsage represents token usage statistics
@@ -59,6 +62,9 @@ type StreamError struct {
 	Message string `json:"message"`
 }
 
+// StreamErrorTimeout is the StreamError type emitted when the AI CLI exceeds its deadline
+const StreamErrorTimeout = "timeout"
+
 // GetText extracts the text content from the chunk
 func (c StreamChunk) GetText() string {
 	// For assistant messages, extract text from content blocks
@@ -83,6 +89,18 @@ func (c StreamChunk) GetText() string {
 	return ""
 }
 
+// ContentBlocks returns the chunk's content blocks in order. A result chunk's
+// result is returned as a text block.
+func (c StreamChunk) ContentBlocks() []ContentBlock {
+	if c.Type == "assistant" && c.Message != nil {
+		return c.Message.Content
+	}
+	if c.Type == "result" && c.Result != "" {
+		return []ContentBlock{{Type: "text", Text: c.Result}}
+	}
+	return nil
+}
+
 // IsComplete returns true if this is the final result chunk
 func (c StreamChunk) IsComplete() bool {
 	return c.Type == "result"
@@ -16,12 +16,16 @@ type CIProvider interface {
 
 	// GetWorkflowRuns retrieves workflow runs for a PR
 	GetWorkflowRuns(ctx context.Context, owner, repo string, prNumber int) ([]WorkflowRun, error)
+
+	// RerunFailedJobs reruns the failed jobs of a GitHub Actions workflow run
+	RerunFailedJobs(ctx context.Context, owner, repo string, runID int64) error
 }
 
 // WorkflowRun represents a CI workflow run
 type WorkflowRun struct {
-	ID         int64
+	ID         int64 // Actions run ID; 0 for checks from other CI apps
 	Name       string
+	Workflow   string
 	Status     string // queued, in_progress, completed
 	Conclusion string // success, failure, neutral, cancelled, skipped, timed_out, action_required
 	LogURL     string
@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
 )
@@ -14,15 +15,24 @@ type GitHubClient interface {
 	// ListCodeRabbitComments fetches all CodeRabbit review comments for a PR
 	ListCodeRabbitComments(ctx context.Context, owner, repo string, number int) ([]domain.Comment, error)
 
+	// GetCodeRabbitSummary returns CodeRabbit's walkthrough/summary for a PR, if any
+	GetCodeRabbitSummary(ctx context.Context, owner, repo string, number int) (string, error)
+
 	// GetLatestCommit returns the HEAD commit SHA of the PR
 	GetLatestCommit(ctx context.Context, owner, repo string, number int) (string, error)
 
+	// GetCommitTime returns when a commit was committed
+	GetCommitTime(ctx context.Context, owner, repo, sha string) (time.Time, error)
+
 	// GetDiff returns the diff for the PR
 	GetDiff(ctx context.Context, owner, repo string, number int) (string, error)
 
 	// GetCurrentPR detects the PR number from the current branch
 	GetCurrentPR(ctx context.Context) (int, error)
 
+	// GetPRForBranch finds the open PR whose head is the given branch
+	GetPRForBranch(ctx context.Context, branch string) (int, error)
+
 	// GetRepoInfo returns the owner and repo from the current git remote
 	GetRepoInfo(ctx context.Context) (owner, repo string, err error)
 
@@ -34,6 +44,11 @@ type GitHubClient interface {
 
 	// ResolveComment marks a review comment thread as resolved
 	ResolveComment(ctx context.Context, owner, repo string, prNumber, commentID int) error
+
+	// ResolveComments marks the threads of several review comments as resolved,
+	// skipping threads that already are. It returns the comments whose threads it
+	// resolved and the error for each comment that couldn't be resolved.
+	ResolveComments(ctx context.Context, owner, repo string, prNumber int, commentIDs []int) (resolved []int, failed map[int]error)
 }
 
 // PullRequest represents GitHub PR metadata
@@ -46,6 +61,7 @@ type PullRequest struct {
 	HeadCommit string
 	BaseCommit string
 	Author     string
-	State      string
+	State      string // OPEN, CLOSED or MERGED
+	IsDraft    bool
 	URL        string
 }
@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
+)
+
+// SuggestionApplier abstracts applying CodeRabbit's committable suggestions locally
+type SuggestionApplier interface {
+	// ApplySuggestion applies a comment's committable suggestion to the local working tree
+	ApplySuggestion(ctx context.Context, comment domain.Comment) error
+}
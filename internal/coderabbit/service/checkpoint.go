@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/DylanSharp/dtools/internal/coderabbit/state"
+	"github.com/DylanSharp/dtools/internal/logging"
+)
+
+// restoreCheckpoint picks up where a previous watch session on the PR left off:
+// what it had already seen, and any cooldown still running. It returns true if
+// there was a checkpoint to restore. With ResetState, it clears the PR's state instead.
+func (w *Watcher) restoreCheckpoint(ctx context.Context, prNumber int) bool {
+	owner, repo, err := w.service.GetRepoInfo(ctx)
+	if err != nil {
+		logging.Warn("Failed to get repo info for the watch checkpoint", "error", err)
+		return false
+	}
+
+	w.mu.Lock()
+	w.stateKey = state.GetStateKey(owner, repo, prNumber)
+	key := w.stateKey
+	w.mu.Unlock()
+
+	if w.opts.ResetState {
+		if err := state.Reset(key); err != nil {
+			logging.Warn("Failed to reset state", "pr", key, "error", err)
+		}
+		return false
+	}
+
+	checkpoint, err := state.LoadWatch(key)
+	if err != nil {
+		logging.Warn("Failed to load the watch checkpoint", "pr", key, "error", err)
+		return false
+	}
+	if checkpoint == nil {
+		return false
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastCommitSHA = checkpoint.LastCommitSHA
+	w.lastCommentCount = checkpoint.LastCommentCount
+	w.lastCIFailureCount = checkpoint.LastCIFailureCount
+	w.processedCIOnce = checkpoint.ProcessedCIOnce
+	if time.Now().Before(checkpoint.CooldownUntil) {
+		w.state = WatchStateCooldown
+		w.cooldownUntil = checkpoint.CooldownUntil
+	}
+	return true
+}
+
+// saveCheckpoint saves what the watcher has seen, so a restart doesn't rerun Claude
+// on it. Failures are logged; watching carries on without a checkpoint.
+func (w *Watcher) saveCheckpoint() {
+	w.mu.Lock()
+	key := w.stateKey
+	checkpoint := state.WatchCheckpoint{
+		LastCommitSHA:      w.lastCommitSHA,
+		LastCommentCount:   w.lastCommentCount,
+		LastCIFailureCount: w.lastCIFailureCount,
+		ProcessedCIOnce:    w.processedCIOnce,
+	}
+	if w.state == WatchStateCooldown {
+		checkpoint.CooldownUntil = w.cooldownUntil
+	}
+	w.mu.Unlock()
+
+	if key == "" {
+		return
+	}
+	if err := state.SaveWatch(key, checkpoint); err != nil {
+		logging.Warn("Failed to save the watch checkpoint", "pr", key, "error", err)
+	}
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
+	"github.com/DylanSharp/dtools/internal/coderabbit/ports"
+)
+
+// rerunGrace is how long a rerun check is treated as pending while GitHub picks
+// up the new attempt; if it's still failing after that, the failure is real
+const rerunGrace = time.Minute
+
+// GetWorkflowRuns lists the CI checks on a PR with their workflow runs
+func (s *ReviewService) GetWorkflowRuns(ctx context.Context, prNumber int) ([]ports.WorkflowRun, error) {
+	owner, repo, err := s.github.GetRepoInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repo info: %w", err)
+	}
+	return s.ci.GetWorkflowRuns(ctx, owner, repo, prNumber)
+}
+
+// RerunFailedWorkflows reruns the failed jobs of every failed GitHub Actions run
+// on a PR and returns the runs that were rerun. Checks from other CI apps can't
+// be rerun and are left alone.
+func (s *ReviewService) RerunFailedWorkflows(ctx context.Context, prNumber int) ([]ports.WorkflowRun, error) {
+	owner, repo, err := s.github.GetRepoInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repo info: %w", err)
+	}
+
+	runs, err := s.ci.GetWorkflowRuns(ctx, owner, repo, prNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	// Several failed jobs can belong to one run; rerun each run once
+	seen := make(map[int64]bool)
+	var rerun []ports.WorkflowRun
+	var errs []error
+	for _, run := range runs {
+		if !run.IsFailed() || run.ID == 0 || seen[run.ID] {
+			continue
+		}
+		seen[run.ID] = true
+		if err := s.ci.RerunFailedJobs(ctx, owner, repo, run.ID); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		rerun = append(rerun, run)
+	}
+
+	return rerun, errors.Join(errs...)
+}
+
+// RerunWorkflowRun reruns the failed jobs of one workflow run in a repository given as owner/repo
+func (s *ReviewService) RerunWorkflowRun(ctx context.Context, repository string, runID int64) error {
+	owner, repo := s.parseRepository(repository)
+	return s.ci.RerunFailedJobs(ctx, owner, repo, runID)
+}
+
+// rerunFlakyFailures gives each failed Actions check one rerun before it's treated
+// as a real failure. Checks rerun within rerunGrace are moved from the review's
+// failures to its pending checks. It returns how many runs were rerun just now.
+func (w *Watcher) rerunFlakyFailures(ctx context.Context, review *domain.Review) int {
+	rerun := 0
+	for _, failure := range review.CIFailures {
+		runID := failure.WorkflowRunID()
+		if runID == 0 {
+			continue
+		}
+		if _, done := w.rerunAt[runID]; done {
+			continue
+		}
+		if err := w.service.RerunWorkflowRun(ctx, review.Repository, runID); err != nil {
+			// Don't retry a rerun GitHub refused; report the failure as is
+			w.rerunAt[runID] = time.Time{}
+			continue
+		}
+		w.rerunAt[runID] = time.Now()
+		rerun++
+	}
+
+	holdRerunFailures(review, w.pendingReruns())
+	return rerun
+}
+
+// pendingReruns returns the workflow runs rerun within rerunGrace, whose
+// failures aren't real yet
+func (w *Watcher) pendingReruns() []int64 {
+	var ids []int64
+	for runID, rerunAt := range w.rerunAt {
+		if !rerunAt.IsZero() && time.Since(rerunAt) < rerunGrace {
+			ids = append(ids, runID)
+		}
+	}
+	return ids
+}
+
+// holdRerunFailures moves the failures of workflow runs that are being rerun
+// from the review's failures to its pending checks
+func holdRerunFailures(review *domain.Review, runIDs []int64) {
+	if len(runIDs) == 0 {
+		return
+	}
+	pending := make(map[int64]bool, len(runIDs))
+	for _, id := range runIDs {
+		pending[id] = true
+	}
+
+	var failures []domain.CITestFailure
+	for _, failure := range review.CIFailures {
+		if !pending[failure.WorkflowRunID()] {
+			failures = append(failures, failure)
+			continue
+		}
+		review.CIPendingCount++
+		review.CIPendingNames = append(review.CIPendingNames, failure.CheckName)
+		review.CIAllComplete = false
+	}
+	review.CIFailures = failures
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
+)
+
+// defaultMaxDiffMb is used when ReviewConfig.MaxDiffMb is not set
+const defaultMaxDiffMb = 1.0
+
+// diffTruncatedMarker is appended when the diff exceeds the size cap
+const diffTruncatedMarker = "\n... [diff truncated]"
+
+// fetchDiffContext fetches the PR diff and returns the hunks for the commented files.
+// Errors are non-fatal - the prompt is simply built without diff context.
+func (s *ReviewService) fetchDiffContext(ctx context.Context, owner, repo string, config ReviewConfig, comments []domain.Comment) string {
+	files := make(map[string]bool)
+	for _, c := range comments {
+		if c.FilePath != "" {
+			files[c.FilePath] = true
+		}
+	}
+	if len(files) == 0 {
+		return ""
+	}
+
+	diff, err := s.github.GetDiff(ctx, owner, repo, config.PRNumber)
+	if err != nil {
+		return ""
+	}
+
+	maxMb := config.MaxDiffMb
+	if maxMb <= 0 {
+		maxMb = defaultMaxDiffMb
+	}
+
+	return truncateDiff(filterDiffByFiles(diff, files), int(maxMb*1024*1024))
+}
+
+// filterDiffByFiles keeps only the per-file sections of a unified diff that touch the given files
+func filterDiffByFiles(diff string, files map[string]bool) string {
+	var kept []string
+
+	for _, section := range splitDiffSections(diff) {
+		if files[diffSectionPath(section)] {
+			kept = append(kept, section)
+		}
+	}
+
+	return strings.Join(kept, "")
+}
+
+// splitDiffSections splits a unified diff into per-file sections starting with "diff --git"
+func splitDiffSections(diff string) []string {
+	var sections []string
+	var current strings.Builder
+
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if strings.HasPrefix(line, "diff --git ") && current.Len() > 0 {
+			sections = append(sections, current.String())
+			current.Reset()
+		}
+		current.WriteString(line)
+	}
+	if current.Len() > 0 {
+		sections = append(sections, current.String())
+	}
+
+	return sections
+}
+
+// diffSectionPath returns the new-side file path of a diff section
+func diffSectionPath(section string) string {
+	for _, line := range strings.Split(section, "\n") {
+		if strings.HasPrefix(line, "+++ b/") {
+			return strings.TrimPrefix(line, "+++ b/")
+		}
+	}
+
+	// Fall back to the header for deleted or binary files: diff --git a/path b/path
+	header := strings.SplitN(section, "\n", 2)[0]
+	if idx := strings.LastIndex(header, " b/"); idx != -1 {
+		return header[idx+3:]
+	}
+	return ""
+}
+
+// truncateDiff caps the diff at maxBytes, cutting on a line boundary
+func truncateDiff(diff string, maxBytes int) string {
+	return truncateText(diff, maxBytes, diffTruncatedMarker)
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
+	"github.com/DylanSharp/dtools/internal/logging"
+)
+
+// IterationOutcome is how a watch iteration ended
+type IterationOutcome string
+
+const (
+	IterationRunning     IterationOutcome = "running"      // Claude is still working on it
+	IterationCompleted   IterationOutcome = "completed"    // Claude finished
+	IterationFailed      IterationOutcome = "failed"       // Claude exited with an error
+	IterationStartFailed IterationOutcome = "start_failed" // The review couldn't be started
+	IterationDeclined    IterationOutcome = "declined"     // The user declined the first batch
+	IterationClosed      IterationOutcome = "closed"       // The PR was merged or closed
+)
+
+// WatchIteration records one pass of watch mode: what triggered it, what it
+// covered and how it ended
+type WatchIteration struct {
+	Number     int              `json:"number"`
+	PRNumber   int              `json:"pr"`
+	Trigger    WatchEventType   `json:"trigger"`
+	Commit     string           `json:"commit,omitempty"`
+	Comments   int              `json:"comments"`
+	Deferred   int              `json:"deferred,omitempty"`
+	CIFailures int              `json:"ci_failures"`
+	Started    time.Time        `json:"started"`
+	Finished   time.Time        `json:"finished"`
+	Outcome    IterationOutcome `json:"outcome"`
+	Error      string           `json:"error,omitempty"`
+}
+
+// Duration returns how long the iteration took, or 0 while it's running
+func (it WatchIteration) Duration() time.Duration {
+	if it.Finished.IsZero() {
+		return 0
+	}
+	return it.Finished.Sub(it.Started)
+}
+
+// newIteration starts recording an iteration for a review
+func newIteration(prNumber int, trigger WatchEventType, review *domain.Review) WatchIteration {
+	it := WatchIteration{
+		PRNumber: prNumber,
+		Trigger:  trigger,
+		Started:  time.Now(),
+		Outcome:  IterationRunning,
+	}
+	if review != nil {
+		it.Commit = review.HeadCommit
+		it.Comments = len(review.Comments)
+		it.Deferred = review.DeferredCount
+		it.CIFailures = len(review.CIFailures)
+	}
+	return it
+}
+
+// beginIteration numbers an iteration and adds it to the history, returning its index
+func (w *Watcher) beginIteration(it WatchIteration) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	it.Number = len(w.history) + 1
+	w.history = append(w.history, it)
+	return len(w.history) - 1
+}
+
+// finishIteration records how an iteration ended and appends it to the history file
+func (w *Watcher) finishIteration(index int, outcome IterationOutcome, err error) {
+	w.mu.Lock()
+	it := &w.history[index]
+	it.Finished = time.Now()
+	it.Outcome = outcome
+	if err != nil {
+		it.Error = err.Error()
+	}
+	finished := *it
+	w.mu.Unlock()
+
+	if w.opts.HistoryFile != "" {
+		if err := appendHistory(w.opts.HistoryFile, finished); err != nil {
+			logging.Warn("Failed to write watch history", "path", w.opts.HistoryFile, "error", err)
+		}
+	}
+}
+
+// recordIteration adds an iteration that ended as soon as it began
+func (w *Watcher) recordIteration(it WatchIteration, outcome IterationOutcome, err error) {
+	w.finishIteration(w.beginIteration(it), outcome, err)
+}
+
+// History returns the iterations of this watch session, oldest first
+func (w *Watcher) History() []WatchIteration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]WatchIteration(nil), w.history...)
+}
+
+// appendHistory adds an iteration to a history file as a line of JSON
+func appendHistory(path string, it WatchIteration) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(it)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
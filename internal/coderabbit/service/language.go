@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DylanSharp/dtools/internal/coderabbit/config"
+	"github.com/DylanSharp/dtools/internal/exec"
+	"github.com/DylanSharp/dtools/internal/logging"
+)
+
+// languageMarkers maps files found at a project's root to its language, checked in order
+var languageMarkers = []struct {
+	file     string
+	language string
+}{
+	{"go.mod", "go"},
+	{"pyproject.toml", "python"},
+	{"setup.py", "python"},
+	{"setup.cfg", "python"},
+	{"requirements.txt", "python"},
+	{"Pipfile", "python"},
+	{"Cargo.toml", "rust"},
+	{"package.json", "javascript"},
+}
+
+// languageInstructions are the tooling instructions added to the review prompt per language
+var languageInstructions = map[string][]string{
+	"go": {
+		"Format the code with gofmt (or goimports if the project uses it).",
+		"Run go vet ./... and fix anything it reports.",
+		"Run the tests with go test ./...",
+	},
+	"python": {
+		"Use black (locally installed) and autoflake to format the code.",
+		"Use flake8 (locally installed) to check for linting errors and fix them.",
+		"Run isort using docker-compose run --rm web python -m isort .",
+		"When you run tests with pytest, run them in parallel with -n auto.",
+	},
+	"rust": {
+		"Format the code with cargo fmt.",
+		"Run cargo clippy and fix its warnings.",
+		"Run the tests with cargo test.",
+	},
+	"javascript": {
+		"Use the lint, format, and test scripts from package.json with the project's package manager (check the lockfile).",
+	},
+}
+
+// DetectLanguage returns the primary language of the project at root from the
+// build files present there, or "" if it can't tell
+func DetectLanguage(root string) string {
+	for _, marker := range languageMarkers {
+		if _, err := os.Stat(filepath.Join(root, marker.file)); err == nil {
+			return marker.language
+		}
+	}
+	return ""
+}
+
+// LanguageInstructions returns the prompt's tooling instructions for a
+// language as a bullet list, or "" for an unknown language
+func LanguageInstructions(language string) string {
+	lines := languageInstructions[language]
+	if len(lines) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("- " + line)
+	}
+	return sb.String()
+}
+
+// ConfigurePrompt sets up the review prompt from cfg. Tooling instructions come
+// from PromptInstructions if set, otherwise from the language detected at the
+// repository root. A custom template comes from templatePath, else PromptTemplate.
+func (s *ReviewService) ConfigurePrompt(ctx context.Context, cfg *config.Config, templatePath string) error {
+	instructions := cfg.PromptInstructions
+	if instructions == "" {
+		root := "."
+		if out, err := exec.Output(ctx, "git", "rev-parse", "--show-toplevel"); err == nil {
+			root = strings.TrimSpace(string(out))
+		}
+		language := DetectLanguage(root)
+		logging.Debug("detected project language", "root", root, "language", language)
+		instructions = LanguageInstructions(language)
+	}
+	s.SetPromptInstructions(instructions)
+
+	if templatePath == "" {
+		templatePath = cfg.PromptTemplate
+	}
+	if templatePath == "" {
+		return nil
+	}
+	tmpl, err := LoadPromptTemplate(templatePath)
+	if err != nil {
+		return err
+	}
+	s.SetPromptTemplate(tmpl)
+	return nil
+}
@@ -2,56 +2,147 @@ package service
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"text/template"
+	"unicode/utf8"
 
 	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
+	"github.com/DylanSharp/dtools/internal/logging"
 )
 
+// DeclinedMarker prefixes the line Claude emits for each comment it chooses not to address
+const DeclinedMarker = "DECLINED"
+
+const (
+	// defaultMaxPromptKb is the total prompt budget used when none is configured
+	defaultMaxPromptKb = 256.0
+	// maxCommentBytes caps a single comment body in the prompt
+	maxCommentBytes = 8 * 1024
+	// minCommentBytes is the floor for a comment's share of a tight budget
+	minCommentBytes = 512
+	// truncatedMarker is appended to any text cut to fit the budget
+	truncatedMarker = "\n... [truncated]"
+	// maxPRContextBytes caps the PR description in the prompt
+	maxPRContextBytes = 4 * 1024
+)
+
+// htmlCommentPattern matches HTML comments, which PR templates use for hints to the author
+var htmlCommentPattern = regexp.MustCompile(`(?s)<!--.*?-->`)
+
 // PromptBuilder builds prompts for Claude from review data
-type PromptBuilder struct{}
+type PromptBuilder struct {
+	maxPromptBytes int
+	instructions   string             // Project-specific tooling instructions, if any
+	template       *template.Template // Custom prompt template; nil uses the built-in prompt
+}
 
-// NewPromptBuilder creates a new prompt builder
-func NewPromptBuilder() *PromptBuilder {
-	return &PromptBuilder{}
+// PromptTemplateData is what a custom prompt template is executed with. The
+// review's fields are available directly, e.g. {{range .Comments}}.
+type PromptTemplateData struct {
+	*domain.Review
+	Instructions   string // Tooling instructions for the project's language, if any
+	DeclinedMarker string // Prefix Claude must use for comments it declines
+	Default        string // The built-in prompt, for templates that only add to it
 }
 
-// BuildReviewPrompt generates a prompt for Claude to address CodeRabbit comments and CI failures
-func (b *PromptBuilder) BuildReviewPrompt(review *domain.Review) string {
-	var sections []string
+// LoadPromptTemplate parses a text/template file to use in place of the built-in review prompt
+func LoadPromptTemplate(path string) (*template.Template, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read prompt template: %w", err)
+	}
 
-	// Separate comments by type
-	inlineComments := []domain.Comment{}
-	outsideDiffComments := []domain.Comment{}
-	nitpickComments := []domain.Comment{}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(template.FuncMap{
+		"indent": func(prefix, text string) string { return indentLines(text, prefix) },
+		"join":   func(sep string, elems []string) string { return strings.Join(elems, sep) },
+		"trim":   strings.TrimSpace,
+	}).Parse(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("could not parse prompt template %s: %w", path, err)
+	}
 
-	for _, c := range review.Comments {
-		if c.IsNit {
-			nitpickComments = append(nitpickComments, c)
-		} else if c.IsOutsideDiff {
-			outsideDiffComments = append(outsideDiffComments, c)
-		} else {
-			inlineComments = append(inlineComments, c)
-		}
+	// Catch misspelled fields now rather than on the first review
+	if err := tmpl.Execute(io.Discard, PromptTemplateData{Review: &domain.Review{}}); err != nil {
+		return nil, fmt.Errorf("invalid prompt template %s: %w", path, err)
+	}
+	return tmpl, nil
+}
+
+// NewPromptBuilder creates a new prompt builder
+func NewPromptBuilder() *PromptBuilder {
+	return &PromptBuilder{
+		maxPromptBytes: int(defaultMaxPromptKb * 1024),
 	}
+}
 
-	// Process inline comments
-	if len(inlineComments) > 0 {
-		sections = append(sections, b.formatCommentSection("Inline Review Comments", inlineComments))
+// WithMaxPromptKb returns a copy of the builder with the given total prompt budget.
+// Non-positive values keep the default.
+func (b *PromptBuilder) WithMaxPromptKb(kb float64) *PromptBuilder {
+	clone := *b
+	if kb > 0 {
+		clone.maxPromptBytes = int(kb * 1024)
 	}
+	return &clone
+}
+
+// WithInstructions returns a copy of the builder that adds project-specific
+// tooling instructions (formatters, linters, test commands) to the prompt
+func (b *PromptBuilder) WithInstructions(instructions string) *PromptBuilder {
+	clone := *b
+	clone.instructions = strings.TrimSpace(instructions)
+	return &clone
+}
 
-	// Process outside diff comments
-	if len(outsideDiffComments) > 0 {
-		sections = append(sections, b.formatCommentSection("Outside Diff Range Comments", outsideDiffComments))
+// WithTemplate returns a copy of the builder that renders prompts with a custom
+// template; nil restores the built-in prompt
+func (b *PromptBuilder) WithTemplate(tmpl *template.Template) *PromptBuilder {
+	clone := *b
+	clone.template = tmpl
+	return &clone
+}
+
+// BuildReviewPrompt generates a prompt for Claude to address CodeRabbit comments and CI failures.
+// Comments that don't fit the prompt budget are left out whole rather than cut off; it
+// returns the comments the prompt includes, which is always at least one if there are any.
+// With a custom template that fails to execute, the built-in prompt is used instead.
+func (b *PromptBuilder) BuildReviewPrompt(review *domain.Review) (string, []domain.Comment) {
+	prompt, included := b.buildDefaultPrompt(review)
+	if b.template == nil {
+		return prompt, included
 	}
 
-	// Process nitpick comments
-	if len(nitpickComments) > 0 {
-		sections = append(sections, b.formatCommentSection("Nitpick Comments", nitpickComments))
+	fitted := *review
+	fitted.Comments = included
+	var sb strings.Builder
+	err := b.template.Execute(&sb, PromptTemplateData{
+		Review:         &fitted,
+		Instructions:   b.instructions,
+		DeclinedMarker: DeclinedMarker,
+		Default:        prompt,
+	})
+	if err != nil {
+		logging.Warn("prompt template failed, using the built-in prompt", "template", b.template.Name(), "err", err)
+		return prompt, included
 	}
+	return truncateText(sb.String(), b.maxPromptBytes, truncatedMarker), included
+}
 
-	// Process CI failures
-	if len(review.CIFailures) > 0 {
-		sections = append(sections, b.formatCIFailures(review.CIFailures))
+// buildDefaultPrompt generates the built-in prompt and returns the comments it includes
+func (b *PromptBuilder) buildDefaultPrompt(review *domain.Review) (string, []domain.Comment) {
+	comments := b.promptOrder(review.Comments)
+
+	// Split the budget between comments, keeping room for instructions and CI output.
+	// Comments use their extracted AI prompt when available, which is much shorter than the body.
+	commentCap := maxCommentBytes
+	if n := len(comments); n > 0 {
+		if share := (b.maxPromptBytes * 3 / 4) / n; share < commentCap {
+			commentCap = max(share, minCommentBytes)
+		}
 	}
 
 	// Build intro based on content
@@ -66,6 +157,8 @@ Each item is numbered.
 Work through each item one by one. Keep track of your progress.`
 	} else if hasFailures {
 		intro = `Please fix the following CI/test failures using extensible code and industry best practices.`
+	} else if !hasComments && len(review.AppliedSuggestions) > 0 {
+		intro = `Please check the CodeRabbit suggestions below, which were applied automatically.`
 	} else {
 		intro = `Please address the following review comments using extensible code and industry best practices.
 Assess each comment to see if you agree with the comment. If you do, address the comment. If you do not, do not address the comment.
@@ -73,48 +166,160 @@ Each item is numbered.
 Work through each item one by one. Keep track of your progress.`
 	}
 
-	prompt := fmt.Sprintf(`%s
+	// Comments come before CI output, so if they alone overflow the budget, leave
+	// out the last ones whole; they stay unprocessed for the next batch
+	if n := len(comments); n > 1 && len(b.assemble(intro, b.commentSections(comments, commentCap))) > b.maxPromptBytes {
+		fit := sort.Search(n, func(k int) bool {
+			return len(b.assemble(intro, b.commentSections(comments[:k+1], commentCap))) > b.maxPromptBytes
+		})
+		comments = comments[:max(fit, 1)]
+	}
+
+	sections := b.commentSections(comments, commentCap)
+	if len(review.CIFailures) > 0 {
+		sections = append(sections, b.formatCIFailures(review.CIFailures))
+	}
+	if len(review.AppliedSuggestions) > 0 {
+		sections = append(sections, b.formatAppliedSuggestions(review.AppliedSuggestions))
+	}
+	prompt := b.assemble(intro, sections)
+
+	// Background context only gets whatever budget the action items leave over.
+	// Each section's own framing (header, fences, separator) counts against it too.
+	remaining := b.maxPromptBytes - len(prompt)
+
+	// The PR's own description of what it's for comes first, but takes a small share
+	var prContext string
+	if description := cleanDescription(review.Description); description != "" && remaining > 0 {
+		overhead := len(b.formatPRContext(review.Title, "")) + len("\n\n")
+		if limit := min(remaining/4, maxPRContextBytes) - overhead; limit > len(truncatedMarker) {
+			prContext = b.formatPRContext(review.Title, truncateText(description, limit, truncatedMarker))
+			remaining -= len(prContext) + len("\n\n")
+		}
+	}
+
+	// Prepend CodeRabbit's walkthrough as background
+	if review.Summary != "" && remaining > 0 {
+		overhead := len(b.formatSummary("")) + len("\n\n")
+		if limit := remaining/2 - overhead; limit > len(truncatedMarker) {
+			summary := b.formatSummary(truncateText(review.Summary, limit, truncatedMarker))
+			sections = append([]string{summary}, sections...)
+			remaining -= len(summary) + len("\n\n")
+		}
+	}
+	if prContext != "" {
+		sections = append([]string{prContext}, sections...)
+	}
+
+	// Append diff context for the commented files
+	if review.DiffContext != "" && remaining > 0 {
+		overhead := len(b.formatDiffContext(review.DiffContext)) - len(strings.TrimRight(review.DiffContext, "\n")) + len("\n\n")
+		if limit := remaining - overhead; limit > len(diffTruncatedMarker) {
+			sections = append(sections, b.formatDiffContext(truncateText(review.DiffContext, limit, diffTruncatedMarker)))
+		}
+	}
+
+	return truncateText(b.assemble(intro, sections), b.maxPromptBytes, truncatedMarker), comments
+}
+
+// commentSections formats comments, already in prompt order, into their sections
+func (b *PromptBuilder) commentSections(comments []domain.Comment, commentCap int) []string {
+	inline, outsideDiff, nitpicks := splitComments(comments)
+
+	var sections []string
+	if len(inline) > 0 {
+		sections = append(sections, b.formatCommentSection("Inline Review Comments", inline, commentCap))
+	}
+	if len(outsideDiff) > 0 {
+		sections = append(sections, b.formatCommentSection("Outside Diff Range Comments", outsideDiff, commentCap))
+	}
+	if len(nitpicks) > 0 {
+		sections = append(sections, b.formatCommentSection("Nitpick Comments", nitpicks, commentCap))
+	}
+	return sections
+}
+
+// splitComments separates comments by the prompt section they belong in
+func splitComments(comments []domain.Comment) (inline, outsideDiff, nitpicks []domain.Comment) {
+	for _, c := range comments {
+		if c.IsNit {
+			nitpicks = append(nitpicks, c)
+		} else if c.IsOutsideDiff {
+			outsideDiff = append(outsideDiff, c)
+		} else {
+			inline = append(inline, c)
+		}
+	}
+	return inline, outsideDiff, nitpicks
+}
+
+// promptOrder returns comments in the order the prompt lists them: by section,
+// then by file, then by line
+func (b *PromptBuilder) promptOrder(comments []domain.Comment) []domain.Comment {
+	inline, outsideDiff, nitpicks := splitComments(comments)
+
+	ordered := make([]domain.Comment, 0, len(comments))
+	for _, section := range [][]domain.Comment{inline, outsideDiff, nitpicks} {
+		grouped := b.groupByFile(section)
+		files := make([]string, 0, len(grouped))
+		for file := range grouped {
+			files = append(files, file)
+		}
+		sort.Strings(files)
+		for _, file := range files {
+			ordered = append(ordered, grouped[file]...)
+		}
+	}
+	return ordered
+}
+
+// assemble wraps the intro and sections in the standard instructions
+func (b *PromptBuilder) assemble(intro string, sections []string) string {
+	instructions := ""
+	if b.instructions != "" {
+		instructions = "\n" + b.instructions
+	}
+
+	return fmt.Sprintf(`%s
 
 - Make minimal, safe edits aligned with project style.
 - If a change requires design or product input, do NOT edit; instead, leave me a clear comment reply explaining the decision/tradeoffs.
+- For each comment you decide NOT to address, output a single line in the form "%s <id>: <one-sentence rationale>" using the comment's id.
 - After making your changes, run the full suite of tests and linters and ensure they pass and there are no new errors or warnings.
-- If you need more context on any one item you can use the github CLI tool (gh) to fetch more information from the pull request.
-- If it's a python project:
-	- Use black (locally installed) and autoflake to format the code.
-	- Use flake8 (locally installed) to check for linting errors and fix them.
-	- Run isort using docker-compose run --rm web python -m isort .
-	- When you run tests with pytest, run them in parallel with -n auto.
+- If you need more context on any one item you can use the github CLI tool (gh) to fetch more information from the pull request.%s
 
 When you are happy with the changes, commit the changes and push them to the branch.
 
-%s`, intro, strings.Join(sections, "\n\n"))
-
-	return prompt
+%s`, intro, DeclinedMarker, instructions, strings.Join(sections, "\n\n"))
 }
 
-// formatCommentSection formats a section of comments
-func (b *PromptBuilder) formatCommentSection(title string, comments []domain.Comment) string {
+// formatCommentSection formats a section of comments, capping each body at maxBytes
+func (b *PromptBuilder) formatCommentSection(title string, comments []domain.Comment, maxBytes int) string {
 	var lines []string
 	lines = append(lines, fmt.Sprintf("--- %s ---", title))
 
-	// Group comments by file
+	// Group comments by file, in a stable order so comment numbers don't change between runs
 	grouped := b.groupByFile(comments)
+	files := make([]string, 0, len(grouped))
+	for file := range grouped {
+		files = append(files, file)
+	}
+	sort.Strings(files)
 
 	commentNumber := 1
-	for file, fileComments := range grouped {
+	for _, file := range files {
+		fileComments := grouped[file]
 		lines = append(lines, fmt.Sprintf("## %s", file))
 
 		for _, comment := range fileComments {
-			lineInfo := ""
-			if comment.LineNumber > 0 {
-				lineInfo = fmt.Sprintf("L%d", comment.LineNumber)
-			}
+			lineInfo := comment.LineRange()
 
-			// Use AI prompt if available, otherwise full body
-			body := comment.EffectiveBody()
+			// Use AI prompt if available, otherwise full body.
+			// Close any fence left open by truncation so it can't swallow the following items.
+			body := balanceFences(truncateText(comment.EffectiveBody(), maxBytes, truncatedMarker))
 
 			// Format as a numbered checkbox item
-			lines = append(lines, fmt.Sprintf("- [ ] %d. %s (%s)", commentNumber, lineInfo, comment.URL))
+			lines = append(lines, fmt.Sprintf("- [ ] %d. %s (%s) [id: %d]", commentNumber, lineInfo, comment.URL, comment.ID))
 
 			// Indent the body
 			indentedBody := b.indentText(body, "   ")
@@ -179,7 +384,61 @@ func (b *PromptBuilder) formatCIFailures(failures []domain.CITestFailure) string
 	return strings.Join(lines, "\n")
 }
 
-// groupByFile groups comments by their file path
+// formatAppliedSuggestions lists the suggestions applied with git apply before
+// the run, for Claude to check rather than address again
+func (b *PromptBuilder) formatAppliedSuggestions(applied []domain.Comment) string {
+	var lines []string
+	lines = append(lines, "--- Suggestions Already Applied ---")
+	lines = append(lines, "")
+	lines = append(lines, "These CodeRabbit suggestions were applied as-is with git apply. Check that each change is correct in context; fix or revert any that aren't.")
+	for _, c := range applied {
+		lines = append(lines, "- "+c.Location())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// formatSummary formats CodeRabbit's walkthrough as background context
+func (b *PromptBuilder) formatSummary(summary string) string {
+	var lines []string
+	lines = append(lines, "--- CodeRabbit Summary (background only, not action items) ---")
+	lines = append(lines, "")
+	lines = append(lines, summary)
+
+	return strings.Join(lines, "\n")
+}
+
+// formatPRContext formats the PR's title and description as background context
+func (b *PromptBuilder) formatPRContext(title, description string) string {
+	var lines []string
+	lines = append(lines, "--- PR Context (the author's intent; background only, not action items) ---")
+	lines = append(lines, "")
+	lines = append(lines, "Title: "+title)
+	lines = append(lines, "")
+	lines = append(lines, description)
+
+	return strings.Join(lines, "\n")
+}
+
+// cleanDescription strips template hints and surrounding space from a PR description
+func cleanDescription(description string) string {
+	return strings.TrimSpace(htmlCommentPattern.ReplaceAllString(description, ""))
+}
+
+// formatDiffContext formats the PR diff hunks for the commented files
+func (b *PromptBuilder) formatDiffContext(diff string) string {
+	var lines []string
+	lines = append(lines, "--- PR Diff (commented files) ---")
+	lines = append(lines, "")
+	fence := fenceFor(diff)
+	lines = append(lines, fence+"diff")
+	lines = append(lines, strings.TrimRight(diff, "\n"))
+	lines = append(lines, fence)
+
+	return strings.Join(lines, "\n")
+}
+
+// groupByFile groups comments by their file path, each file's comments ordered by line
 func (b *PromptBuilder) groupByFile(comments []domain.Comment) map[string][]domain.Comment {
 	grouped := make(map[string][]domain.Comment)
 
@@ -191,14 +450,75 @@ func (b *PromptBuilder) groupByFile(comments []domain.Comment) map[string][]doma
 		grouped[file] = append(grouped[file], comment)
 	}
 
+	for _, fileComments := range grouped {
+		sort.SliceStable(fileComments, func(i, j int) bool {
+			if fileComments[i].LineNumber != fileComments[j].LineNumber {
+				return fileComments[i].LineNumber < fileComments[j].LineNumber
+			}
+			return fileComments[i].ID < fileComments[j].ID
+		})
+	}
+
 	return grouped
 }
 
 // indentText indents each line of text with the given prefix
 func (b *PromptBuilder) indentText(text, indent string) string {
+	return indentLines(text, indent)
+}
+
+// indentLines indents each line of text with the given prefix
+func indentLines(text, indent string) string {
 	lines := strings.Split(text, "\n")
 	for i, line := range lines {
 		lines[i] = indent + line
 	}
 	return strings.Join(lines, "\n")
 }
+
+// truncateText caps text at maxBytes, cutting on a line boundary, or else between
+// characters, and appending marker
+func truncateText(text string, maxBytes int, marker string) string {
+	if maxBytes <= 0 || len(text) <= maxBytes {
+		return text
+	}
+
+	end := max(maxBytes-len(marker), 0)
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	cut := text[:end]
+	if idx := strings.LastIndex(cut, "\n"); idx > 0 {
+		cut = cut[:idx]
+	}
+	return cut + marker
+}
+
+// balanceFences closes a trailing unterminated ``` code fence
+func balanceFences(text string) string {
+	inFence := false
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+	}
+	if inFence {
+		return text + "\n```"
+	}
+	return text
+}
+
+// fenceFor returns a backtick fence longer than any backtick run in content,
+// so fences inside the content can't terminate the outer block
+func fenceFor(content string) string {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateText(t *testing.T) {
+	const marker = "\n[truncated]"
+	tests := []struct {
+		name     string
+		text     string
+		maxBytes int
+		want     string
+	}{
+		{"fits", "short", 10, "short"},
+		{"no limit", "anything", 0, "anything"},
+		{"line boundary", "first line\nsecond line\nthird line", 30, "first line" + marker},
+		{"single line", strings.Repeat("a", 40), 20, strings.Repeat("a", 20-len(marker)) + marker},
+		// 7 bytes are left for text: two 3-byte runes, then half of the third
+		{"multi-byte runes", strings.Repeat("€", 10), 7 + len(marker), "€€" + marker},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateText(tt.text, tt.maxBytes, marker)
+			if got != tt.want {
+				t.Errorf("truncateText() = %q, want %q", got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncateText() = %q is not valid UTF-8", got)
+			}
+		})
+	}
+}
@@ -2,23 +2,30 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/DylanSharp/dtools/internal/coderabbit/adapters"
 	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
 	"github.com/DylanSharp/dtools/internal/coderabbit/ports"
 	"github.com/DylanSharp/dtools/internal/coderabbit/state"
+	"github.com/DylanSharp/dtools/internal/logging"
 )
 
 // ReviewService orchestrates the review process
 type ReviewService struct {
-	github       ports.GitHubClient
-	ci           ports.CIProvider
-	aiProvider   ports.AIProvider
+	github        ports.GitHubClient
+	ci            ports.CIProvider
+	aiProvider    ports.AIProvider
+	suggestions   ports.SuggestionApplier
 	promptBuilder *PromptBuilder
-	parser       *adapters.ClaudeStreamParser
+	parser        *adapters.ClaudeStreamParser
 }
 
 // NewReviewService creates a new review service
@@ -31,113 +38,95 @@ func NewReviewService(
 		github:        github,
 		ci:            ci,
 		aiProvider:    aiProvider,
+		suggestions:   adapters.NewGitSuggestionApplier(),
 		promptBuilder: NewPromptBuilder(),
 		parser:        adapters.NewClaudeStreamParser(),
 	}
 }
 
+// SetSuggestionApplier replaces how committable suggestions are applied with
+// ApplySuggestions, which by default is git apply in the current checkout
+func (s *ReviewService) SetSuggestionApplier(applier ports.SuggestionApplier) {
+	s.suggestions = applier
+}
+
+// SetPromptInstructions sets the project-specific tooling instructions added
+// to every review prompt; empty leaves them out
+func (s *ReviewService) SetPromptInstructions(instructions string) {
+	s.promptBuilder = s.promptBuilder.WithInstructions(instructions)
+}
+
+// SetPromptTemplate renders review prompts with a custom template instead of
+// the built-in one; nil restores the built-in prompt
+func (s *ReviewService) SetPromptTemplate(tmpl *template.Template) {
+	s.promptBuilder = s.promptBuilder.WithTemplate(tmpl)
+}
+
 // ReviewConfig contains configuration for a review
 type ReviewConfig struct {
-	PRNumber        int
-	IncludeNits     bool
-	IncludeOutdated bool
-	MaxDiffMb       float64
-	ResetState      bool // If true, clear state before starting
-	MarkAddressed   bool // If true, mark comments as resolved on GitHub
+	PRNumber         int
+	IncludeNits      bool
+	IncludeOutdated  bool
+	MaxDiffMb        float64 // Size cap for the diff included in the prompt
+	MaxPromptKb      float64 // Total prompt budget; comments and background are truncated to fit
+	WithDiff         bool    // If true, include diff hunks for commented files in the prompt
+	IncludeSummary   bool    // If true, include CodeRabbit's walkthrough as background context
+	IncludePRContext bool    // If true, include the PR's title and description as background context
+	ReplyToDeclined  bool    // If true, reply to comments Claude declines with its rationale
+	ResetState       bool    // If true, clear state before starting
+	MarkAddressed    bool    // If true, mark comments as resolved on GitHub
+	Since            string  // If set, only comments created after this commit are reviewed
+	ApplySuggestions bool    // If true, apply trivial committable suggestions with git apply before invoking Claude
+	AllowClosed      bool    // If true, review merged and closed PRs too
+	MaxComments      int     // If set, address at most this many comments per run and defer the rest
+	RerunRunIDs      []int64 // Workflow runs just rerun as possibly flaky; their failures count as pending
 }
 
 // StartReview initiates a PR review and returns a channel of thoughts
 func (s *ReviewService) StartReview(ctx context.Context, config ReviewConfig) (*domain.Review, <-chan domain.ThoughtChunk, error) {
-	// Get repo info
-	owner, repo, err := s.github.GetRepoInfo(ctx)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get repo info: %w", err)
-	}
-
-	repository := fmt.Sprintf("%s/%s", owner, repo)
-	stateKey := state.GetStateKey(owner, repo, config.PRNumber)
-
-	// Reset state if requested
-	if config.ResetState {
-		_ = state.Reset(stateKey)
-	}
-
-	// Load state for filtering processed comments
-	trackerState, err := state.GetOrCreate(stateKey)
-	if err != nil {
-		// Non-fatal - continue without state tracking
-		trackerState = &state.TrackerState{
-			ProcessedCommentIDs: []int{},
-			ProcessedByHash:     []string{},
-			SeenComments:        make(map[int]state.SeenInfo),
-		}
-	}
-
-	// Create review
-	review := domain.NewReview(config.PRNumber, repository)
-	review.Status = domain.ReviewStatusFetching
-
-	// Fetch PR details
-	pr, err := s.github.GetPullRequest(ctx, owner, repo, config.PRNumber)
+	review, err := s.FetchReviewData(ctx, config)
 	if err != nil {
 		return nil, nil, err
 	}
+	owner, repo := s.parseRepository(review.Repository)
+	stateKey := state.GetStateKey(owner, repo, config.PRNumber)
 
-	review.Branch = pr.Branch
-	review.BaseBranch = pr.BaseBranch
-	review.HeadCommit = pr.HeadCommit
-	review.BaseCommit = pr.BaseCommit
-	review.Title = pr.Title
-	review.Author = pr.Author
-
-	// Fetch CodeRabbit comments
-	comments, err := s.github.ListCodeRabbitComments(ctx, owner, repo, config.PRNumber)
-	if err != nil {
-		// No comments is not a fatal error
-		if _, ok := err.(*domain.ReviewError); !ok || err.(*domain.ReviewError).Code != domain.ErrCodeNoComments {
-			return nil, nil, err
-		}
-	}
-
-	// Filter comments based on config (nits, outdated, etc.)
-	filteredComments := s.filterComments(comments, config)
-
-	// Track total found for UI display
-	review.TotalFoundCount = len(filteredComments)
-
-	// Filter out already processed comments using state
-	unprocessedComments := state.FilterUnprocessed(trackerState, filteredComments)
-	review.Comments = unprocessedComments
-	review.RemainingCount = len(unprocessedComments)
-	review.NewCommentsCount = len(unprocessedComments)
-	review.AlreadyAddressed = review.TotalFoundCount - review.NewCommentsCount
-
-	// Fetch CI status (includes failures and pending checks)
-	ciStatus, err := s.ci.GetCIStatus(ctx, owner, repo, pr.HeadCommit)
-	if err != nil {
-		// CI status is optional - log but continue with empty status
-		ciStatus = domain.CIStatus{}
+	// Reviewing a merged or closed PR is only useful for deliberate post-merge cleanup
+	if review.IsClosed() && !config.AllowClosed {
+		return nil, nil, domain.ErrPRClosed(config.PRNumber, review.PRState)
 	}
-	review.CIFailures = ciStatus.Failures
-	review.CIPendingCount = ciStatus.PendingCount
-	review.CIPendingNames = ciStatus.PendingNames
-	review.CIAllComplete = ciStatus.AllComplete()
-	review.CodeRabbitFound = ciStatus.CodeRabbitFound
-	review.CodeRabbitCompleted = ciStatus.CodeRabbitCompleted
 
 	// Check if there's anything to review
 	// Only mark satisfied if:
 	// - No comments AND no CI failures AND all CI checks complete
 	// - AND CodeRabbit has actually reviewed (found and completed)
-	codeRabbitReviewed := ciStatus.CodeRabbitFound && ciStatus.CodeRabbitCompleted
-	if len(unprocessedComments) == 0 && len(ciStatus.Failures) == 0 && ciStatus.AllComplete() && codeRabbitReviewed {
+	codeRabbitReviewed := review.CodeRabbitFound && review.CodeRabbitCompleted
+	if len(review.Comments) == 0 && len(review.CIFailures) == 0 && review.CIAllComplete && codeRabbitReviewed {
 		review.Status = domain.ReviewStatusSatisfied
 		review.MarkSatisfied()
 		return review, nil, nil
 	}
 
-	// Build prompt
-	prompt := s.promptBuilder.BuildReviewPrompt(review)
+	// Cap the batch; the rest stay unprocessed in state for the next run
+	capBatch(review, config.MaxComments)
+	unprocessedComments := review.Comments
+
+	// Apply trivial committable suggestions locally so Claude only checks them. Like
+	// the rest, they're only marked processed and resolved once Claude's run completes.
+	if config.ApplySuggestions {
+		applied, remaining := s.applySuggestions(ctx, unprocessedComments)
+		if len(applied) > 0 {
+			review.AppliedSuggestions = applied
+			unprocessedComments = remaining
+			review.Comments = remaining
+			review.RemainingCount = len(remaining)
+		}
+	}
+
+	// Comments that don't fit the prompt budget wait for the next batch, so they
+	// mustn't be marked processed or resolved with this one
+	prompt := s.buildBatchPrompt(ctx, owner, repo, config, review)
+	unprocessedComments = review.Comments
 
 	// Start Claude streaming
 	review.Status = domain.ReviewStatusReviewing
@@ -147,46 +136,219 @@ func (s *ReviewService) StartReview(ctx context.Context, config ReviewConfig) (*
 		return nil, nil, err
 	}
 
+	// Watch for a timeout error before the parser drops error chunks
+	var timeoutErr error
+	monitored := make(chan ports.StreamChunk, 100)
+	go func() {
+		defer close(monitored)
+		for chunk := range chunks {
+			if chunk.Error != nil && chunk.Error.Type == ports.StreamErrorTimeout {
+				timeoutErr = errors.New(chunk.Error.Message)
+			}
+			monitored <- chunk
+		}
+	}()
+
 	// Filter and transform chunks to thoughts
-	thoughts := s.parser.FilterThoughts(chunks)
+	thoughts := s.parser.FilterThoughts(monitored)
 
 	// Capture values for goroutine
 	markAddressed := config.MarkAddressed
+	replyToDeclined := config.ReplyToDeclined
 	ghClient := s.github
 
 	// Wrap the channel to track review state
 	trackedThoughts := make(chan domain.ThoughtChunk, 100)
 	go func() {
 		defer close(trackedThoughts)
+		if len(review.AppliedSuggestions) > 0 {
+			thought := appliedSuggestionsThought(review.AppliedSuggestions)
+			review.AddThought(thought)
+			trackedThoughts <- thought
+		}
 		for thought := range thoughts {
 			review.AddThought(thought)
 			review.ProcessedCount++
 			review.CurrentFile = thought.File
 			trackedThoughts <- thought
 		}
+
+		// A timed-out run is incomplete - leave comments unprocessed so they're retried
+		if timeoutErr != nil {
+			review.MarkFailed()
+			trackedThoughts <- domain.ThoughtChunk{
+				Timestamp: time.Now(),
+				Content:   timeoutErr.Error(),
+				Type:      domain.ThoughtTypeProgress,
+			}
+			return
+		}
 		review.MarkCompleted()
 
 		// Mark comments as processed after Claude finishes
-		_ = state.MarkProcessed(stateKey, unprocessedComments, "")
+		addressed := append(append([]domain.Comment{}, review.AppliedSuggestions...), unprocessedComments...)
+		_ = state.MarkProcessed(stateKey, addressed, "")
+
+		// Declined comments are left unresolved so the reviewer sees them, and are
+		// answered with Claude's rationale if enabled
+		declined := extractDeclinedComments(review.Thoughts)
+		if replyToDeclined {
+			for _, comment := range unprocessedComments {
+				rationale, ok := declined[comment.ID]
+				// Only real review comments can be replied to, not synthetic or general ones
+				if !ok || comment.ID <= 0 || comment.FilePath == "" {
+					continue
+				}
+				if err := ghClient.ReplyToComment(ctx, owner, repo, config.PRNumber, comment.ID, rationale); err != nil {
+					logging.Warn("failed to reply to declined comment", "id", comment.ID, "location", comment.Location(), "error", err)
+					thought := domain.ThoughtChunk{
+						Timestamp: time.Now(),
+						Content:   fmt.Sprintf("couldn't reply to declined comment on %s (comment #%d): %v", comment.Location(), comment.ID, err),
+						Type:      domain.ThoughtTypeProgress,
+						File:      comment.FilePath,
+					}
+					review.AddThought(thought)
+					trackedThoughts <- thought
+				}
+			}
+		}
 
 		// Mark comments as resolved on GitHub if enabled
+		var resolved, unresolved []domain.Comment
 		if markAddressed {
-			for _, comment := range unprocessedComments {
-				if comment.ID > 0 { // Only real comments, not synthetic ones
-					_ = ghClient.ResolveComment(ctx, owner, repo, config.PRNumber, comment.ID)
+			var toResolve []domain.Comment
+			for _, comment := range addressed {
+				if _, ok := declined[comment.ID]; !ok {
+					toResolve = append(toResolve, comment)
 				}
 			}
+			resolved, unresolved = s.resolveComments(ctx, owner, repo, config.PRNumber, toResolve)
+		}
+		review.ResolvedCount = len(resolved)
+		for _, thought := range resolutionThoughts(resolved, unresolved) {
+			review.AddThought(thought)
+			trackedThoughts <- thought
 		}
 	}()
 
 	return review, trackedThoughts, nil
 }
 
+// capComments returns the first max comments and how many were left out. Comments
+// are ordered by file first, so a file's comments tend to land in the same batch.
+// A non-positive max keeps them all.
+func capComments(comments []domain.Comment, max int) ([]domain.Comment, int) {
+	if max <= 0 || len(comments) <= max {
+		return comments, 0
+	}
+
+	sorted := make([]domain.Comment, len(comments))
+	copy(sorted, comments)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].FilePath != sorted[j].FilePath {
+			return sorted[i].FilePath < sorted[j].FilePath
+		}
+		return sorted[i].LineNumber < sorted[j].LineNumber
+	})
+	return sorted[:max], len(sorted) - max
+}
+
+// resolveComments resolves the threads of real comments on GitHub. It returns the
+// comments whose threads were resolved and, after logging them, the comments that
+// couldn't be; comments on threads that were already resolved are in neither.
+func (s *ReviewService) resolveComments(ctx context.Context, owner, repo string, prNumber int, comments []domain.Comment) (resolved, failed []domain.Comment) {
+	var ids []int
+	for _, comment := range comments {
+		if comment.ID > 0 { // Only real comments, not synthetic ones
+			ids = append(ids, comment.ID)
+		}
+	}
+
+	resolvedIDs, errs := s.github.ResolveComments(ctx, owner, repo, prNumber, ids)
+	isResolved := make(map[int]bool, len(resolvedIDs))
+	for _, id := range resolvedIDs {
+		isResolved[id] = true
+	}
+
+	for _, comment := range comments {
+		if isResolved[comment.ID] {
+			logging.Debug("resolved comment thread", "id", comment.ID, "location", comment.Location())
+			resolved = append(resolved, comment)
+		} else if err, ok := errs[comment.ID]; ok {
+			logging.Warn("failed to resolve comment", "id", comment.ID, "location", comment.Location(), "error", err)
+			failed = append(failed, comment)
+		}
+	}
+	return resolved, failed
+}
+
+// resolutionThoughts reports the comment threads resolved on GitHub, and those that
+// couldn't be, as a header followed by one line per comment
+func resolutionThoughts(resolved, failed []domain.Comment) []domain.ThoughtChunk {
+	if len(resolved) == 0 && len(failed) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	thoughts := []domain.ThoughtChunk{{
+		Timestamp: now,
+		Content:   fmt.Sprintf("─── Resolved on GitHub (%d) ───", len(resolved)),
+		Type:      domain.ThoughtTypeHeader,
+	}}
+	for _, c := range resolved {
+		thoughts = append(thoughts, domain.ThoughtChunk{
+			Timestamp: now,
+			Content:   "resolved thread on " + c.Location(),
+			Type:      domain.ThoughtTypeProgress,
+			File:      c.FilePath,
+		})
+	}
+	for _, c := range failed {
+		thoughts = append(thoughts, domain.ThoughtChunk{
+			Timestamp: now,
+			Content:   fmt.Sprintf("couldn't resolve thread on %s (comment #%d)", c.Location(), c.ID),
+			Type:      domain.ThoughtTypeProgress,
+			File:      c.FilePath,
+		})
+	}
+	return thoughts
+}
+
+// declinedPattern matches the line Claude emits for a declined comment: "DECLINED <id>: <rationale>"
+// The separator before the ID excludes "-", which is the sign of synthetic comment IDs.
+var declinedPattern = regexp.MustCompile(`^\W*` + DeclinedMarker + `[^\w-]*(-?\d+)\W*:\s*(.+)$`)
+
+// extractDeclinedComments maps comment IDs to the rationale Claude gave for declining them
+func extractDeclinedComments(thoughts []domain.ThoughtChunk) map[int]string {
+	declined := make(map[int]string)
+	for _, thought := range thoughts {
+		for _, line := range strings.Split(thought.Content, "\n") {
+			matches := declinedPattern.FindStringSubmatch(strings.TrimSpace(line))
+			if len(matches) < 3 {
+				continue
+			}
+			id, err := strconv.Atoi(matches[1])
+			if err != nil {
+				continue
+			}
+			if _, exists := declined[id]; !exists {
+				declined[id] = strings.TrimSpace(matches[2])
+			}
+		}
+	}
+	return declined
+}
+
 // DetectCurrentPR detects the PR number from the current branch
 func (s *ReviewService) DetectCurrentPR(ctx context.Context) (int, error) {
 	return s.github.GetCurrentPR(ctx)
 }
 
+// DetectPRForBranch finds the open PR for a branch other than the current one
+func (s *ReviewService) DetectPRForBranch(ctx context.Context, branch string) (int, error) {
+	return s.github.GetPRForBranch(ctx, branch)
+}
+
 // GetRepoInfo returns the owner and repo
 func (s *ReviewService) GetRepoInfo(ctx context.Context) (owner, repo string, err error) {
 	return s.github.GetRepoInfo(ctx)
@@ -197,7 +359,8 @@ func (s *ReviewService) GetCurrentBranch(ctx context.Context) (string, error) {
 	return s.github.GetCurrentBranch(ctx)
 }
 
-// FetchReviewData fetches review data without starting Claude
+// FetchReviewData fetches the PR, its unprocessed comments and its CI status
+// without starting Claude. StartReview and BuildPrompt start from it.
 func (s *ReviewService) FetchReviewData(ctx context.Context, config ReviewConfig) (*domain.Review, error) {
 	owner, repo, err := s.github.GetRepoInfo(ctx)
 	if err != nil {
@@ -235,7 +398,12 @@ func (s *ReviewService) FetchReviewData(ctx context.Context, config ReviewConfig
 	review.HeadCommit = pr.HeadCommit
 	review.BaseCommit = pr.BaseCommit
 	review.Title = pr.Title
+	if config.IncludePRContext {
+		review.Description = pr.Body
+	}
 	review.Author = pr.Author
+	review.PRState = pr.State
+	review.IsDraft = pr.IsDraft
 
 	// Fetch comments
 	comments, err := s.github.ListCodeRabbitComments(ctx, owner, repo, config.PRNumber)
@@ -247,6 +415,9 @@ func (s *ReviewService) FetchReviewData(ctx context.Context, config ReviewConfig
 
 	// Filter by config then by state
 	filteredComments := s.filterComments(comments, config)
+	if filteredComments, err = s.filterSince(ctx, owner, repo, filteredComments, config.Since); err != nil {
+		return nil, err
+	}
 	review.TotalFoundCount = len(filteredComments)
 	review.Comments = state.FilterUnprocessed(trackerState, filteredComments)
 	review.RemainingCount = len(review.Comments)
@@ -262,11 +433,105 @@ func (s *ReviewService) FetchReviewData(ctx context.Context, config ReviewConfig
 		review.CIAllComplete = ciStatus.AllComplete()
 		review.CodeRabbitFound = ciStatus.CodeRabbitFound
 		review.CodeRabbitCompleted = ciStatus.CodeRabbitCompleted
+		holdRerunFailures(review, config.RerunRunIDs)
 	}
 
 	return review, nil
 }
 
+// BuildPrompt returns the prompt StartReview would send Claude for the PR's
+// current comments and CI failures, without invoking Claude or changing state.
+// The review is returned too so callers can tell an empty one apart.
+func (s *ReviewService) BuildPrompt(ctx context.Context, config ReviewConfig) (string, *domain.Review, error) {
+	// Dumping the prompt must not clear what's been processed
+	config.ResetState = false
+
+	review, err := s.FetchReviewData(ctx, config)
+	if err != nil {
+		return "", nil, err
+	}
+	capBatch(review, config.MaxComments)
+
+	owner, repo := s.parseRepository(review.Repository)
+	prompt := s.buildBatchPrompt(ctx, owner, repo, config, review)
+	return prompt, review, nil
+}
+
+// capBatch caps a review's comments at max for this run and counts the rest as deferred
+func capBatch(review *domain.Review, max int) {
+	review.Comments, review.DeferredCount = capComments(review.Comments, max)
+	review.RemainingCount = len(review.Comments)
+	review.NewCommentsCount = len(review.Comments)
+}
+
+// buildBatchPrompt attaches the background config asks for to the review and
+// builds the prompt for its comments and CI failures. Comments that don't fit
+// the prompt budget are dropped from the review and counted as deferred.
+func (s *ReviewService) buildBatchPrompt(ctx context.Context, owner, repo string, config ReviewConfig, review *domain.Review) string {
+	// Attach diff context for commented files if requested
+	if config.WithDiff {
+		review.DiffContext = s.fetchDiffContext(ctx, owner, repo, config, review.Comments)
+	}
+
+	// Attach CodeRabbit's walkthrough as background if requested (non-fatal)
+	if config.IncludeSummary {
+		if summary, err := s.github.GetCodeRabbitSummary(ctx, owner, repo, config.PRNumber); err == nil {
+			review.Summary = summary
+		}
+	}
+
+	prompt, included := s.promptBuilder.WithMaxPromptKb(config.MaxPromptKb).BuildReviewPrompt(review)
+	if left := len(review.Comments) - len(included); left > 0 {
+		logging.Info("prompt budget exceeded, deferring comments", "included", len(included), "deferred", left)
+		review.DeferredCount += left
+		review.Comments = included
+		review.RemainingCount = len(included)
+		review.NewCommentsCount = len(included)
+	}
+	return prompt
+}
+
+// ListAllComments fetches every CodeRabbit comment on a PR along with CI status,
+// without applying config or state filtering and without invoking Claude
+func (s *ReviewService) ListAllComments(ctx context.Context, prNumber int) ([]domain.Comment, domain.CIStatus, error) {
+	owner, repo, err := s.github.GetRepoInfo(ctx)
+	if err != nil {
+		return nil, domain.CIStatus{}, fmt.Errorf("failed to get repo info: %w", err)
+	}
+
+	pr, err := s.github.GetPullRequest(ctx, owner, repo, prNumber)
+	if err != nil {
+		return nil, domain.CIStatus{}, err
+	}
+
+	comments, err := s.github.ListCodeRabbitComments(ctx, owner, repo, prNumber)
+	if err != nil {
+		if rerr, ok := err.(*domain.ReviewError); !ok || rerr.Code != domain.ErrCodeNoComments {
+			return nil, domain.CIStatus{}, err
+		}
+	}
+
+	// CI status is optional
+	ciStatus, err := s.ci.GetCIStatus(ctx, owner, repo, pr.HeadCommit)
+	if err != nil {
+		ciStatus = domain.CIStatus{}
+	}
+
+	return comments, ciStatus, nil
+}
+
+// PruneState drops stored comment state for PRs that are no longer open.
+// PRs whose status can't be fetched are left untouched.
+func (s *ReviewService) PruneState(ctx context.Context) ([]string, error) {
+	return state.Prune(func(owner, repo string, pr int) (bool, error) {
+		info, err := s.github.GetPullRequest(ctx, owner, repo, pr)
+		if err != nil {
+			return true, err
+		}
+		return strings.EqualFold(info.State, "OPEN"), nil
+	})
+}
+
 // filterComments filters comments based on configuration
 func (s *ReviewService) filterComments(comments []domain.Comment, config ReviewConfig) []domain.Comment {
 	var filtered []domain.Comment
@@ -293,6 +558,30 @@ func (s *ReviewService) filterComments(comments []domain.Comment, config ReviewC
 	return filtered
 }
 
+// filterSince drops comments created before the given commit was committed.
+// An empty sha keeps every comment.
+func (s *ReviewService) filterSince(ctx context.Context, owner, repo string, comments []domain.Comment, sha string) ([]domain.Comment, error) {
+	if sha == "" {
+		return comments, nil
+	}
+
+	since, err := s.github.GetCommitTime(ctx, owner, repo, sha)
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []domain.Comment
+	for _, c := range comments {
+		// Comments without a timestamp are kept rather than silently dropped
+		if !c.CreatedAt.IsZero() && c.CreatedAt.Before(since) {
+			continue
+		}
+		filtered = append(filtered, c)
+	}
+
+	return filtered, nil
+}
+
 // CheckSatisfaction checks if CodeRabbit is satisfied with the current state
 func (s *ReviewService) CheckSatisfaction(ctx context.Context, review *domain.Review) (SatisfactionResult, error) {
 	detector := NewSatisfactionDetector()
@@ -336,9 +625,26 @@ type WatchOptions struct {
 	PollInterval         time.Duration
 	CooldownDuration     time.Duration
 	BatchWaitDuration    time.Duration // Wait for more comments before processing
+	BatchWaitMax         time.Duration // Cap on the total adaptive batch wait
+	AdaptiveBatchWait    bool          // Keep extending the batch wait while comments are still arriving
 	RequireManualConfirm bool
 	IncludeNits          bool
 	IncludeOutdated      bool
+	WithDiff             bool
+	MaxDiffMb            float64
+	MaxPromptKb          float64
+	IncludeSummary       bool
+	IncludePRContext     bool
+	ReplyToDeclined      bool
+	Since                string // Only review comments created after this commit
+	ApplySuggestions     bool   // Apply trivial committable suggestions before invoking Claude
+	AllowClosed          bool   // Keep watching a PR after it's merged or closed
+	RerunFailedCI        bool   // Rerun failed Actions runs once before treating them as real failures
+	ConfirmFirstBatch    bool   // Wait for approval before Claude addresses the first batch, then run unattended
+	MaxComments          int    // Address at most this many comments per review; the rest follow in later batches
+	HistoryFile          string // Append each finished iteration here as a line of JSON, if set
+	ResetState           bool   // Clear the PR's state, including the last session's checkpoint, before watching
+	MaxIterations        int    // Stop after this many Claude runs without CodeRabbit being satisfied (0 = no limit)
 }
 
 // DefaultWatchOptions returns default watch configuration
@@ -347,6 +653,8 @@ func DefaultWatchOptions() WatchOptions {
 		PollInterval:         15 * time.Second,
 		CooldownDuration:     3 * time.Minute,
 		BatchWaitDuration:    30 * time.Second, // Wait for CodeRabbit to finish posting
+		BatchWaitMax:         2 * time.Minute,
+		AdaptiveBatchWait:    true,
 		RequireManualConfirm: true,
 		IncludeNits:          true,
 		IncludeOutdated:      true,
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
+)
+
+func TestExtractDeclinedComments(t *testing.T) {
+	thoughts := []domain.ThoughtChunk{
+		{Content: "Fixed the error handling in a.go."},
+		{Content: "DECLINED 12: the name matches the API it wraps\nMoving on."},
+		{Content: "- **DECLINED #34**: out of scope for this PR"},
+		{Content: "`DECLINED -2`: the CI failure is a flaky test"},
+		{Content: "DECLINED 12: a second rationale is ignored"},
+		{Content: "I declined nothing else. DECLINED 56: mid-sentence markers don't count"},
+		{Content: "DECLINED abc: not an ID"},
+	}
+
+	want := map[int]string{
+		12: "the name matches the API it wraps",
+		34: "out of scope for this PR",
+		-2: "the CI failure is a flaky test",
+	}
+	if got := extractDeclinedComments(thoughts); !reflect.DeepEqual(got, want) {
+		t.Errorf("extractDeclinedComments() = %v, want %v", got, want)
+	}
+}
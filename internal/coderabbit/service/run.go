@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
+	"github.com/DylanSharp/dtools/internal/logging"
+)
+
+// ReviewResult summarizes a review run for callers that don't show its thoughts
+type ReviewResult struct {
+	Review     *domain.Review // The last review, with every thought Claude had in it
+	Iterations int            // Reviews that addressed comments or CI failures, counting each batch
+
+	CommentsProcessed  int // Comments sent to Claude or applied as suggestions
+	SuggestionsApplied int // Comments whose committable suggestions were applied locally
+	Resolved           int // Comment threads resolved on GitHub
+	Deferred           int // Comments still left for a later run by MaxComments or the prompt budget
+	CIFailuresFixed    int // CI failures Claude was asked to fix
+
+	// Satisfied is true if there was nothing left to address, or if after the run
+	// both Claude's thoughts and CodeRabbit's comments suggest it's done
+	Satisfied    bool
+	Satisfaction *SatisfactionResult // The check behind Satisfied; nil if no check ran
+
+	// StopReason says why WatchToCompletion stopped, e.g. that MaxIterations was reached
+	StopReason string
+}
+
+// add counts a finished review into the result
+func (r *ReviewResult) add(review *domain.Review) {
+	r.Review = review
+	r.Deferred = review.DeferredCount
+	if review.Status == domain.ReviewStatusSatisfied {
+		return
+	}
+
+	r.Iterations++
+	r.SuggestionsApplied += len(review.AppliedSuggestions)
+	r.Resolved += review.ResolvedCount
+	if review.Status == domain.ReviewStatusCompleted {
+		r.CommentsProcessed += len(review.Comments) + len(review.AppliedSuggestions)
+		r.CIFailuresFixed += len(review.CIFailures)
+	}
+}
+
+// RunReviewToCompletion reviews config.PRNumber without the TUI: it runs StartReview,
+// waits for Claude to finish, works through any comments MaxComments or the prompt
+// budget deferred in further batches, and then checks whether CodeRabbit is
+// satisfied. It doesn't wait for CodeRabbit to re-review; WatchToCompletion does.
+// A run that fails or times out returns the partial result along with the error.
+func (s *ReviewService) RunReviewToCompletion(ctx context.Context, config ReviewConfig) (*ReviewResult, error) {
+	result := &ReviewResult{}
+	for deferred := -1; ; {
+		review, thoughts, err := s.StartReview(ctx, config)
+		if err != nil {
+			if result.Review == nil {
+				return nil, err
+			}
+			return result, err
+		}
+
+		// The review records each thought as it passes through. There's no channel
+		// when there was nothing to address.
+		if thoughts != nil {
+			for range thoughts {
+			}
+		}
+		result.add(review)
+
+		switch {
+		case review.Status == domain.ReviewStatusSatisfied:
+			result.Satisfied = true
+			return result, nil
+		case ctx.Err() != nil:
+			return result, ctx.Err()
+		case review.Status == domain.ReviewStatusFailed:
+			var cause error
+			if n := len(review.Thoughts); n > 0 {
+				cause = errors.New(review.Thoughts[n-1].Content)
+			}
+			return result, domain.ErrClaudeTimeout(cause)
+		}
+
+		// Comments held back from this batch are next, as in the TUI. Stop if a
+		// batch didn't shrink the backlog, e.g. because state couldn't be saved.
+		if review.DeferredCount == 0 || (deferred >= 0 && review.DeferredCount >= deferred) {
+			break
+		}
+		deferred = review.DeferredCount
+		config.ResetState = false
+	}
+
+	satisfaction, err := s.CheckSatisfaction(ctx, result.Review)
+	if err != nil {
+		return result, err
+	}
+	result.Satisfied = satisfaction.IsSatisfied
+	result.Satisfaction = &satisfaction
+	return result, nil
+}
+
+// WatchToCompletion runs watch mode on a PR without the TUI. It addresses comments
+// and CI failures as they arrive, waits for CodeRabbit to re-review each push, and
+// returns once CodeRabbit is satisfied, the PR is closed or opts.MaxIterations
+// reviews have run. Nothing is confirmed interactively. Without MaxIterations it
+// only stops when CodeRabbit is satisfied, so callers should bound ctx.
+func (s *ReviewService) WatchToCompletion(ctx context.Context, prNumber int, opts WatchOptions) (*ReviewResult, error) {
+	opts.RequireManualConfirm = false
+	opts.ConfirmFirstBatch = false
+
+	// The watcher keeps polling after CodeRabbit is satisfied; stop it ourselves
+	watchCtx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	result := &ReviewResult{}
+	for event := range NewWatcher(s, opts).Start(watchCtx, prNumber) {
+		switch event.Type {
+		case WatchEventReviewComplete:
+			result.add(event.Review)
+		case WatchEventError:
+			// Watch mode carries on after a failed poll; so does this
+			logging.Warn("watch poll failed", "pr", prNumber, "message", event.Message, "error", event.Error)
+		case WatchEventSatisfied:
+			satisfaction := event.Satisfied
+			result.Satisfied = true
+			result.Satisfaction = &satisfaction
+			result.StopReason = event.Message
+			stop()
+		case WatchEventPRClosed, WatchEventMaxIterations:
+			result.StopReason = event.Message
+			stop()
+		}
+		if result.Review == nil && event.Review != nil {
+			result.Review = event.Review
+		}
+	}
+
+	if result.StopReason == "" && ctx.Err() != nil {
+		return result, ctx.Err()
+	}
+	return result, nil
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
+)
+
+// isTrivialSuggestion reports whether a comment's suggestion can be applied without Claude:
+// a single suggestion on a real, current comment anchored to lines of one file
+func isTrivialSuggestion(c domain.Comment) bool {
+	return c.HasSuggestion() && c.ID > 0 && c.FilePath != "" && c.LineNumber > 0 && !c.IsOutdated
+}
+
+// applySuggestions applies the committable suggestions of trivial comments to the
+// working tree. It returns the applied comments and the rest, which are left for Claude.
+func (s *ReviewService) applySuggestions(ctx context.Context, comments []domain.Comment) (applied, remaining []domain.Comment) {
+	var candidates []domain.Comment
+	for _, c := range comments {
+		if isTrivialSuggestion(c) {
+			candidates = append(candidates, c)
+		}
+	}
+
+	// Apply bottom-up within each file so earlier edits don't shift later line numbers
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].FilePath != candidates[j].FilePath {
+			return candidates[i].FilePath < candidates[j].FilePath
+		}
+		return candidates[i].LineNumber > candidates[j].LineNumber
+	})
+
+	appliedIDs := make(map[int]bool)
+	lowestApplied := make(map[string]int)
+	for _, c := range candidates {
+		endLine := c.EndLine
+		if endLine < c.LineNumber {
+			endLine = c.LineNumber
+		}
+		// Overlapping suggestions are left for Claude to reconcile
+		if lowest, ok := lowestApplied[c.FilePath]; ok && endLine >= lowest {
+			continue
+		}
+		if err := s.suggestions.ApplySuggestion(ctx, c); err != nil {
+			continue
+		}
+		appliedIDs[c.ID] = true
+		lowestApplied[c.FilePath] = c.LineNumber
+	}
+
+	for _, c := range comments {
+		if appliedIDs[c.ID] {
+			applied = append(applied, c)
+		} else {
+			remaining = append(remaining, c)
+		}
+	}
+	return applied, remaining
+}
+
+// appliedSuggestionsThought summarizes the suggestions applied before Claude was invoked
+func appliedSuggestionsThought(applied []domain.Comment) domain.ThoughtChunk {
+	lines := []string{fmt.Sprintf("Applied %d CodeRabbit suggestion(s) with git apply:", len(applied))}
+	for _, c := range applied {
+		lines = append(lines, "- "+c.Location())
+	}
+	return domain.ThoughtChunk{
+		Timestamp: time.Now(),
+		Content:   strings.Join(lines, "\n"),
+		Type:      domain.ThoughtTypeProgress,
+	}
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
@@ -20,6 +21,9 @@ const (
 	WatchEventCooldown       WatchEventType = "cooldown"
 	WatchEventPolling        WatchEventType = "polling"
 	WatchEventManualConfirm  WatchEventType = "manual_confirm"
+	WatchEventPRClosed       WatchEventType = "pr_closed"
+	WatchEventConfirmStart   WatchEventType = "confirm_start"
+	WatchEventMaxIterations  WatchEventType = "max_iterations"
 )
 
 // WatchEvent represents an event in watch mode
@@ -44,8 +48,16 @@ const (
 	WatchStateCooldown   WatchState = "cooldown"
 	WatchStateSatisfied  WatchState = "satisfied"
 	WatchStateError      WatchState = "error"
+	WatchStateClosed     WatchState = "closed"
+	WatchStateConfirming WatchState = "confirming"
+	WatchStateStopped    WatchState = "stopped" // MaxIterations was reached
 )
 
+// IsFinal returns true once the watcher has stopped for good
+func (s WatchState) IsFinal() bool {
+	return s == WatchStateClosed || s == WatchStateStopped
+}
+
 // Watcher monitors a PR for changes and triggers reviews
 type Watcher struct {
 	service            *ReviewService
@@ -59,16 +71,30 @@ type Watcher struct {
 	processedCIOnce    bool // Have we processed CI failures for this commit?
 	cooldownUntil      time.Time
 	batchWaitUntil     time.Time
+	batchExtensions    int                 // Times the current adaptive batch wait has been extended
+	paused             bool                // Ticker-driven polling is suspended
+	checkNow           chan struct{}       // Requests an immediate poll
+	rerunAt            map[int64]time.Time // When each workflow run was rerun as possibly flaky
+	startApproved      bool                // The first batch was approved; later ones run unattended
+	startDecision      chan bool           // Carries the user's answer to WatchEventConfirmStart
+	continueBatch      bool                // The last review deferred comments; start the next batch right away
+	history            []WatchIteration    // Every iteration of this session, oldest first
+	stateKey           string              // Where the watch checkpoint is saved, once the repo is known
+	iterations         int                 // Claude runs started this session
 	review             *domain.Review
+	reviews            sync.WaitGroup // Reviews still reporting back on the events channel
 }
 
 // NewWatcher creates a new watcher
 func NewWatcher(service *ReviewService, opts WatchOptions) *Watcher {
 	return &Watcher{
-		service:  service,
-		detector: NewSatisfactionDetector(),
-		opts:     opts,
-		state:    WatchStateIdle,
+		service:       service,
+		detector:      NewSatisfactionDetector(),
+		opts:          opts,
+		state:         WatchStateIdle,
+		checkNow:      make(chan struct{}, 1),
+		rerunAt:       make(map[int64]time.Time),
+		startDecision: make(chan bool, 1),
 	}
 }
 
@@ -77,19 +103,36 @@ func (w *Watcher) Start(ctx context.Context, prNumber int) <-chan WatchEvent {
 	events := make(chan WatchEvent, 10)
 
 	go func() {
-		defer close(events)
+		// A finished review still reports back on events, so wait for it before closing
+		defer func() {
+			w.reviews.Wait()
+			close(events)
+		}()
 
 		ticker := time.NewTicker(w.opts.PollInterval)
 		defer ticker.Stop()
 
+		// Resume a previous session's cooldown rather than rerunning Claude straight away
+		if w.restoreCheckpoint(ctx, prNumber) {
+			events <- WatchEvent{
+				Type:      WatchEventPolling,
+				Timestamp: time.Now(),
+				Message:   "Resumed from the previous watch session",
+			}
+		}
+
 		// Initial check
 		w.checkForChanges(ctx, prNumber, events)
 
-		for {
+		for !w.GetState().IsFinal() {
 			select {
 			case <-ctx.Done():
 				return
 			case <-ticker.C:
+				if !w.IsPaused() {
+					w.checkForChanges(ctx, prNumber, events)
+				}
+			case <-w.checkNow:
 				w.checkForChanges(ctx, prNumber, events)
 			}
 		}
@@ -142,11 +185,27 @@ func (w *Watcher) checkForChanges(ctx context.Context, prNumber int, events chan
 
 	// Fetch current review data
 	config := ReviewConfig{
-		PRNumber:        prNumber,
-		IncludeNits:     w.opts.IncludeNits,
-		IncludeOutdated: w.opts.IncludeOutdated,
+		PRNumber:         prNumber,
+		IncludeNits:      w.opts.IncludeNits,
+		IncludeOutdated:  w.opts.IncludeOutdated,
+		WithDiff:         w.opts.WithDiff,
+		MaxDiffMb:        w.opts.MaxDiffMb,
+		MaxPromptKb:      w.opts.MaxPromptKb,
+		IncludeSummary:   w.opts.IncludeSummary,
+		IncludePRContext: w.opts.IncludePRContext,
+		ReplyToDeclined:  w.opts.ReplyToDeclined,
+		Since:            w.opts.Since,
+		ApplySuggestions: w.opts.ApplySuggestions,
+		AllowClosed:      w.opts.AllowClosed,
+		MaxComments:      w.opts.MaxComments,
 	}
 
+	// The rest of a capped review was already waited for and approved
+	w.mu.Lock()
+	continuing := w.continueBatch
+	w.continueBatch = false
+	w.mu.Unlock()
+
 	review, err := w.service.FetchReviewData(ctx, config)
 	if err != nil {
 		events <- WatchEvent{
@@ -158,6 +217,33 @@ func (w *Watcher) checkForChanges(ctx context.Context, prNumber int, events chan
 		return
 	}
 
+	// A merged or closed PR won't get new comments - stop watching
+	if review.IsClosed() && !w.opts.AllowClosed {
+		w.mu.Lock()
+		w.state = WatchStateClosed
+		w.mu.Unlock()
+		w.recordIteration(newIteration(prNumber, WatchEventPRClosed, review), IterationClosed, nil)
+		events <- WatchEvent{
+			Type:      WatchEventPRClosed,
+			Review:    review,
+			Timestamp: time.Now(),
+			Message:   fmt.Sprintf("PR #%d is %s, stopped watching", prNumber, review.PRStateLabel()),
+		}
+		return
+	}
+
+	// Give failed CI checks one rerun before treating them as real failures
+	if w.opts.RerunFailedCI {
+		if rerun := w.rerunFlakyFailures(ctx, review); rerun > 0 {
+			events <- WatchEvent{
+				Type:      WatchEventPolling,
+				Review:    review,
+				Timestamp: time.Now(),
+				Message:   fmt.Sprintf("Re-running %d failed workflow run(s) in case they're flaky", rerun),
+			}
+		}
+	}
+
 	// Check for new comments
 	newComments := len(review.Comments) > w.lastCommentCount
 	newCommit := review.HeadCommit != w.lastCommitSHA
@@ -223,6 +309,7 @@ func (w *Watcher) checkForChanges(ctx context.Context, prNumber int, events chan
 		eventType = WatchEventNewCIFailures
 		w.processedCIOnce = true
 	}
+	w.saveCheckpoint()
 
 	if !needsProcessing {
 		// Nothing to do - send a polling event so UI knows we're still checking
@@ -235,11 +322,30 @@ func (w *Watcher) checkForChanges(ctx context.Context, prNumber int, events chan
 		return
 	}
 
+	// Stop rather than loop forever if Claude and CodeRabbit keep disagreeing
+	w.mu.Lock()
+	limitReached := w.opts.MaxIterations > 0 && w.iterations >= w.opts.MaxIterations
+	if limitReached {
+		w.state = WatchStateStopped
+	}
+	w.mu.Unlock()
+	if limitReached {
+		events <- WatchEvent{
+			Type:      WatchEventMaxIterations,
+			Review:    review,
+			Timestamp: time.Now(),
+			Message: fmt.Sprintf("Stopped after %d iteration(s) without CodeRabbit being satisfied: %d comment(s) and %d CI failure(s) unresolved",
+				w.opts.MaxIterations, len(review.Comments), len(review.CIFailures)),
+		}
+		return
+	}
+
 	// Batch wait - let more comments roll in before processing
-	if w.opts.BatchWaitDuration > 0 {
+	if w.opts.BatchWaitDuration > 0 && !continuing {
 		w.mu.Lock()
 		w.state = WatchStateBatchWait
 		w.batchWaitUntil = time.Now().Add(w.opts.BatchWaitDuration)
+		w.batchExtensions = 0
 		w.mu.Unlock()
 
 		events <- WatchEvent{
@@ -249,16 +355,11 @@ func (w *Watcher) checkForChanges(ctx context.Context, prNumber int, events chan
 			Message:   "Waiting for more comments to arrive...",
 		}
 
-		// Wait for batch duration
-		select {
-		case <-ctx.Done():
-			return
-		case <-time.After(w.opts.BatchWaitDuration):
-		}
-
-		// Re-fetch to get any new comments that came in during batch wait
-		review, err = w.service.FetchReviewData(ctx, config)
+		review, err = w.batchWait(ctx, config, len(review.Comments))
 		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
 			events <- WatchEvent{
 				Type:      WatchEventError,
 				Error:     err,
@@ -268,8 +369,30 @@ func (w *Watcher) checkForChanges(ctx context.Context, prNumber int, events chan
 			return
 		}
 
+		if w.opts.RerunFailedCI {
+			w.rerunFlakyFailures(ctx, review)
+		}
+
 		// Update tracking with new count
 		w.lastCommentCount = len(review.Comments)
+		w.saveCheckpoint()
+	}
+
+	// Show the first batch and wait for the go-ahead before Claude starts editing
+	if w.opts.ConfirmFirstBatch && !w.startApproved {
+		// Show only what the capped review will address
+		review.Comments, review.DeferredCount = capComments(review.Comments, w.opts.MaxComments)
+		if !w.awaitStartApproval(ctx, review, events) {
+			if ctx.Err() == nil {
+				w.recordIteration(newIteration(prNumber, eventType, review), IterationDeclined, nil)
+			}
+			return
+		}
+	}
+
+	// Don't send Claude failures that are still being rerun
+	if w.opts.RerunFailedCI {
+		config.RerunRunIDs = w.pendingReruns()
 	}
 
 	// Start processing (thread-safe)
@@ -281,6 +404,7 @@ func (w *Watcher) checkForChanges(ctx context.Context, prNumber int, events chan
 	// Start the actual review
 	review, thoughts, err := w.service.StartReview(ctx, config)
 	if err != nil {
+		w.recordIteration(newIteration(prNumber, eventType, w.review), IterationStartFailed, err)
 		events <- WatchEvent{
 			Type:      WatchEventError,
 			Error:     err,
@@ -293,6 +417,11 @@ func (w *Watcher) checkForChanges(ctx context.Context, prNumber int, events chan
 		return
 	}
 
+	iteration := w.beginIteration(newIteration(prNumber, eventType, review))
+	w.mu.Lock()
+	w.iterations++
+	w.mu.Unlock()
+
 	// Emit event with thoughts channel
 	events <- WatchEvent{
 		Type:      eventType,
@@ -303,7 +432,9 @@ func (w *Watcher) checkForChanges(ctx context.Context, prNumber int, events chan
 	}
 
 	// Wait for review to complete in background
+	w.reviews.Add(1)
 	go func() {
+		defer w.reviews.Done()
 		// Handle nil thoughts channel (review already satisfied)
 		if thoughts == nil {
 			goto done
@@ -323,6 +454,11 @@ func (w *Watcher) checkForChanges(ctx context.Context, prNumber int, events chan
 			}
 		}
 	done:
+		outcome := IterationCompleted
+		if review.Status == domain.ReviewStatusFailed {
+			outcome = IterationFailed
+		}
+		w.finishIteration(iteration, outcome, nil)
 
 		// Review complete
 		events <- WatchEvent{
@@ -332,11 +468,29 @@ func (w *Watcher) checkForChanges(ctx context.Context, prNumber int, events chan
 			Message:   "Review iteration complete",
 		}
 
+		// Comments held back by MaxComments are next, without a cooldown
+		if review.DeferredCount > 0 && review.Status == domain.ReviewStatusCompleted {
+			w.mu.Lock()
+			w.state = WatchStatePolling
+			w.continueBatch = true
+			w.mu.Unlock()
+
+			events <- WatchEvent{
+				Type:      WatchEventPolling,
+				Review:    review,
+				Timestamp: time.Now(),
+				Message:   fmt.Sprintf("Batch complete, starting the next one (%d comment(s) left)", review.DeferredCount),
+			}
+			w.CheckNow()
+			return
+		}
+
 		// Enter cooldown (thread-safe)
 		w.mu.Lock()
 		w.state = WatchStateCooldown
 		w.cooldownUntil = time.Now().Add(w.opts.CooldownDuration)
 		w.mu.Unlock()
+		w.saveCheckpoint()
 
 		events <- WatchEvent{
 			Type:      WatchEventCooldown,
@@ -346,6 +500,109 @@ func (w *Watcher) checkForChanges(ctx context.Context, prNumber int, events chan
 	}()
 }
 
+// adaptiveBatchStep is the first extension of an adaptive batch wait; later ones double
+const adaptiveBatchStep = 10 * time.Second
+
+// batchWait waits BatchWaitDuration, then re-fetches the review. In adaptive mode it
+// keeps extending the wait by a growing interval while the comment count is still
+// rising, up to BatchWaitMax, and returns once the count holds steady for an interval.
+func (w *Watcher) batchWait(ctx context.Context, config ReviewConfig, count int) (*domain.Review, error) {
+	deadline := time.Now().Add(w.opts.BatchWaitMax)
+	wait := w.opts.BatchWaitDuration
+	step := adaptiveBatchStep
+
+	for {
+		w.mu.Lock()
+		w.state = WatchStateBatchWait
+		w.batchWaitUntil = time.Now().Add(wait)
+		w.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+
+		// Re-fetch to get any new comments that came in during the wait
+		review, err := w.service.FetchReviewData(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+
+		// Stop once comments have stopped arriving or the cap is reached
+		remaining := time.Until(deadline)
+		if !w.opts.AdaptiveBatchWait || len(review.Comments) <= count || remaining <= 0 {
+			return review, nil
+		}
+
+		count = len(review.Comments)
+		wait = step
+		if wait > remaining {
+			wait = remaining
+		}
+		step *= 2
+
+		w.mu.Lock()
+		w.batchExtensions++
+		w.mu.Unlock()
+	}
+}
+
+// awaitStartApproval emits WatchEventConfirmStart for the batch about to be
+// processed and blocks until the user answers. It returns true if approved;
+// a rejected batch pauses polling so it isn't offered again straight away.
+func (w *Watcher) awaitStartApproval(ctx context.Context, review *domain.Review, events chan<- WatchEvent) bool {
+	w.mu.Lock()
+	w.state = WatchStateConfirming
+	w.mu.Unlock()
+
+	events <- WatchEvent{
+		Type:      WatchEventConfirmStart,
+		Review:    review,
+		Timestamp: time.Now(),
+		Message:   "Confirm to let Claude address these items",
+	}
+
+	var approved bool
+	select {
+	case <-ctx.Done():
+		return false
+	case approved = <-w.startDecision:
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if approved {
+		w.startApproved = true
+		return true
+	}
+	w.state = WatchStatePolling
+	w.paused = true
+	// Offer the CI failures again along with the comments
+	w.processedCIOnce = false
+	return false
+}
+
+// ApproveStart lets Claude address the batch shown by WatchEventConfirmStart.
+// Later batches are processed without asking.
+func (w *Watcher) ApproveStart() {
+	w.answerStart(true)
+}
+
+// RejectStart declines the batch shown by WatchEventConfirmStart and pauses
+// polling; resuming or checking now offers the next batch again
+func (w *Watcher) RejectStart() {
+	w.answerStart(false)
+}
+
+func (w *Watcher) answerStart(approved bool) {
+	select {
+	case w.startDecision <- approved:
+	default:
+		// Already answered
+	}
+}
+
 // ConfirmSatisfied manually confirms that the review is satisfied
 func (w *Watcher) ConfirmSatisfied() {
 	w.mu.Lock()
@@ -360,6 +617,44 @@ func (w *Watcher) RejectSatisfied() {
 	w.state = WatchStatePolling
 }
 
+// Pause suspends ticker-driven polling, e.g. while pushing commits by hand.
+// A review already in progress carries on.
+func (w *Watcher) Pause() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.paused = true
+}
+
+// Resume restarts ticker-driven polling
+func (w *Watcher) Resume() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.paused = false
+}
+
+// IsPaused returns true if polling is paused
+func (w *Watcher) IsPaused() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.paused
+}
+
+// CheckNow polls immediately instead of waiting for the next interval, ending any
+// cooldown. It works while paused too, without resuming.
+func (w *Watcher) CheckNow() {
+	w.mu.Lock()
+	if w.state == WatchStateCooldown {
+		w.cooldownUntil = time.Now()
+	}
+	w.mu.Unlock()
+
+	select {
+	case w.checkNow <- struct{}{}:
+	default:
+		// A check is already pending
+	}
+}
+
 // GetState returns the current watcher state
 func (w *Watcher) GetState() WatchState {
 	w.mu.Lock()
@@ -367,6 +662,14 @@ func (w *Watcher) GetState() WatchState {
 	return w.state
 }
 
+// GetIterations returns how many times Claude has run this session, and the
+// MaxIterations limit (0 for none)
+func (w *Watcher) GetIterations() (count, max int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.iterations, w.opts.MaxIterations
+}
+
 // GetCooldownRemaining returns the time remaining in cooldown
 func (w *Watcher) GetCooldownRemaining() time.Duration {
 	w.mu.Lock()
@@ -394,3 +697,14 @@ func (w *Watcher) GetBatchWaitRemaining() time.Duration {
 	}
 	return remaining
 }
+
+// GetBatchWaitExtensions returns how many times the current batch wait was extended
+// because comments were still arriving
+func (w *Watcher) GetBatchWaitExtensions() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.state != WatchStateBatchWait {
+		return 0
+	}
+	return w.batchExtensions
+}
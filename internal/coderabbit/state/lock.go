@@ -0,0 +1,38 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// fileLock is an exclusive OS-level lock held on a sidecar ".lock" file,
+// so concurrent dtools processes serialize their read-modify-write cycles
+type fileLock struct {
+	file *os.File
+}
+
+// lockFile acquires an exclusive lock for path, blocking until it is available
+func lockFile(path string) (*fileLock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	if err := lockExclusive(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock state file: %w", err)
+	}
+
+	return &fileLock{file: f}, nil
+}
+
+// Unlock releases the lock
+func (l *fileLock) Unlock() {
+	_ = unlock(l.file)
+	l.file.Close()
+}
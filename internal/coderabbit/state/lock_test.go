@@ -0,0 +1,80 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
+	"github.com/DylanSharp/dtools/internal/statedir"
+)
+
+const (
+	writerEnv      = "DTOOLS_TEST_STATE_WRITER"
+	writers        = 4
+	marksPerWriter = 25
+	testKey        = "owner/repo#1"
+)
+
+// TestMain lets the test binary act as one of several state writers
+func TestMain(m *testing.M) {
+	if writer := os.Getenv(writerEnv); writer != "" {
+		n, _ := strconv.Atoi(writer)
+		for i := 0; i < marksPerWriter; i++ {
+			id := n*marksPerWriter + i + 1
+			comment := domain.Comment{ID: id, Body: fmt.Sprintf("comment %d", id)}
+			if err := MarkProcessed(testKey, []domain.Comment{comment}, ""); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestConcurrentWritersKeepEveryUpdate(t *testing.T) {
+	dir := t.TempDir()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for n := 0; n < writers; n++ {
+		cmd := exec.Command(os.Args[0], "-test.run=^$")
+		cmd.Env = append(os.Environ(), writerEnv+"="+strconv.Itoa(n), statedir.EnvVar+"="+dir)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if out, err := cmd.CombinedOutput(); err != nil {
+				errs <- fmt.Errorf("writer failed: %v\n%s", err, out)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	data, err := loadFile(filepath.Join(dir, "review-state", "owner-repo.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := data[testKey]
+	if st == nil {
+		t.Fatalf("no state saved for %s", testKey)
+	}
+
+	processed := make(map[int]bool)
+	for _, id := range st.ProcessedCommentIDs {
+		processed[id] = true
+	}
+	for id := 1; id <= writers*marksPerWriter; id++ {
+		if !processed[id] {
+			t.Errorf("comment %d was marked processed but is missing from the state file", id)
+		}
+	}
+}
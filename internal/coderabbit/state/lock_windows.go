@@ -0,0 +1,20 @@
+//go:build windows
+
+package state
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockExclusive blocks until it holds an exclusive lock on f
+func lockExclusive(f *os.File) error {
+	// Lock the first byte; every process locks the same range, so it acts as a whole-file lock
+	return windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK, 0, 1, 0, new(windows.Overlapped))
+}
+
+// unlock releases the lock on f
+func unlock(f *os.File) error {
+	return windows.UnlockFileEx(windows.Handle(f.Fd()), 0, 1, 0, new(windows.Overlapped))
+}
@@ -7,23 +7,47 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
+	"github.com/DylanSharp/dtools/internal/statedir"
 )
 
 var (
-	stateDir  = filepath.Join(os.Getenv("HOME"), ".config", "dtools")
-	stateFile = filepath.Join(stateDir, "review-state.json")
-	mu        sync.Mutex
+	mu          sync.Mutex
+	migrateOnce sync.Once
 )
 
+// shardDir holds one state file per repository. It's resolved on each use so
+// $DTOOLS_STATE_DIR set after startup, e.g. by a program embedding dtools, applies.
+func shardDir() string {
+	return filepath.Join(statedir.Dir(), "review-state")
+}
+
+// legacyStateFile is the old single state file shared by all repositories
+func legacyStateFile() string {
+	return filepath.Join(statedir.Dir(), "review-state.json")
+}
+
 // TrackerState holds the state for a single PR
 type TrackerState struct {
-	ProcessedCommentIDs []int              `json:"processedCommentIds"`
-	ProcessedByHash     []string           `json:"processedByHash"`
-	SeenComments        map[int]SeenInfo   `json:"seenComments"`
-	LastReviewTimestamp string             `json:"lastProcessedReviewSubmittedAt,omitempty"`
+	ProcessedCommentIDs []int            `json:"processedCommentIds"`
+	ProcessedByHash     []string         `json:"processedByHash"`
+	SeenComments        map[int]SeenInfo `json:"seenComments"`
+	LastReviewTimestamp string           `json:"lastProcessedReviewSubmittedAt,omitempty"`
+	Watch               *WatchCheckpoint `json:"watch,omitempty"`
+}
+
+// WatchCheckpoint is what watch mode has seen of a PR, so a restarted
+// session doesn't reprocess it or skip the cooldown
+type WatchCheckpoint struct {
+	LastCommitSHA      string    `json:"lastCommitSha"`
+	LastCommentCount   int       `json:"lastCommentCount"`
+	LastCIFailureCount int       `json:"lastCIFailureCount"`
+	ProcessedCIOnce    bool      `json:"processedCIOnce,omitempty"`
+	CooldownUntil      time.Time `json:"cooldownUntil,omitempty"`
 }
 
 // SeenInfo tracks when we last saw a comment and its content hash
@@ -32,7 +56,7 @@ type SeenInfo struct {
 	BodyHash  string `json:"bodyHash"`
 }
 
-// TrackerData is the full state file containing all PRs
+// TrackerData is a state file containing all PRs of a repository
 type TrackerData map[string]*TrackerState
 
 // HashComment creates a unique hash for a comment based on file, line, and body
@@ -50,16 +74,96 @@ func GetStateKey(owner, repo string, pr int) string {
 	return fmt.Sprintf("%s/%s#%d", owner, repo, pr)
 }
 
-// Load reads the state file from disk
-func Load() (TrackerData, error) {
+// ParseStateKey splits a state key back into owner, repo and PR number
+func ParseStateKey(key string) (owner, repo string, pr int, ok bool) {
+	hashIdx := strings.LastIndex(key, "#")
+	if hashIdx == -1 {
+		return "", "", 0, false
+	}
+	if _, err := fmt.Sscanf(key[hashIdx+1:], "%d", &pr); err != nil {
+		return "", "", 0, false
+	}
+
+	parts := strings.SplitN(key[:hashIdx], "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", 0, false
+	}
+
+	return parts[0], parts[1], pr, true
+}
+
+// shardPath returns the per-repo state file for a state key
+func shardPath(key string) string {
+	owner, repo, _, ok := ParseStateKey(key)
+	if !ok {
+		return filepath.Join(shardDir(), "unknown.json")
+	}
+	return filepath.Join(shardDir(), fmt.Sprintf("%s-%s.json", owner, repo))
+}
+
+// Load reads the state file for the repository of the given key
+func Load(key string) (TrackerData, error) {
+	migrateLegacy()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	path := shardPath(key)
+	lock, err := lockFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	return loadFile(path)
+}
+
+// Save writes the state file for the repository of the given key
+func Save(key string, data TrackerData) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	path := shardPath(key)
+	lock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	return saveFile(path, data)
+}
+
+// update runs a read-modify-write cycle on the state file for the key's repository.
+// The file is locked for the whole cycle so concurrent processes don't lose writes.
+func update(key string, fn func(data TrackerData)) error {
+	migrateLegacy()
+
 	mu.Lock()
 	defer mu.Unlock()
 
-	if _, err := os.Stat(stateFile); os.IsNotExist(err) {
+	path := shardPath(key)
+	lock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	data, err := loadFile(path)
+	if err != nil {
+		return err
+	}
+
+	fn(data)
+	return saveFile(path, data)
+}
+
+// loadFile reads a state file, returning empty data if it doesn't exist
+func loadFile(path string) (TrackerData, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return make(TrackerData), nil
 	}
 
-	data, err := os.ReadFile(stateFile)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read state file: %w", err)
 	}
@@ -68,16 +172,17 @@ func Load() (TrackerData, error) {
 	if err := json.Unmarshal(data, &state); err != nil {
 		return nil, fmt.Errorf("failed to parse state file: %w", err)
 	}
+	if state == nil {
+		state = make(TrackerData)
+	}
 
 	return state, nil
 }
 
-// Save writes the state file to disk
-func Save(data TrackerData) error {
-	mu.Lock()
-	defer mu.Unlock()
-
-	if err := os.MkdirAll(stateDir, 0755); err != nil {
+// saveFile atomically writes a state file via a temp file and rename,
+// creating its directory if needed
+func saveFile(path string, data TrackerData) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return fmt.Errorf("failed to create state directory: %w", err)
 	}
 
@@ -86,16 +191,95 @@ func Save(data TrackerData) error {
 		return fmt.Errorf("failed to marshal state: %w", err)
 	}
 
-	if err := os.WriteFile(stateFile, content, 0644); err != nil {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
 		return fmt.Errorf("failed to write state file: %w", err)
 	}
 
 	return nil
 }
 
+// migrateLegacy splits the old single state file into per-repo shards.
+// Existing shard entries take precedence; the legacy file is renamed once migrated.
+func migrateLegacy() {
+	migrateOnce.Do(func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// State kept in ~/.config/dtools before XDG_CONFIG_HOME was respected
+		statedir.MigrateLegacy("review-state")
+		statedir.MigrateLegacy("review-state.json")
+
+		legacyFile := legacyStateFile()
+		legacyLock, err := lockFile(legacyFile)
+		if err != nil {
+			return
+		}
+		defer legacyLock.Unlock()
+
+		legacy, err := loadFile(legacyFile)
+		if err != nil || len(legacy) == 0 {
+			return
+		}
+
+		byShard := make(map[string]TrackerData)
+		for key, entry := range legacy {
+			path := shardPath(key)
+			if byShard[path] == nil {
+				byShard[path] = make(TrackerData)
+			}
+			byShard[path][key] = entry
+		}
+
+		for path, entries := range byShard {
+			if err := mergeIntoShard(path, entries); err != nil {
+				return
+			}
+		}
+
+		_ = os.Rename(legacyFile, legacyFile+".migrated")
+	})
+}
+
+// mergeIntoShard adds entries missing from a shard file under its lock
+func mergeIntoShard(path string, entries TrackerData) error {
+	lock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	existing, err := loadFile(path)
+	if err != nil {
+		return err
+	}
+	for key, entry := range entries {
+		if existing[key] == nil {
+			existing[key] = entry
+		}
+	}
+	return saveFile(path, existing)
+}
+
 // GetOrCreate returns the state for a PR, creating it if it doesn't exist
 func GetOrCreate(key string) (*TrackerState, error) {
-	data, err := Load()
+	data, err := Load(key)
 	if err != nil {
 		return nil, err
 	}
@@ -155,71 +339,157 @@ func HasCommentChanged(state *TrackerState, comment domain.Comment) bool {
 
 // MarkProcessed marks comments as processed and saves state
 func MarkProcessed(key string, comments []domain.Comment, reviewTimestamp string) error {
-	data, err := Load()
-	if err != nil {
-		return err
-	}
-
-	state := data[key]
-	if state == nil {
-		state = &TrackerState{
-			ProcessedCommentIDs: []int{},
-			ProcessedByHash:     []string{},
-			SeenComments:        make(map[int]SeenInfo),
+	return update(key, func(data TrackerData) {
+		state := data[key]
+		if state == nil {
+			state = &TrackerState{
+				ProcessedCommentIDs: []int{},
+				ProcessedByHash:     []string{},
+				SeenComments:        make(map[int]SeenInfo),
+			}
+			data[key] = state
 		}
-		data[key] = state
-	}
 
-	for _, comment := range comments {
-		hash := HashComment(comment.FilePath, comment.LineNumber, comment.Body)
-
-		// Add ID if not already present
-		found := false
-		for _, id := range state.ProcessedCommentIDs {
-			if id == comment.ID {
-				found = true
-				break
+		for _, comment := range comments {
+			hash := HashComment(comment.FilePath, comment.LineNumber, comment.Body)
+
+			// Add ID if not already present
+			found := false
+			for _, id := range state.ProcessedCommentIDs {
+				if id == comment.ID {
+					found = true
+					break
+				}
+			}
+			if !found {
+				state.ProcessedCommentIDs = append(state.ProcessedCommentIDs, comment.ID)
+			}
+
+			// Add hash if not already present
+			found = false
+			for _, h := range state.ProcessedByHash {
+				if h == hash {
+					found = true
+					break
+				}
+			}
+			if !found {
+				state.ProcessedByHash = append(state.ProcessedByHash, hash)
+			}
+
+			// Update seen info
+			state.SeenComments[comment.ID] = SeenInfo{
+				UpdatedAt: comment.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+				BodyHash:  hash,
 			}
 		}
-		if !found {
-			state.ProcessedCommentIDs = append(state.ProcessedCommentIDs, comment.ID)
+
+		if reviewTimestamp != "" {
+			state.LastReviewTimestamp = reviewTimestamp
 		}
 
-		// Add hash if not already present
-		found = false
-		for _, h := range state.ProcessedByHash {
-			if h == hash {
-				found = true
-				break
+	})
+}
+
+// LoadWatch returns the watch checkpoint saved for a PR, or nil if there isn't one
+func LoadWatch(key string) (*WatchCheckpoint, error) {
+	data, err := Load(key)
+	if err != nil {
+		return nil, err
+	}
+	if data[key] == nil {
+		return nil, nil
+	}
+	return data[key].Watch, nil
+}
+
+// SaveWatch saves a PR's watch checkpoint
+func SaveWatch(key string, checkpoint WatchCheckpoint) error {
+	return update(key, func(data TrackerData) {
+		state := data[key]
+		if state == nil {
+			state = &TrackerState{
+				ProcessedCommentIDs: []int{},
+				ProcessedByHash:     []string{},
+				SeenComments:        make(map[int]SeenInfo),
 			}
+			data[key] = state
 		}
-		if !found {
-			state.ProcessedByHash = append(state.ProcessedByHash, hash)
-		}
+		state.Watch = &checkpoint
+	})
+}
+
+// Reset clears the state for a PR
+func Reset(key string) error {
+	return update(key, func(data TrackerData) {
+		delete(data, key)
+	})
+}
 
-		// Update seen info
-		state.SeenComments[comment.ID] = SeenInfo{
-			UpdatedAt: comment.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			BodyHash:  hash,
+// Keys returns the state keys of all tracked PRs across all repositories
+func Keys() ([]string, error) {
+	migrateLegacy()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	files, err := filepath.Glob(filepath.Join(shardDir(), "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list state files: %w", err)
+	}
+
+	var keys []string
+	for _, file := range files {
+		data, err := loadLocked(file)
+		if err != nil {
+			continue // Skip corrupt shards
+		}
+		for key := range data {
+			keys = append(keys, key)
 		}
 	}
 
-	if reviewTimestamp != "" {
-		state.LastReviewTimestamp = reviewTimestamp
+	return keys, nil
+}
+
+// loadLocked reads a state file while holding its lock
+func loadLocked(path string) (TrackerData, error) {
+	lock, err := lockFile(path)
+	if err != nil {
+		return nil, err
 	}
+	defer lock.Unlock()
 
-	return Save(data)
+	return loadFile(path)
 }
 
-// Reset clears the state for a PR
-func Reset(key string) error {
-	data, err := Load()
+// Prune drops the state of every PR for which keep returns false and
+// returns the keys that were removed. PRs whose status can't be determined are kept.
+func Prune(keep func(owner, repo string, pr int) (bool, error)) ([]string, error) {
+	keys, err := Keys()
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	var pruned []string
+	for _, key := range keys {
+		owner, repo, pr, ok := ParseStateKey(key)
+		if !ok {
+			continue
+		}
+
+		shouldKeep, err := keep(owner, repo, pr)
+		if err != nil || shouldKeep {
+			continue
+		}
+
+		if err := Reset(key); err != nil {
+			return pruned, err
+		}
+		pruned = append(pruned, key)
 	}
 
-	delete(data, key)
-	return Save(data)
+	return pruned, nil
 }
 
 // FilterUnprocessed returns only comments that haven't been processed yet
@@ -0,0 +1,153 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
+	"github.com/DylanSharp/dtools/internal/coderabbit/ports"
+	dtui "github.com/DylanSharp/dtools/internal/ui"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// renderCIPanel renders the CI failure details and workflow runs in place of the thoughts viewport
+func renderCIPanel(failures []domain.CITestFailure, runs []ports.WorkflowRun, note string, width, height, scrollOffset int) string {
+	return renderPanelWindow(renderCIPanelLines(failures, runs, note, width), height, scrollOffset)
+}
+
+// renderCIPanelLines renders each CI failure with its annotations, followed by the
+// PR's workflow runs, one entry per line
+func renderCIPanelLines(failures []domain.CITestFailure, runs []ports.WorkflowRun, note string, width int) []string {
+	var lines []string
+	if note != "" {
+		lines = append(lines, InfoStyle.Render(dtui.TruncateWidth(note, width)), "")
+	}
+	if len(failures) == 0 {
+		lines = append(lines, DimStyle.Render("✓ No CI failures"), "")
+		return append(lines, renderWorkflowRunLines(runs, width)...)
+	}
+
+	lines = append(lines, BoldStyle.Render(fmt.Sprintf("CI failures (%d)", len(failures))), "")
+	bodyWidth := width - 4
+
+	for _, failure := range failures {
+		title := failure.CheckName
+		if failure.JobName != "" && failure.JobName != failure.CheckName {
+			title += " / " + failure.JobName
+		}
+		if failure.AppName != "" {
+			title += DimStyle.Render(" (" + failure.AppName + ")")
+		}
+		lines = append(lines, ErrorStyle.Render("✗ ")+dtui.TruncateWidth(title, width-2))
+
+		if summary := strings.TrimSpace(failure.Summary); summary != "" {
+			lines = append(lines, indentLines(wordWrap(summary, bodyWidth), "  ")...)
+		} else if msg := strings.TrimSpace(failure.ErrorMessage); msg != "" {
+			lines = append(lines, indentLines(wordWrap(msg, bodyWidth), "  ")...)
+		}
+
+		for _, annotation := range failure.Annotations {
+			lines = append(lines, "  "+FileReferenceStyle.Render(annotationLocation(annotation)))
+			message := strings.TrimSpace(annotation.Message)
+			if annotation.Title != "" {
+				message = strings.TrimSpace(annotation.Title + ": " + message)
+			}
+			if message != "" {
+				lines = append(lines, indentLines(wordWrap(message, bodyWidth-2), "    ")...)
+			}
+		}
+
+		if failure.LogURL != "" {
+			lines = append(lines, "  "+DimStyle.Render(dtui.TruncateWidth(failure.LogURL, bodyWidth)))
+		}
+		lines = append(lines, "")
+	}
+
+	return append(lines, renderWorkflowRunLines(runs, width)...)
+}
+
+// renderWorkflowRunLines lists the PR's checks with their workflow and outcome
+func renderWorkflowRunLines(runs []ports.WorkflowRun, width int) []string {
+	if len(runs) == 0 {
+		return nil
+	}
+
+	lines := []string{BoldStyle.Render(fmt.Sprintf("Workflow runs (%d)", len(runs)))}
+	for _, run := range runs {
+		var icon string
+		switch {
+		case run.IsFailed():
+			icon = ErrorStyle.Render("✗")
+		case run.Status != "completed":
+			icon = WarnStyle.Render("◐")
+		case run.Conclusion == "success":
+			icon = SuccessStyle.Render("✓")
+		default:
+			icon = DimStyle.Render("○")
+		}
+
+		title := run.Name
+		if run.Workflow != "" && run.Workflow != run.Name {
+			title = run.Workflow + " / " + run.Name
+		}
+		outcome := run.Conclusion
+		if run.Status != "completed" {
+			outcome = strings.ReplaceAll(run.Status, "_", " ")
+		}
+		if outcome != "" {
+			title += DimStyle.Render(" (" + outcome + ")")
+		}
+		lines = append(lines, icon+" "+dtui.TruncateWidth(title, width-2))
+	}
+
+	return lines
+}
+
+// annotationLocation formats an annotation as path:L10 or path:L10-12
+func annotationLocation(annotation domain.CIAnnotation) string {
+	if annotation.StartLine == 0 {
+		return annotation.Path
+	}
+	if annotation.EndLine > annotation.StartLine {
+		return fmt.Sprintf("%s:L%d-%d", annotation.Path, annotation.StartLine, annotation.EndLine)
+	}
+	return fmt.Sprintf("%s:L%d", annotation.Path, annotation.StartLine)
+}
+
+// indentLines splits wrapped text into lines with the given prefix,
+// replacing wordWrap's hanging indent so continuation lines line up
+func indentLines(text, prefix string) []string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = prefix + strings.TrimLeft(line, " ")
+	}
+	return lines
+}
+
+// ciFailures returns the current review's CI failures
+func (m *Model) ciFailures() []domain.CITestFailure {
+	if m.review == nil {
+		return nil
+	}
+	return m.review.CIFailures
+}
+
+// maxCIScrollOffset returns the scroll offset that shows the end of the CI panel
+func (m *Model) maxCIScrollOffset() int {
+	return panelScrollLimit(renderCIPanelLines(m.ciFailures(), m.workflowRuns, m.ciNote, m.width), m.viewportHeight())
+}
+
+// handleCIPanelKey handles scrolling, reruns and closing while the CI panel is open.
+// It returns false for keys the panel doesn't use.
+func (m *Model) handleCIPanelKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	switch msg.String() {
+	case "c", "C", "esc":
+		m.showCI = false
+		return true, nil
+
+	case "r", "R":
+		m.ciNote = "Rerunning failed workflow runs..."
+		return true, m.rerunWorkflowsCmd()
+	}
+	return scrollPanel(msg.String(), &m.ciScrollOffset, m.maxCIScrollOffset()), nil
+}
@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DylanSharp/dtools/internal/coderabbit/service"
+	dtui "github.com/DylanSharp/dtools/internal/ui"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// renderHistoryPanel renders the watch session's iterations in place of the thoughts viewport
+func renderHistoryPanel(history []service.WatchIteration, width, height, scrollOffset int) string {
+	return renderPanelWindow(renderHistoryLines(history, width), height, scrollOffset)
+}
+
+// renderHistoryLines renders one entry per iteration, newest last, with what
+// triggered it on the line below
+func renderHistoryLines(history []service.WatchIteration, width int) []string {
+	if len(history) == 0 {
+		return []string{DimStyle.Render("No iterations yet")}
+	}
+
+	lines := []string{BoldStyle.Render(fmt.Sprintf("Watch history (%d)", len(history))), ""}
+	for _, it := range history {
+		var icon string
+		switch it.Outcome {
+		case service.IterationCompleted:
+			icon = SuccessStyle.Render("✓")
+		case service.IterationRunning:
+			icon = WarnStyle.Render("◐")
+		case service.IterationFailed, service.IterationStartFailed:
+			icon = ErrorStyle.Render("✗")
+		default:
+			icon = DimStyle.Render("○")
+		}
+
+		title := fmt.Sprintf("#%d %s", it.Number, it.Started.Format("15:04:05"))
+		if len(it.Commit) >= 7 {
+			title += " " + FileReferenceStyle.Render(it.Commit[:7])
+		}
+		title += " " + iterationCounts(it)
+
+		outcome := strings.ReplaceAll(string(it.Outcome), "_", " ")
+		if d := it.Duration(); d > 0 && it.Outcome != service.IterationClosed && it.Outcome != service.IterationDeclined {
+			outcome += " in " + d.Round(time.Second).String()
+		}
+		title += DimStyle.Render(" (" + outcome + ")")
+		lines = append(lines, icon+" "+dtui.TruncateWidth(title, width-2))
+
+		detail := "triggered by " + triggerLabel(it.Trigger)
+		if it.Error != "" {
+			detail += ": " + it.Error
+		}
+		lines = append(lines, "  "+DimStyle.Render(dtui.TruncateWidth(detail, width-4)))
+	}
+
+	return lines
+}
+
+// triggerLabel describes what started an iteration
+func triggerLabel(trigger service.WatchEventType) string {
+	switch trigger {
+	case service.WatchEventNewComments:
+		return "comments to address"
+	case service.WatchEventNewCIFailures:
+		return "CI failures"
+	case service.WatchEventPRClosed:
+		return "the PR closing"
+	default:
+		return strings.ReplaceAll(string(trigger), "_", " ")
+	}
+}
+
+// iterationCounts summarizes the comments and CI failures an iteration covered
+func iterationCounts(it service.WatchIteration) string {
+	counts := fmt.Sprintf("%d comment(s)", it.Comments)
+	if it.Deferred > 0 {
+		counts += fmt.Sprintf(" +%d deferred", it.Deferred)
+	}
+	if it.CIFailures > 0 {
+		counts += fmt.Sprintf(", %d CI failure(s)", it.CIFailures)
+	}
+	return counts
+}
+
+// watchHistory returns the watcher's iterations, or nil outside watch mode
+func (m *Model) watchHistory() []service.WatchIteration {
+	if m.watcher == nil {
+		return nil
+	}
+	return m.watcher.History()
+}
+
+// maxHistoryScrollOffset returns the scroll offset that shows the end of the history panel
+func (m *Model) maxHistoryScrollOffset() int {
+	return panelScrollLimit(renderHistoryLines(m.watchHistory(), m.width), m.viewportHeight())
+}
+
+// handleHistoryPanelKey handles scrolling and closing while the history panel is open.
+// It returns false for keys the panel doesn't use.
+func (m *Model) handleHistoryPanelKey(msg tea.KeyMsg) bool {
+	switch msg.String() {
+	case "h", "H", "esc":
+		m.showHistory = false
+		return true
+	}
+	return scrollPanel(msg.String(), &m.historyScrollOffset, m.maxHistoryScrollOffset())
+}
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
+	"github.com/DylanSharp/dtools/internal/coderabbit/ports"
 	"github.com/DylanSharp/dtools/internal/coderabbit/service"
 )
 
@@ -26,6 +27,18 @@ type WatchEventMsg struct {
 	Event service.WatchEvent
 }
 
+// WorkflowRunsMsg carries the PR's CI workflow runs for the CI panel
+type WorkflowRunsMsg struct {
+	Runs []ports.WorkflowRun
+	Err  error
+}
+
+// WorkflowRerunMsg reports the workflow runs rerun from the CI panel
+type WorkflowRerunMsg struct {
+	Runs []ports.WorkflowRun
+	Err  error
+}
+
 // ErrorMsg carries error information
 type ErrorMsg struct {
 	Err error
@@ -42,6 +55,11 @@ type ManualConfirmMsg struct {
 	Confirmed bool
 }
 
+// StartConfirmMsg is sent when the user answers the first-batch approval prompt
+type StartConfirmMsg struct {
+	Confirmed bool
+}
+
 // WindowSizeMsg is sent when the terminal is resized
 type WindowSizeMsg struct {
 	Width  int
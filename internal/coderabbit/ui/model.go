@@ -8,7 +8,10 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
+	"github.com/DylanSharp/dtools/internal/coderabbit/ports"
 	"github.com/DylanSharp/dtools/internal/coderabbit/service"
+	"github.com/DylanSharp/dtools/internal/logging"
+	dtui "github.com/DylanSharp/dtools/internal/ui"
 )
 
 // Model is the Bubbletea model for the review TUI
@@ -18,19 +21,33 @@ type Model struct {
 	thoughts []domain.ThoughtChunk
 
 	// UI state
-	statusBar     StatusBar
-	width         int
-	height        int
-	scrollOffset  int
-	err           error
+	statusBar           StatusBar
+	width               int
+	height              int
+	scrollOffset        int
+	follow              bool // Keep the latest thoughts in view; scrolling up turns this off
+	search              dtui.Search
+	showCI              bool // CI failure panel replaces the thoughts viewport
+	ciScrollOffset      int
+	workflowRuns        []ports.WorkflowRun // Fetched when the CI panel opens
+	ciNote              string              // Outcome of the last workflow rerun
+	showHistory         bool                // Watch history panel replaces the thoughts viewport
+	historyScrollOffset int
+	err                 error
 
 	// Mode flags
-	watchMode      bool
-	confirmingExit bool
-	streaming      bool
-	satisfied      bool
-	complete       bool  // Review finished (with or without comments)
-	fetching       bool  // Currently fetching data from GitHub
+	watchMode       bool
+	confirmingExit  bool
+	confirmingStart bool // First watch batch is shown, waiting for approval
+	streaming       bool
+	satisfied       bool
+	complete        bool // Review finished (with or without comments)
+	fetching        bool // Currently fetching data from GitHub
+	deferred        int  // Comments the last batch held back; 0 before the first
+
+	// Wait indicator, shown until Claude's first output
+	streamStarted time.Time
+	ticks         int
 
 	// Services
 	reviewService *service.ReviewService
@@ -61,6 +78,8 @@ func NewModel(
 		ctx:           ctx,
 		cancel:        cancel,
 		config:        config,
+		search:        dtui.NewSearch(),
+		follow:        true,
 		watchMode:     false,
 	}
 }
@@ -82,6 +101,8 @@ func NewWatchModel(
 		ctx:           ctx,
 		cancel:        cancel,
 		config:        config,
+		search:        dtui.NewSearch(),
+		follow:        true,
 		watchMode:     true,
 	}
 }
@@ -115,13 +136,19 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		return m.handleKeyPress(msg)
 
+	case tea.MouseMsg:
+		return m.handleMouse(msg)
+
 	case ThoughtMsg:
 		m.thoughts = append(m.thoughts, msg.Thought)
 		m.statusBar.CommentsProcessed++
 		m.statusBar.CurrentFile = msg.Thought.File
+		m.statusBar.MarkFileTouched(msg.Thought.File)
 
-		// Auto-scroll to bottom
-		m.scrollToBottom()
+		// Auto-scroll to bottom while following, unless the user is looking at search results
+		if m.follow && !m.search.Active() {
+			m.scrollToBottom()
+		}
 
 		// Continue reading thoughts
 		if m.thoughtsChan != nil {
@@ -132,8 +159,10 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case ReviewStartedMsg:
 		m.review = msg.Review
 		m.statusBar.Update(msg.Review)
+		m.statusBar.StartFileProgress(msg.Review)
 		m.thoughtsChan = msg.Thoughts
 		m.streaming = true
+		m.streamStarted = time.Now()
 		m.fetching = false
 		m.complete = false
 
@@ -153,22 +182,52 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.Review != nil && msg.Review.Satisfied {
 			m.satisfied = true
 		}
+		// Comments held back by --max-comments are addressed as the next batch, without
+		// resetting the state that marks this batch's as processed. Stop if a batch
+		// didn't shrink the backlog, e.g. because state couldn't be saved.
+		if !m.watchMode && msg.Review != nil && msg.Review.DeferredCount > 0 && msg.Review.Status == domain.ReviewStatusCompleted &&
+			(m.deferred == 0 || msg.Review.DeferredCount < m.deferred) {
+			m.deferred = msg.Review.DeferredCount
+			m.config.ResetState = false
+			return m, m.startReviewCmd()
+		}
 		return m, nil
 
 	case WatchEventMsg:
 		return m.handleWatchEvent(msg.Event)
 
+	case WorkflowRunsMsg:
+		if msg.Err == nil {
+			m.workflowRuns = msg.Runs
+		}
+		return m, nil
+
+	case WorkflowRerunMsg:
+		switch {
+		case msg.Err != nil:
+			m.ciNote = "Rerun failed: " + msg.Err.Error()
+		case len(msg.Runs) == 0:
+			m.ciNote = "No failed workflow runs to rerun"
+		default:
+			m.ciNote = fmt.Sprintf("Rerunning %d failed workflow run(s)", len(msg.Runs))
+		}
+		return m, m.fetchWorkflowRunsCmd()
+
 	case ErrorMsg:
 		m.err = msg.Err
 		m.statusBar.SetError(msg.Err)
 		return m, nil
 
 	case TickMsg:
+		m.ticks++
 		// Update cooldown/batch wait remaining
 		if m.watcher != nil {
 			cooldown := m.watcher.GetCooldownRemaining()
 			batchWait := m.watcher.GetBatchWaitRemaining()
 			m.statusBar.SetWatchState(m.watcher.GetState(), cooldown, batchWait)
+			m.statusBar.SetBatchExtensions(m.watcher.GetBatchWaitExtensions())
+			m.statusBar.Paused = m.watcher.IsPaused()
+			m.statusBar.Iterations, m.statusBar.MaxIterations = m.watcher.GetIterations()
 		}
 		return m, tickCmd()
 
@@ -185,6 +244,27 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		// Resume reading watch events after rejection
 		return m, m.readWatchEventCmd()
+
+	case StartConfirmMsg:
+		m.confirmingStart = false
+		if m.watcher == nil {
+			return m, nil
+		}
+		content := "Approved, Claude will address these items; later batches run without asking"
+		if msg.Confirmed {
+			m.watcher.ApproveStart()
+		} else {
+			m.watcher.RejectStart()
+			m.statusBar.Paused = true
+			content = "Not addressed, watching is paused (p to resume, r to check now)"
+		}
+		m.thoughts = append(m.thoughts, domain.ThoughtChunk{
+			Timestamp: time.Now(),
+			Content:   content,
+			Type:      domain.ThoughtTypeProgress,
+		})
+		m.scrollToBottom()
+		return m, m.readWatchEventCmd()
 	}
 
 	return m, nil
@@ -222,6 +302,20 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// Approve or decline the first watch batch; other keys still scroll
+	if m.confirmingStart {
+		switch msg.String() {
+		case "y", "Y":
+			return m, func() tea.Msg {
+				return StartConfirmMsg{Confirmed: true}
+			}
+		case "n", "N":
+			return m, func() tea.Msg {
+				return StartConfirmMsg{Confirmed: false}
+			}
+		}
+	}
+
 	// Handle error state
 	if m.err != nil {
 		m.err = nil
@@ -229,45 +323,133 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// Handle search input
+	if m.search.Editing {
+		if msg.String() == "ctrl+c" {
+			m.cancel()
+			return m, tea.Quit
+		}
+		if m.search.HandleInput(msg) && m.search.Active() {
+			// Incremental search: stay on the current match while it still matches
+			from := m.search.Current
+			if from < 0 {
+				from = 0
+			}
+			m.jumpToMatch(from, 1)
+		}
+		return m, nil
+	}
+
+	// The history panel takes over scrolling while it's open
+	if m.showHistory && m.handleHistoryPanelKey(msg) {
+		return m, nil
+	}
+
+	// The CI panel takes over scrolling while it's open
+	if m.showCI {
+		if handled, cmd := m.handleCIPanelKey(msg); handled {
+			return m, cmd
+		}
+	}
+
 	switch msg.String() {
 	case "q", "Q", "ctrl+c":
 		m.cancel()
 		return m, tea.Quit
 
-	case "up", "k":
-		if m.scrollOffset > 0 {
-			m.scrollOffset--
+	case "c", "C":
+		m.showCI = true
+		m.showHistory = false
+		m.ciScrollOffset = 0
+		return m, m.fetchWorkflowRunsCmd()
+
+	case "h", "H":
+		// Show what each watch iteration covered, newest at the bottom
+		if m.watcher != nil {
+			m.showHistory = true
+			m.showCI = false
+			m.historyScrollOffset = m.maxHistoryScrollOffset()
+		}
+		return m, nil
+
+	case "/":
+		m.search = dtui.NewSearch()
+		m.search.Editing = true
+		return m, nil
+
+	case "n":
+		if m.search.Active() {
+			m.jumpToMatch(m.search.Current+1, 1)
+		}
+		return m, nil
+
+	case "N":
+		if m.search.Active() {
+			m.jumpToMatch(m.search.Current-1, -1)
 		}
 		return m, nil
 
+	case "esc":
+		m.search.Clear()
+		return m, nil
+
+	case "up", "k":
+		m.scrollBy(-1)
+		return m, nil
+
 	case "down", "j":
-		m.scrollOffset++
+		m.scrollBy(1)
 		return m, nil
 
 	case "pgup":
-		m.scrollOffset -= 10
-		if m.scrollOffset < 0 {
-			m.scrollOffset = 0
-		}
+		m.scrollBy(-10)
 		return m, nil
 
 	case "pgdown":
-		m.scrollOffset += 10
+		m.scrollBy(10)
 		return m, nil
 
 	case "home", "g":
-		m.scrollOffset = 0
+		m.scrollBy(-m.scrollOffset)
 		return m, nil
 
 	case "end", "G":
 		m.scrollToBottom()
 		return m, nil
 
+	case "f", "F":
+		// Toggle following new output; turning it on jumps to the latest
+		if m.follow {
+			m.follow = false
+		} else {
+			m.scrollToBottom()
+		}
+		return m, nil
+
+	case "p", "P":
+		// Pause or resume polling in watch mode
+		if m.watcher != nil {
+			if m.watcher.IsPaused() {
+				m.watcher.Resume()
+			} else {
+				m.watcher.Pause()
+			}
+			m.statusBar.Paused = m.watcher.IsPaused()
+		}
+		return m, nil
+
 	case "r", "R":
+		// In watch mode, check for new comments now instead of waiting for the next poll
+		if m.watcher != nil {
+			m.watcher.CheckNow()
+			return m, nil
+		}
 		if !m.watchMode && !m.streaming {
 			// Refresh - restart review
 			m.thoughts = []domain.ThoughtChunk{}
 			m.scrollOffset = 0
+			m.follow = true
+			m.search.Current = -1
 			return m, m.startReviewCmd()
 		}
 		return m, nil
@@ -286,15 +468,21 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 // handleWatchEvent handles watch mode events
 func (m *Model) handleWatchEvent(event service.WatchEvent) (tea.Model, tea.Cmd) {
 	m.statusBar.SetWatchState(m.watcher.GetState(), m.watcher.GetCooldownRemaining(), m.watcher.GetBatchWaitRemaining())
+	m.statusBar.SetBatchExtensions(m.watcher.GetBatchWaitExtensions())
 
 	switch event.Type {
 	case service.WatchEventNewComments, service.WatchEventNewCIFailures:
 		m.review = event.Review
 		m.statusBar.Update(event.Review)
+		m.statusBar.StartFileProgress(event.Review)
 		m.thoughtsChan = event.Thoughts
 		m.streaming = true
-		// Clear previous thoughts for new review iteration
+		m.streamStarted = time.Now()
+		// Clear previous thoughts for new review iteration, following it from the start
 		m.thoughts = []domain.ThoughtChunk{}
+		m.scrollOffset = 0
+		m.follow = true
+		m.search.Current = -1
 		// Read both thoughts and continue watching for more events
 		return m, tea.Batch(m.readThoughtCmd(), m.readWatchEventCmd())
 
@@ -316,6 +504,61 @@ func (m *Model) handleWatchEvent(event service.WatchEvent) (tea.Model, tea.Cmd)
 		m.confirmingExit = true
 		return m, nil
 
+	case service.WatchEventConfirmStart:
+		m.review = event.Review
+		m.statusBar.Update(event.Review)
+		m.confirmingStart = true
+		m.thoughts = m.buildCommentSummary(event.Review)
+		// Swap the closing analysis header for the approval prompt
+		if n := len(m.thoughts); n > 0 {
+			m.thoughts[n-1].Content = "─── Waiting for approval ───"
+		}
+		m.thoughts = append(m.thoughts, domain.ThoughtChunk{
+			Timestamp: event.Timestamp,
+			Content:   "Press y to let Claude address these items, n to pause watching instead",
+			Type:      domain.ThoughtTypeProgress,
+		})
+		m.scrollOffset = 0
+		m.search.Current = -1
+		// The watcher waits for the answer, so there are no events to read until then
+		return m, nil
+
+	case service.WatchEventPRClosed:
+		m.review = event.Review
+		m.statusBar.Update(event.Review)
+		m.streaming = false
+		m.complete = true
+		m.thoughtsChan = nil
+		m.thoughts = append(m.thoughts, domain.ThoughtChunk{
+			Timestamp: event.Timestamp,
+			Content:   event.Message + " (use --allow-closed to keep watching)",
+			Type:      domain.ThoughtTypeProgress,
+		})
+		// The watcher has stopped, so there are no more events to read
+		return m, nil
+
+	case service.WatchEventMaxIterations:
+		m.review = event.Review
+		m.statusBar.Update(event.Review)
+		m.streaming = false
+		m.complete = true
+		m.thoughtsChan = nil
+		// List what's still unresolved, in place of the closing analysis header
+		m.thoughts = m.buildCommentSummary(event.Review)
+		if n := len(m.thoughts); n > 0 {
+			m.thoughts[n-1].Content = "─── Unresolved ───"
+		}
+		m.thoughts = append(m.thoughts, domain.ThoughtChunk{
+			Timestamp: event.Timestamp,
+			Content:   event.Message + " (raise --max-iterations to keep going)",
+			Type:      domain.ThoughtTypeProgress,
+		})
+		m.scrollOffset = 0
+		m.follow = true
+		m.search.Current = -1
+		// The watcher has stopped, so there are no more events to read
+		return m, nil
+
 	case service.WatchEventError:
 		m.err = event.Error
 		m.statusBar.SetError(event.Error)
@@ -333,18 +576,89 @@ func (m *Model) handleWatchEvent(event service.WatchEvent) (tea.Model, tea.Cmd)
 	return m, m.readWatchEventCmd()
 }
 
-// scrollToBottom scrolls to show the latest content
-func (m *Model) scrollToBottom() {
-	// Calculate total lines from thoughts
-	totalLines := len(m.thoughts)
+// handleMouse scrolls the thoughts with the mouse wheel
+func (m *Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.confirmingExit || m.err != nil {
+		return m, nil
+	}
+
+	if m.showCI {
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			m.ciScrollOffset, _ = dtui.ScrollBy(m.ciScrollOffset, -mouseWheelStep, m.maxCIScrollOffset())
+		case tea.MouseButtonWheelDown:
+			m.ciScrollOffset, _ = dtui.ScrollBy(m.ciScrollOffset, mouseWheelStep, m.maxCIScrollOffset())
+		}
+		return m, nil
+	}
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.scrollBy(-mouseWheelStep)
+
+	case tea.MouseButtonWheelDown:
+		m.scrollBy(mouseWheelStep)
+	}
+
+	return m, nil
+}
+
+// viewportHeight returns the number of thought lines that fit on screen
+func (m *Model) viewportHeight() int {
 	viewHeight := m.height - statusBarHeight - helpHeight - headerHeight
 	if viewHeight < minViewportHeight {
 		viewHeight = minViewportHeight
 	}
+	return viewHeight
+}
 
-	if totalLines > viewHeight {
-		m.scrollOffset = totalLines - viewHeight
-	} else {
+// scrollToBottom scrolls to show the latest content and follows new content from there
+func (m *Model) scrollToBottom() {
+	m.scrollOffset = m.maxScrollOffset()
+	m.follow = true
+}
+
+// scrollBy scrolls by delta lines within bounds. Scrolling away from the bottom
+// stops following new content; scrolling back down to it follows again.
+func (m *Model) scrollBy(delta int) {
+	m.scrollOffset, m.follow = dtui.ScrollBy(m.scrollOffset, delta, m.maxScrollOffset())
+}
+
+// maxScrollOffset returns the scroll offset that shows the last line of thoughts
+func (m *Model) maxScrollOffset() int {
+	// Thoughts can wrap, so count rendered lines rather than thoughts
+	lines, _ := renderThoughtLines(m.thoughts, m.width, m.search)
+	viewHeight := m.viewportHeight()
+
+	if len(lines) > viewHeight {
+		return len(lines) - viewHeight
+	}
+	return 0
+}
+
+// searchTexts returns the searchable text of each thought
+func (m *Model) searchTexts() []string {
+	texts := make([]string, len(m.thoughts))
+	for i, thought := range m.thoughts {
+		texts[i] = thoughtSearchText(thought)
+	}
+	return texts
+}
+
+// jumpToMatch moves to the next search match starting at from in direction dir
+// and scrolls it into the middle of the viewport
+func (m *Model) jumpToMatch(from, dir int) {
+	idx := m.search.Find(m.searchTexts(), from, dir)
+	m.search.Current = idx
+	if idx < 0 {
+		return
+	}
+	m.follow = false
+
+	// Thoughts can wrap over several lines, so scroll by rendered line
+	_, starts := renderThoughtLines(m.thoughts, m.width, m.search)
+	m.scrollOffset = starts[idx] - m.viewportHeight()/2
+	if m.scrollOffset < 0 {
 		m.scrollOffset = 0
 	}
 }
@@ -433,11 +747,27 @@ func (m *Model) openPRCmd() tea.Cmd {
 	return func() tea.Msg {
 		// Use gh pr view --web to open in browser
 		cmd := exec.Command("gh", "pr", "view", fmt.Sprintf("%d", m.config.PRNumber), "--web")
-		_ = cmd.Run() // Ignore errors - best effort
+		_ = logging.Run(cmd) // Ignore errors - best effort
 		return nil
 	}
 }
 
+// fetchWorkflowRunsCmd fetches the PR's workflow runs for the CI panel
+func (m *Model) fetchWorkflowRunsCmd() tea.Cmd {
+	return func() tea.Msg {
+		runs, err := m.reviewService.GetWorkflowRuns(m.ctx, m.config.PRNumber)
+		return WorkflowRunsMsg{Runs: runs, Err: err}
+	}
+}
+
+// rerunWorkflowsCmd reruns the PR's failed workflow runs
+func (m *Model) rerunWorkflowsCmd() tea.Cmd {
+	return func() tea.Msg {
+		runs, err := m.reviewService.RerunFailedWorkflows(m.ctx, m.config.PRNumber)
+		return WorkflowRerunMsg{Runs: runs, Err: err}
+	}
+}
+
 // GetReview returns the current review
 func (m *Model) GetReview() *domain.Review {
 	return m.review
@@ -463,9 +793,13 @@ func (m *Model) buildCommentSummary(review *domain.Review) []domain.ThoughtChunk
 
 	// Show CodeRabbit comments if any
 	if len(review.Comments) > 0 {
+		count := fmt.Sprintf("%d", len(review.Comments))
+		if review.DeferredCount > 0 {
+			count = fmt.Sprintf("%d of %d, the rest in later batches", len(review.Comments), len(review.Comments)+review.DeferredCount)
+		}
 		thoughts = append(thoughts, domain.ThoughtChunk{
 			Timestamp: now,
-			Content:   fmt.Sprintf("─── CodeRabbit Comments (%d) ───", len(review.Comments)),
+			Content:   fmt.Sprintf("─── CodeRabbit Comments (%s) ───", count),
 			Type:      domain.ThoughtTypeHeader,
 		})
 
@@ -0,0 +1,29 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
+	"github.com/DylanSharp/dtools/internal/coderabbit/service"
+)
+
+func TestSingleRunBatches(t *testing.T) {
+	m := NewModel(nil, service.ReviewConfig{ResetState: true})
+	batch := func(deferred int) bool {
+		_, cmd := m.Update(ReviewCompleteMsg{Review: &domain.Review{Status: domain.ReviewStatusCompleted, DeferredCount: deferred}})
+		return cmd != nil
+	}
+
+	if !batch(3) {
+		t.Fatal("no next batch with 3 comments deferred")
+	}
+	if m.config.ResetState {
+		t.Error("the next batch resets state, which would redo the first batch's comments")
+	}
+	if !batch(1) {
+		t.Fatal("no next batch after the backlog shrank to 1")
+	}
+	if batch(1) {
+		t.Error("started another batch though the last one didn't shrink the backlog")
+	}
+}
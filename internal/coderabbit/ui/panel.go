@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"strings"
+
+	dtui "github.com/DylanSharp/dtools/internal/ui"
+)
+
+// renderPanelWindow renders the lines of a panel visible at scrollOffset,
+// padded to fill height rows
+func renderPanelWindow(lines []string, height, scrollOffset int) string {
+	scrollOffset = min(max(scrollOffset, 0), panelScrollLimit(lines, height))
+	end := min(scrollOffset+height, len(lines))
+
+	visible := lines[scrollOffset:end]
+	for len(visible) < height {
+		visible = append(visible, "")
+	}
+
+	return strings.Join(visible, "\n")
+}
+
+// panelScrollLimit returns the scroll offset that shows the last of a panel's lines
+func panelScrollLimit(lines []string, height int) int {
+	return max(len(lines)-height, 0)
+}
+
+// scrollPanel applies a scrolling key to a panel's scroll offset, keeping it
+// within limit. It returns false for keys that don't scroll.
+func scrollPanel(key string, offset *int, limit int) bool {
+	var delta int
+	switch key {
+	case "up", "k":
+		delta = -1
+	case "down", "j":
+		delta = 1
+	case "pgup":
+		delta = -10
+	case "pgdown":
+		delta = 10
+	case "home", "g":
+		delta = -*offset
+	case "end", "G":
+		delta = limit - *offset
+	default:
+		return false
+	}
+
+	*offset, _ = dtui.ScrollBy(*offset, delta, limit)
+	return true
+}
@@ -13,6 +13,7 @@ import (
 // StatusBar renders the bottom status line
 type StatusBar struct {
 	Branch            string
+	Commit            string // Short SHA of the head commit being reviewed
 	PRNumber          int
 	Repository        string
 	CommentsProcessed int
@@ -22,6 +23,10 @@ type StatusBar struct {
 	WatchState        service.WatchState
 	CooldownRemaining   time.Duration
 	BatchWaitRemaining  time.Duration
+	BatchExtensions     int  // Times an adaptive batch wait was extended
+	Paused              bool // Watch mode polling is paused
+	Iterations          int  // Claude runs this watch session
+	MaxIterations       int  // Watch mode's iteration limit (0 for none)
 	StartTime         time.Time
 	LastChecked       time.Time
 	Error             error
@@ -30,11 +35,18 @@ type StatusBar struct {
 	TotalFound       int
 	AlreadyAddressed int
 	NewComments      int
+	ResolvedThreads  int
 
 	// CI tracking
 	CIFailureCount int
 	CIPendingCount int
 	CIAllComplete  bool
+
+	// File progress while reviewing: commented files Claude has worked on so far
+	FilesTotal     int
+	FilesTouched   int
+	commentedFiles []string
+	touchedFiles   map[string]bool
 }
 
 // NewStatusBar creates a new status bar with default values
@@ -56,7 +68,11 @@ func (s StatusBar) Render(width int) string {
 
 	// Branch and PR
 	if s.Branch != "" {
-		branchSection := StatusBarSectionStyle.Render(s.Branch)
+		branch := s.Branch
+		if s.Commit != "" {
+			branch += "@" + s.Commit
+		}
+		branchSection := StatusBarSectionStyle.Render(branch)
 		sections = append(sections, branchSection)
 	}
 
@@ -69,11 +85,17 @@ func (s StatusBar) Render(width int) string {
 	if s.TotalFound > 0 || s.NewComments > 0 {
 		var commentInfo string
 		if s.NewComments > 0 && s.Status == domain.ReviewStatusReviewing {
-			// Actively processing - can't track per-comment progress, just show count
+			// Actively processing - per-comment progress is opaque, so estimate it from the files touched
 			commentInfo = fmt.Sprintf("Addressing %d comments", s.NewComments)
+			if s.FilesTotal > 0 {
+				commentInfo += fmt.Sprintf(" (%d/%d files)", s.FilesTouched, s.FilesTotal)
+			}
 		} else if s.NewComments > 0 {
 			// Completed with new comments
 			commentInfo = fmt.Sprintf("Addressed: %d", s.NewComments)
+			if s.ResolvedThreads > 0 {
+				commentInfo += fmt.Sprintf(" (%d resolved)", s.ResolvedThreads)
+			}
 		} else if s.AlreadyAddressed > 0 {
 			// All comments were already addressed
 			commentInfo = fmt.Sprintf("Found: %d (all addressed)", s.TotalFound)
@@ -99,14 +121,28 @@ func (s StatusBar) Render(width int) string {
 	if s.CurrentFile != "" {
 		// Truncate if too long
 		file := s.CurrentFile
-		if len(file) > 30 {
-			file = "..." + file[len(file)-27:]
+		if lipgloss.Width(file) > 30 {
+			runes := []rune(file)
+			for len(runes) > 0 && lipgloss.Width(string(runes)) > 27 {
+				runes = runes[1:]
+			}
+			file = "..." + string(runes)
 		}
 		fileSection := FileReferenceStyle.Render(file)
 		sections = append(sections, fileSection)
 	}
 
+	// Watch iterations
+	if s.MaxIterations > 0 {
+		sections = append(sections, StatusBarSectionStyle.Render(fmt.Sprintf("Iteration %d/%d", s.Iterations, s.MaxIterations)))
+	} else if s.Iterations > 0 {
+		sections = append(sections, StatusBarSectionStyle.Render(fmt.Sprintf("Iteration %d", s.Iterations)))
+	}
+
 	// Status indicator
+	if s.Paused {
+		sections = append(sections, StatusBarWarningStyle.Render("⏸ Paused"))
+	}
 	statusSection := s.renderStatus()
 	sections = append(sections, statusSection)
 
@@ -152,9 +188,14 @@ func (s StatusBar) renderStatus() string {
 			return StatusBarSectionStyle.Render("◌ Polling...")
 		case service.WatchStateBatchWait:
 			remaining := formatDuration(s.BatchWaitRemaining)
+			if s.BatchExtensions > 0 {
+				return StatusBarWarningStyle.Render(fmt.Sprintf("◐ Batching %s (extended %dx)", remaining, s.BatchExtensions))
+			}
 			return StatusBarWarningStyle.Render(fmt.Sprintf("◐ Batching %s", remaining))
 		case service.WatchStateProcessing:
 			return StatusBarProgressStyle.Render("● Processing")
+		case service.WatchStateConfirming:
+			return StatusBarWarningStyle.Render("◐ Awaiting approval")
 		case service.WatchStateCooldown:
 			remaining := formatDuration(s.CooldownRemaining)
 			return StatusBarWarningStyle.Render(fmt.Sprintf("◑ Cooldown %s", remaining))
@@ -168,6 +209,8 @@ func (s StatusBar) renderStatus() string {
 			return StatusBarProgressStyle.Render("✓ Satisfied")
 		case service.WatchStateError:
 			return StatusBarErrorStyle.Render("● Error")
+		case service.WatchStateStopped:
+			return StatusBarErrorStyle.Render("■ Max iterations")
 		}
 	}
 
@@ -209,6 +252,7 @@ func (s *StatusBar) Update(review *domain.Review) {
 	}
 
 	s.Branch = review.Branch
+	s.Commit = review.ShortCommit()
 	s.PRNumber = review.PRNumber
 	s.Repository = review.Repository
 	s.CommentsTotal = len(review.Comments)
@@ -221,6 +265,7 @@ func (s *StatusBar) Update(review *domain.Review) {
 	s.TotalFound = review.TotalFoundCount
 	s.AlreadyAddressed = review.AlreadyAddressed
 	s.NewComments = review.NewCommentsCount
+	s.ResolvedThreads = review.ResolvedCount
 
 	// CI tracking
 	s.CIFailureCount = len(review.CIFailures)
@@ -235,6 +280,40 @@ func (s *StatusBar) SetWatchState(state service.WatchState, cooldownRemaining, b
 	s.BatchWaitRemaining = batchWaitRemaining
 }
 
+// SetBatchExtensions updates how many times the adaptive batch wait was extended
+func (s *StatusBar) SetBatchExtensions(n int) {
+	s.BatchExtensions = n
+}
+
+// StartFileProgress resets file progress for a review starting to address its comments
+func (s *StatusBar) StartFileProgress(review *domain.Review) {
+	if review == nil {
+		return
+	}
+	files := review.CommentedFiles()
+	s.commentedFiles = files
+	s.touchedFiles = make(map[string]bool)
+	s.FilesTotal = len(files)
+	s.FilesTouched = 0
+}
+
+// MarkFileTouched records a file Claude worked on, counting it if it's a
+// commented file. Claude may refer to files by absolute path.
+func (s *StatusBar) MarkFileTouched(file string) {
+	if file == "" || s.touchedFiles == nil {
+		return
+	}
+	for _, commented := range s.commentedFiles {
+		if file == commented || strings.HasSuffix(file, "/"+commented) {
+			if !s.touchedFiles[commented] {
+				s.touchedFiles[commented] = true
+				s.FilesTouched++
+			}
+			return
+		}
+	}
+}
+
 // SetError sets the error state
 func (s *StatusBar) SetError(err error) {
 	s.Error = err
@@ -110,6 +110,17 @@ var (
 		Foreground(Green).
 		PaddingLeft(2)
 
+	// ThoughtThinkingStyle is for Claude's extended thinking
+	ThoughtThinkingStyle = lipgloss.NewStyle().
+		Foreground(Gray).
+		Italic(true).
+		PaddingLeft(2)
+
+	// ThoughtToolStyle is for tool calls
+	ThoughtToolStyle = lipgloss.NewStyle().
+		Foreground(Blue).
+		PaddingLeft(2)
+
 	// ThoughtBulletStyle is for the bullet point
 	ThoughtBulletStyle = lipgloss.NewStyle().
 		Foreground(Cyan)
@@ -153,6 +164,18 @@ var (
 		PaddingTop(1)
 )
 
+// Search styles
+var (
+	// SearchMatchStyle marks lines matching the search query
+	SearchMatchStyle = lipgloss.NewStyle().
+		Foreground(Yellow)
+
+	// SearchCurrentStyle marks the current search match
+	SearchCurrentStyle = lipgloss.NewStyle().
+		Foreground(Yellow).
+		Bold(true)
+)
+
 // Box styles
 var (
 	// BorderStyle is for bordered boxes
@@ -3,9 +3,11 @@ package ui
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
+	dtui "github.com/DylanSharp/dtools/internal/ui"
 )
 
 const (
@@ -13,6 +15,7 @@ const (
 	helpHeight      = 1
 	headerHeight    = 2
 	minViewportHeight = 5
+	mouseWheelStep = 3
 )
 
 // RenderView renders the complete TUI view
@@ -40,6 +43,8 @@ func RenderView(m *Model) string {
 		Complete:  m.complete,
 		Satisfied: m.satisfied,
 		WatchMode: m.watchMode,
+		Spinner:   dtui.SpinnerFrame(m.ticks),
+		Waited:    time.Since(m.streamStarted),
 	}
 	if m.review != nil {
 		viewState.TotalFound = m.review.TotalFoundCount
@@ -52,7 +57,14 @@ func RenderView(m *Model) string {
 		viewState.CodeRabbitCompleted = m.review.CodeRabbitCompleted
 	}
 
-	content := renderThoughts(m.thoughts, m.width, viewportHeight, m.scrollOffset, viewState)
+	var content string
+	if m.showHistory {
+		content = renderHistoryPanel(m.watchHistory(), m.width, viewportHeight, m.historyScrollOffset)
+	} else if m.showCI {
+		content = renderCIPanel(m.ciFailures(), m.workflowRuns, m.ciNote, m.width, viewportHeight, m.ciScrollOffset)
+	} else {
+		content = renderThoughts(m.thoughts, m.width, viewportHeight, m.scrollOffset, viewState, m.search)
+	}
 	sections = append(sections, content)
 
 	// Help line
@@ -71,14 +83,19 @@ func renderHeader(m *Model) string {
 	title := "Claude Code Review"
 	if m.review != nil && m.review.Title != "" {
 		title = fmt.Sprintf("Review: %s", m.review.Title)
-		if len(title) > m.width-4 {
-			title = title[:m.width-7] + "..."
-		}
+		title = dtui.TruncateWidth(title, m.width-4)
 	}
 
 	var subtitle string
 	if m.review != nil {
-		subtitle = fmt.Sprintf("PR #%d on %s", m.review.PRNumber, m.review.Branch)
+		pr := fmt.Sprintf("PR #%d", m.review.PRNumber)
+		if label := m.review.PRStateLabel(); label != "" {
+			pr += fmt.Sprintf(" (%s)", label)
+		}
+		subtitle = fmt.Sprintf("%s on %s", pr, m.review.Branch)
+		if commit := m.review.ShortCommit(); commit != "" {
+			subtitle += " @ " + commit
+		}
 	}
 
 	header := HeaderStyle.Width(m.width).Render(title)
@@ -105,10 +122,12 @@ type ThoughtViewState struct {
 	CIAllComplete       bool
 	CodeRabbitFound     bool // True if CodeRabbit check run exists
 	CodeRabbitCompleted bool // True if CodeRabbit check run has completed
+	Spinner             string        // Current spinner frame, shown while waiting for Claude
+	Waited              time.Duration // How long Claude has run without output
 }
 
 // renderThoughts renders the scrollable thoughts area
-func renderThoughts(thoughts []domain.ThoughtChunk, width, height, scrollOffset int, state ThoughtViewState) string {
+func renderThoughts(thoughts []domain.ThoughtChunk, width, height, scrollOffset int, state ThoughtViewState, search dtui.Search) string {
 	if len(thoughts) == 0 {
 		var message string
 		// First, check CodeRabbit status - this takes priority
@@ -169,7 +188,7 @@ func renderThoughts(thoughts []domain.ThoughtChunk, width, height, scrollOffset
 			}
 			message = fmt.Sprintf("Found %s, passing to Claude...", strings.Join(parts, " and "))
 		} else if state.Streaming {
-			message = "Waiting for Claude's response..."
+			message = fmt.Sprintf("%s Waiting for Claude's response... %s", state.Spinner, state.Waited.Round(time.Second))
 		} else if state.Fetching {
 			message = "Checking for CodeRabbit comments and CI status..."
 		} else if state.Complete {
@@ -186,15 +205,7 @@ func renderThoughts(thoughts []domain.ThoughtChunk, width, height, scrollOffset
 	}
 
 	// Render each thought
-	var lines []string
-	for _, thought := range thoughts {
-		line := renderThought(thought, width-4)
-		lines = append(lines, line)
-	}
-
-	// Join all lines
-	content := strings.Join(lines, "\n")
-	allLines := strings.Split(content, "\n")
+	allLines, _ := renderThoughtLines(thoughts, width, search)
 
 	// Apply scroll offset
 	totalLines := len(allLines)
@@ -221,6 +232,51 @@ func renderThoughts(thoughts []domain.ThoughtChunk, width, height, scrollOffset
 	return strings.Join(visibleLines, "\n")
 }
 
+// renderThoughtLines renders every thought and splits the result into lines.
+// It also returns the index of the first line of each thought. When a search is
+// active, matching thoughts are marked in a gutter.
+func renderThoughtLines(thoughts []domain.ThoughtChunk, width int, search dtui.Search) ([]string, []int) {
+	var lines []string
+	starts := make([]int, len(thoughts))
+
+	for i, thought := range thoughts {
+		starts[i] = len(lines)
+
+		if !search.Active() {
+			lines = append(lines, strings.Split(renderThought(thought, width-4), "\n")...)
+			continue
+		}
+
+		// Leave room for the match gutter
+		first, rest := "  ", "  "
+		switch {
+		case i == search.Current:
+			first = SearchCurrentStyle.Render("▶") + " "
+			rest = SearchCurrentStyle.Render("▌") + " "
+		case search.Matches(thoughtSearchText(thought)):
+			first = SearchMatchStyle.Render("▌") + " "
+			rest = first
+		}
+		for j, line := range strings.Split(renderThought(thought, width-6), "\n") {
+			if j == 0 {
+				lines = append(lines, first+line)
+			} else {
+				lines = append(lines, rest+line)
+			}
+		}
+	}
+
+	return lines, starts
+}
+
+// thoughtSearchText returns the plain text of a thought that search matches against
+func thoughtSearchText(thought domain.ThoughtChunk) string {
+	if thought.File == "" {
+		return thought.Content
+	}
+	return thought.File + " " + thought.Content
+}
+
 // renderThought renders a single thought chunk
 func renderThought(thought domain.ThoughtChunk, maxWidth int) string {
 	// Handle header type specially (no bullet, dimmed)
@@ -232,7 +288,7 @@ func renderThought(thought domain.ThoughtChunk, maxWidth int) string {
 	if thought.Type == domain.ThoughtTypeComment {
 		// Word wrap if too long
 		content := thought.Content
-		if len(content) > maxWidth-2 {
+		if lipgloss.Width(content) > maxWidth-2 {
 			content = wordWrap(content, maxWidth-2)
 		}
 		return CommentStyle.Render(content)
@@ -252,6 +308,12 @@ func renderThought(thought domain.ThoughtChunk, maxWidth int) string {
 	case domain.ThoughtTypeAnalysis:
 		style = ThoughtAnalysisStyle
 		bullet = "▸"
+	case domain.ThoughtTypeThinking:
+		style = ThoughtThinkingStyle
+		bullet = "∴"
+	case domain.ThoughtTypeTool:
+		style = ThoughtToolStyle
+		bullet = "→"
 	default:
 		style = ThoughtStyle
 		bullet = "·"
@@ -268,7 +330,7 @@ func renderThought(thought domain.ThoughtChunk, maxWidth int) string {
 	}
 
 	// Word wrap if too long
-	if len(content) > maxWidth-4 {
+	if lipgloss.Width(content) > maxWidth-4 {
 		content = wordWrap(content, maxWidth-4)
 	}
 
@@ -285,12 +347,26 @@ func renderHelp(m *Model) string {
 				HelpKeyStyle.Render("y")+" "+HelpDescStyle.Render("confirm"),
 				HelpKeyStyle.Render("n")+" "+HelpDescStyle.Render("continue watching"),
 			)
+		} else if m.confirmingStart {
+			bindings = append(bindings,
+				HelpKeyStyle.Render("y")+" "+HelpDescStyle.Render("start Claude"),
+				HelpKeyStyle.Render("n")+" "+HelpDescStyle.Render("not now"),
+				HelpKeyStyle.Render("↑/↓")+" "+HelpDescStyle.Render("scroll"),
+				HelpKeyStyle.Render("q")+" "+HelpDescStyle.Render("quit"),
+			)
 		} else {
 			bindings = append(bindings,
 				HelpKeyStyle.Render("q")+" "+HelpDescStyle.Render("quit"),
 				HelpKeyStyle.Render("↑/↓")+" "+HelpDescStyle.Render("scroll"),
 				HelpKeyStyle.Render("o")+" "+HelpDescStyle.Render("open PR"),
+				HelpKeyStyle.Render("r")+" "+HelpDescStyle.Render("check now"),
 			)
+			if m.watcher != nil && m.watcher.IsPaused() {
+				bindings = append(bindings, HelpKeyStyle.Render("p")+" "+HelpDescStyle.Render("resume"))
+			} else {
+				bindings = append(bindings, HelpKeyStyle.Render("p")+" "+HelpDescStyle.Render("pause"))
+			}
+			bindings = append(bindings, HelpKeyStyle.Render("h")+" "+HelpDescStyle.Render("history"))
 		}
 	} else {
 		bindings = append(bindings,
@@ -301,6 +377,51 @@ func renderHelp(m *Model) string {
 		)
 	}
 
+	if m.showHistory && !m.confirmingExit {
+		bindings = []string{
+			HelpKeyStyle.Render("q") + " " + HelpDescStyle.Render("quit"),
+			HelpKeyStyle.Render("↑/↓") + " " + HelpDescStyle.Render("scroll"),
+			HelpKeyStyle.Render("h/esc") + " " + HelpDescStyle.Render("close history"),
+		}
+		return HelpStyle.Render(strings.Join(bindings, "  "))
+	}
+
+	if m.showCI && !m.confirmingExit {
+		bindings = []string{
+			HelpKeyStyle.Render("q") + " " + HelpDescStyle.Render("quit"),
+			HelpKeyStyle.Render("↑/↓") + " " + HelpDescStyle.Render("scroll"),
+			HelpKeyStyle.Render("r") + " " + HelpDescStyle.Render("rerun failed"),
+			HelpKeyStyle.Render("c/esc") + " " + HelpDescStyle.Render("close CI details"),
+		}
+		return HelpStyle.Render(strings.Join(bindings, "  "))
+	}
+
+	if !m.follow && !m.confirmingExit {
+		bindings = append(bindings, HelpKeyStyle.Render("f")+" "+HelpDescStyle.Render("follow"))
+	}
+
+	if len(m.ciFailures()) > 0 && !m.confirmingExit {
+		bindings = append(bindings, HelpKeyStyle.Render("c")+" "+HelpDescStyle.Render("CI details"))
+	}
+
+	if !m.confirmingExit {
+		if m.search.Editing {
+			bindings = []string{
+				HelpKeyStyle.Render(m.search.RenderPrompt(nil)),
+				HelpKeyStyle.Render("enter") + " " + HelpDescStyle.Render("done"),
+				HelpKeyStyle.Render("esc") + " " + HelpDescStyle.Render("cancel"),
+			}
+		} else if m.search.Active() {
+			bindings = append(bindings,
+				HelpKeyStyle.Render("n/N")+" "+HelpDescStyle.Render("next/prev"),
+				HelpKeyStyle.Render("esc")+" "+HelpDescStyle.Render("clear search"),
+				HelpDescStyle.Render(m.search.RenderPrompt(m.searchTexts())),
+			)
+		} else {
+			bindings = append(bindings, HelpKeyStyle.Render("/")+" "+HelpDescStyle.Render("search"))
+		}
+	}
+
 	help := strings.Join(bindings, "  ")
 	return HelpStyle.Render(help)
 }
@@ -316,7 +437,7 @@ func wordWrap(text string, maxWidth int) string {
 	lineLength := 0
 
 	for i, word := range words {
-		wordLen := len(word)
+		wordLen := lipgloss.Width(word)
 
 		if lineLength+wordLen+1 > maxWidth && lineLength > 0 {
 			result.WriteString("\n  ")
@@ -326,6 +447,20 @@ func wordWrap(text string, maxWidth int) string {
 			lineLength++
 		}
 
+		// Hard-break words that don't fit on a line of their own (long URLs,
+		// paths). Styled words are left alone so escape sequences aren't split.
+		for wordLen > maxWidth-lineLength && maxWidth-lineLength > 0 && !strings.Contains(word, "\x1b") {
+			head, tail := dtui.SplitAtWidth(word, maxWidth-lineLength)
+			if head == "" {
+				break
+			}
+			result.WriteString(head)
+			result.WriteString("\n  ")
+			lineLength = 2
+			word = tail
+			wordLen = lipgloss.Width(word)
+		}
+
 		result.WriteString(word)
 		lineLength += wordLen
 	}
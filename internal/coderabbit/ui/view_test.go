@@ -0,0 +1,34 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestWordWrapEmoji(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		maxWidth int
+	}{
+		{"emoji labels", "⚠️ Potential issue 🛠️ Refactor suggestion 🧹 Nitpick comments (3) 🎉 LGTM", 20},
+		{"bullets", "• Fixed the race • Added tests • Updated docs • Renamed helpers", 16},
+		{"wide runes", "修正しました 🐛🐛🐛🐛🐛🐛🐛🐛🐛🐛 日本語のテキスト", 12},
+		{"long emoji word", "🚀🚀🚀🚀🚀🚀🚀🚀🚀🚀🚀🚀🚀🚀🚀🚀", 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped := wordWrap(tt.text, tt.maxWidth)
+			for _, line := range strings.Split(wrapped, "\n") {
+				if w := lipgloss.Width(line); w > tt.maxWidth {
+					t.Errorf("line %q is %d cells wide, want at most %d", line, w, tt.maxWidth)
+				}
+			}
+			if got, want := strings.Join(strings.Fields(wrapped), ""), strings.Join(strings.Fields(tt.text), ""); got != want {
+				t.Errorf("wrapping changed the text: %q", wrapped)
+			}
+		})
+	}
+}
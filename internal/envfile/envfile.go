@@ -0,0 +1,73 @@
+// Package envfile reads dotenv-style KEY=value files
+package envfile
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Read reads KEY=value assignments from a dotenv-style file. Blank lines,
+// # comments and a leading "export " are ignored, and values may be quoted.
+// Errors name the offending line but never its value.
+func Read(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var env []string
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		name = strings.TrimSpace(name)
+		if !ok || !validEnvName(name) {
+			return nil, fmt.Errorf("%s:%d: expected KEY=value", path, lineNum)
+		}
+		env = append(env, name+"="+unquoteEnvValue(strings.TrimSpace(value)))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+// ParseAssignment checks a KEY=value assignment given on the command line.
+// Like Read, its error never includes the value.
+func ParseAssignment(assignment string) (string, error) {
+	name, value, ok := strings.Cut(assignment, "=")
+	if !ok || !validEnvName(name) {
+		return "", fmt.Errorf("invalid environment variable %q: expected KEY=value", name)
+	}
+	return name + "=" + value, nil
+}
+
+// validEnvName reports whether name is usable as an environment variable name
+func validEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// unquoteEnvValue strips one pair of matching single or double quotes
+func unquoteEnvValue(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
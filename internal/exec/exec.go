@@ -0,0 +1,224 @@
+// Package exec runs external commands (gh, git, docker) with logging, and
+// classifies their failures so callers can tell a missing binary or an
+// expired login from an ordinary error.
+package exec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	osexec "os/exec"
+	"strings"
+	"time"
+
+	"github.com/DylanSharp/dtools/internal/logging"
+)
+
+// Kind classifies why an external command failed
+type Kind int
+
+const (
+	KindGeneric   Kind = iota // Exited non-zero for any other reason
+	KindNotFound              // The binary isn't on the PATH
+	KindAuth                  // Not logged in, or credentials were rejected
+	KindRateLimit             // The remote API is rate limiting
+)
+
+// String returns the kind's name
+func (k Kind) String() string {
+	switch k {
+	case KindNotFound:
+		return "not_found"
+	case KindAuth:
+		return "auth"
+	case KindRateLimit:
+		return "rate_limit"
+	default:
+		return "generic"
+	}
+}
+
+// authMarkers and rateLimitMarkers are lowercase stderr fragments gh and git
+// print for those failures
+var (
+	authMarkers = []string{
+		"gh auth login",
+		"authentication failed",
+		"authentication required",
+		"bad credentials",
+		"http 401",
+		"permission denied (publickey)",
+		"could not read username",
+	}
+	rateLimitMarkers = []string{
+		"rate limit",
+		"http 429",
+		"too many requests",
+	}
+)
+
+// Error is a failed external command
+type Error struct {
+	Name     string // Binary that was run
+	Kind     Kind
+	ExitCode int    // -1 if the process never ran
+	Stderr   string // Trimmed standard error
+	Err      error
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	switch {
+	case e.Kind == KindNotFound:
+		return fmt.Sprintf("%s not found in PATH", e.Name)
+	case e.Stderr != "":
+		return fmt.Sprintf("%s command failed: %s", e.Name, e.Stderr)
+	default:
+		return fmt.Sprintf("%s command failed: %v", e.Name, e.Err)
+	}
+}
+
+// Unwrap returns the underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// KindOf returns how err's command failed, or KindGeneric if err isn't an *Error
+func KindOf(err error) Kind {
+	var execErr *Error
+	if errors.As(err, &execErr) {
+		return execErr.Kind
+	}
+	return KindGeneric
+}
+
+// IsNotFound returns true if err is from a command whose binary isn't installed
+func IsNotFound(err error) bool {
+	return KindOf(err) == KindNotFound
+}
+
+// IsAuth returns true if err is from a command that wasn't logged in
+func IsAuth(err error) bool {
+	return KindOf(err) == KindAuth
+}
+
+// IsRateLimit returns true if err is from a command that was rate limited
+func IsRateLimit(err error) bool {
+	return KindOf(err) == KindRateLimit
+}
+
+// Available returns true if the named binary is on the PATH
+func Available(name string) bool {
+	_, err := osexec.LookPath(name)
+	return err == nil
+}
+
+// Cmd is an external command to run. Standard error is always captured for
+// classifying failures, and is also copied to Stderr if set.
+type Cmd struct {
+	Name   string
+	Args   []string
+	Dir    string
+	Env    []string // Added to the current environment
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Command returns a Cmd that runs name with args
+func Command(name string, args ...string) *Cmd {
+	return &Cmd{Name: name, Args: args}
+}
+
+// Output runs name with args and returns its standard output
+func Output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return Command(name, args...).Output(ctx)
+}
+
+// Run runs name with args, discarding its output
+func Run(ctx context.Context, name string, args ...string) error {
+	return Command(name, args...).Run(ctx)
+}
+
+// Output runs the command and returns its standard output. Stdout must not be set.
+func (c *Cmd) Output(ctx context.Context) ([]byte, error) {
+	if c.Stdout != nil {
+		return nil, errors.New("exec: Stdout already set")
+	}
+	var stdout bytes.Buffer
+	err := c.run(ctx, &stdout)
+	return stdout.Bytes(), err
+}
+
+// Run runs the command, sending its standard output to Stdout if set
+func (c *Cmd) Run(ctx context.Context) error {
+	return c.run(ctx, c.Stdout)
+}
+
+func (c *Cmd) run(ctx context.Context, stdout io.Writer) error {
+	cmd := osexec.CommandContext(ctx, c.Name, c.Args...)
+	cmd.Dir = c.Dir
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+	cmd.Stdout = stdout
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if c.Stderr != nil {
+		cmd.Stderr = io.MultiWriter(c.Stderr, &stderr)
+	}
+
+	start := time.Now()
+	err := cmd.Run()
+
+	// Hand stderr to the exit error so the log shows it
+	var exitErr *osexec.ExitError
+	if errors.As(err, &exitErr) {
+		exitErr.Stderr = stderr.Bytes()
+	}
+	logging.Finished(cmd, err, time.Since(start))
+
+	if err != nil {
+		return newError(c.Name, err, stderr.String())
+	}
+	return nil
+}
+
+// newError classifies a command failure from its error and standard error
+func newError(name string, err error, stderr string) *Error {
+	execErr := &Error{
+		Name:     name,
+		ExitCode: -1,
+		Stderr:   strings.TrimSpace(stderr),
+		Err:      err,
+	}
+
+	var exitErr *osexec.ExitError
+	if errors.As(err, &exitErr) {
+		execErr.ExitCode = exitErr.ExitCode()
+	}
+
+	lower := strings.ToLower(stderr)
+	switch {
+	case errors.Is(err, osexec.ErrNotFound):
+		execErr.Kind = KindNotFound
+	case containsAny(lower, rateLimitMarkers):
+		execErr.Kind = KindRateLimit
+	case containsAny(lower, authMarkers):
+		execErr.Kind = KindAuth
+	}
+	return execErr
+}
+
+// containsAny returns true if s contains any of the substrings
+func containsAny(s string, substrings []string) bool {
+	for _, sub := range substrings {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
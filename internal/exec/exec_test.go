@@ -0,0 +1,151 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeCommand puts a shell script named name on an otherwise empty PATH
+func fakeCommand(t *testing.T, name, script string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+}
+
+func TestOutput(t *testing.T) {
+	fakeCommand(t, "fake", `echo "args: $*"; echo "warning" >&2`)
+
+	out, err := Output(context.Background(), "fake", "a", "b c")
+	if err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	if got := string(out); got != "args: a b c\n" {
+		t.Errorf("Output() = %q, want only stdout", got)
+	}
+}
+
+func TestCmdDirEnvAndStderr(t *testing.T) {
+	fakeCommand(t, "fake", `pwd; echo "$FAKE_VALUE"; echo "progress" >&2`)
+	dir := t.TempDir()
+
+	var stderr strings.Builder
+	cmd := Command("fake")
+	cmd.Dir = dir
+	cmd.Env = []string{"FAKE_VALUE=set"}
+	cmd.Stderr = &stderr
+	out, err := cmd.Output(context.Background())
+	if err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+
+	resolved, _ := filepath.EvalSymlinks(dir)
+	if want := resolved + "\nset\n"; string(out) != want {
+		t.Errorf("Output() = %q, want %q", out, want)
+	}
+	if stderr.String() != "progress\n" {
+		t.Errorf("Stderr = %q, want it copied", stderr.String())
+	}
+}
+
+func TestOutputRejectsStdout(t *testing.T) {
+	cmd := Command("fake")
+	cmd.Stdout = &strings.Builder{}
+	if _, err := cmd.Output(context.Background()); err == nil {
+		t.Error("Output() with Stdout set succeeded, want an error")
+	}
+}
+
+func TestErrorKinds(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		want     Kind
+		exitCode int
+		message  string
+	}{
+		{
+			name:     "generic",
+			script:   `echo "fatal: not a git repository" >&2; exit 128`,
+			want:     KindGeneric,
+			exitCode: 128,
+			message:  "fake command failed: fatal: not a git repository",
+		},
+		{
+			name:     "auth",
+			script:   `echo "To get started with GitHub CLI, please run:  gh auth login" >&2; exit 4`,
+			want:     KindAuth,
+			exitCode: 4,
+		},
+		{
+			name:     "rate limit",
+			script:   `echo "gh: API rate limit exceeded (HTTP 403)" >&2; exit 1`,
+			want:     KindRateLimit,
+			exitCode: 1,
+		},
+		{
+			name:     "rate limit wins over auth",
+			script:   `echo "HTTP 429: Too Many Requests, authentication required" >&2; exit 1`,
+			want:     KindRateLimit,
+			exitCode: 1,
+		},
+		{
+			name:     "no stderr",
+			script:   `exit 3`,
+			want:     KindGeneric,
+			exitCode: 3,
+			message:  "fake command failed: exit status 3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeCommand(t, "fake", tt.script)
+
+			err := Run(context.Background(), "fake")
+			var execErr *Error
+			if !errors.As(err, &execErr) {
+				t.Fatalf("Run() error = %v, want an *Error", err)
+			}
+			if execErr.Kind != tt.want || KindOf(err) != tt.want {
+				t.Errorf("Kind = %v, want %v", execErr.Kind, tt.want)
+			}
+			if execErr.ExitCode != tt.exitCode {
+				t.Errorf("ExitCode = %d, want %d", execErr.ExitCode, tt.exitCode)
+			}
+			if tt.message != "" && err.Error() != tt.message {
+				t.Errorf("Error() = %q, want %q", err.Error(), tt.message)
+			}
+		})
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	err := Run(context.Background(), "missing")
+	if !IsNotFound(err) {
+		t.Fatalf("Run() error = %v, want not found", err)
+	}
+	if err.Error() != "missing not found in PATH" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+	if IsAuth(err) || IsRateLimit(err) {
+		t.Error("a missing binary is classified as another kind too")
+	}
+	if Available("missing") {
+		t.Error("Available() = true for a missing binary")
+	}
+}
+
+func TestKindOfOtherErrors(t *testing.T) {
+	if got := KindOf(errors.New("boom")); got != KindGeneric {
+		t.Errorf("KindOf() = %v, want generic", got)
+	}
+}
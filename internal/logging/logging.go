@@ -0,0 +1,187 @@
+// Package logging provides the leveled debug logger enabled by dtools --verbose.
+// Logging is off by default; when enabled it writes to stderr, or to a log file
+// while a TUI owns the terminal.
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DylanSharp/dtools/internal/statedir"
+)
+
+// maxArgLen caps how much of a single command argument is logged, so multi-KB
+// prompts passed on the command line don't flood the log
+const maxArgLen = 200
+
+var (
+	mu      sync.RWMutex
+	logger  = slog.New(slog.NewTextHandler(io.Discard, nil))
+	enabled bool
+)
+
+// Init enables or disables verbose logging to stderr
+func Init(verbose bool) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	enabled = verbose
+	if verbose {
+		logger = newLogger(os.Stderr)
+	} else {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+}
+
+// Enabled reports whether verbose logging is on
+func Enabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return enabled
+}
+
+// LogToFile redirects verbose logging to <config dir>/dtools/logs/dtools.log so it
+// doesn't corrupt a TUI. It returns the log file path, or "" if logging is off.
+func LogToFile() (string, error) {
+	return LogToFileIn(filepath.Join(statedir.ConfigDir(), "logs"))
+}
+
+// LogToFileIn is LogToFile with the log file in dir instead
+func LogToFileIn(dir string) (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if !enabled {
+		return "", nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	path := filepath.Join(dir, "dtools.log")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	logger = newLogger(f)
+	return path, nil
+}
+
+func newLogger(w io.Writer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func current() *slog.Logger {
+	mu.RLock()
+	defer mu.RUnlock()
+	return logger
+}
+
+// Debug logs a debug message with key/value pairs
+func Debug(msg string, args ...any) {
+	current().Debug(msg, args...)
+}
+
+// Info logs an informational message with key/value pairs
+func Info(msg string, args ...any) {
+	current().Info(msg, args...)
+}
+
+// Warn logs a warning with key/value pairs
+func Warn(msg string, args ...any) {
+	current().Warn(msg, args...)
+}
+
+// Error logs an error with key/value pairs
+func Error(msg string, args ...any) {
+	current().Error(msg, args...)
+}
+
+// Started logs that a long-running external command was started
+func Started(cmd *exec.Cmd) {
+	if !Enabled() {
+		return
+	}
+	current().Debug("exec start", "cmd", FormatCommand(cmd), "dir", cmd.Dir)
+}
+
+// Finished logs an external command's exit status and duration
+func Finished(cmd *exec.Cmd, err error, elapsed time.Duration) {
+	if !Enabled() {
+		return
+	}
+
+	args := []any{
+		"cmd", FormatCommand(cmd),
+		"exit", exitCode(err),
+		"duration", elapsed.Round(time.Millisecond),
+	}
+	if cmd.Dir != "" {
+		args = append(args, "dir", cmd.Dir)
+	}
+	if err != nil {
+		args = append(args, "err", err.Error())
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			args = append(args, "stderr", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		current().Warn("exec", args...)
+		return
+	}
+	current().Debug("exec", args...)
+}
+
+// Run runs cmd and logs it
+func Run(cmd *exec.Cmd) error {
+	start := time.Now()
+	err := cmd.Run()
+	Finished(cmd, err, time.Since(start))
+	return err
+}
+
+// Output runs cmd, logs it and returns its stdout
+func Output(cmd *exec.Cmd) ([]byte, error) {
+	start := time.Now()
+	out, err := cmd.Output()
+	Finished(cmd, err, time.Since(start))
+	return out, err
+}
+
+// FormatCommand renders a command line for logging, quoting arguments with
+// spaces and abbreviating very long ones
+func FormatCommand(cmd *exec.Cmd) string {
+	parts := make([]string, 0, len(cmd.Args))
+	for _, arg := range cmd.Args {
+		if len(arg) > maxArgLen {
+			arg = fmt.Sprintf("%s...(%d bytes)", arg[:maxArgLen], len(arg))
+		}
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'") {
+			arg = fmt.Sprintf("%q", arg)
+		}
+		parts = append(parts, arg)
+	}
+	return strings.Join(parts, " ")
+}
+
+// exitCode returns the process exit code for err, 0 for success and -1 if the
+// process never ran
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
@@ -2,42 +2,75 @@ package adapters
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"os"
 	"os/exec"
 	"regexp"
 	"strings"
+	"time"
 
+	"github.com/DylanSharp/dtools/internal/aicmd"
 	"github.com/DylanSharp/dtools/internal/ralph/domain"
 	"github.com/DylanSharp/dtools/internal/ralph/ports"
+	"github.com/DylanSharp/dtools/internal/logging"
 )
 
-// ClaudeExecutor implements ports.Executor using the Claude CLI
+// ClaudeExecutor implements ports.Executor using the Claude CLI,
+// or any CLI that accepts a prompt and emits compatible stream-json
 type ClaudeExecutor struct {
-	binaryPath    string
+	command       aicmd.Command
+	timeout       time.Duration
+	env           []string
 	promptBuilder *PromptBuilder
 }
 
 // NewClaudeExecutor creates a new Claude executor
 func NewClaudeExecutor() *ClaudeExecutor {
 	return &ClaudeExecutor{
-		binaryPath:    "claude",
+		command:       aicmd.Default(),
+		timeout:       aicmd.DefaultTimeout,
 		promptBuilder: NewPromptBuilder(),
 	}
 }
 
 // NewClaudeExecutorWithPath creates a new executor with a custom binary path
 func NewClaudeExecutorWithPath(binaryPath string) *ClaudeExecutor {
+	command := aicmd.Default()
+	command.Binary = binaryPath
 	return &ClaudeExecutor{
-		binaryPath:    binaryPath,
+		command:       command,
+		timeout:       aicmd.DefaultTimeout,
 		promptBuilder: NewPromptBuilder(),
 	}
 }
 
-// IsAvailable checks if the Claude CLI is available
+// NewClaudeExecutorWithCommand creates an executor that runs a custom AI command
+func NewClaudeExecutorWithCommand(command aicmd.Command) *ClaudeExecutor {
+	return &ClaudeExecutor{
+		command:       command,
+		timeout:       aicmd.DefaultTimeout,
+		promptBuilder: NewPromptBuilder(),
+	}
+}
+
+// SetTimeout sets the deadline for a single story run (0 disables it)
+func (e *ClaudeExecutor) SetTimeout(timeout time.Duration) {
+	e.timeout = timeout
+}
+
+// SetEnv sets KEY=value assignments added to the AI CLI's environment, such as
+// credentials a project's tests need. Later assignments win; values are never logged.
+func (e *ClaudeExecutor) SetEnv(env []string) {
+	e.env = env
+}
+
+// IsAvailable checks if the AI CLI is available
 func (e *ClaudeExecutor) IsAvailable() bool {
-	_, err := exec.LookPath(e.binaryPath)
-	return err == nil
+	return e.command.IsAvailable()
 }
 
 // Execute runs a story and returns a channel of execution events
@@ -49,33 +82,46 @@ func (e *ClaudeExecutor) Execute(ctx context.Context, story *domain.Story, execC
 	// Build the prompt
 	prompt := e.promptBuilder.BuildStoryPrompt(story, execCtx)
 
-	// Build the Claude command with streaming JSON output
-	cmd := exec.CommandContext(ctx, e.binaryPath,
-		"-p",
-		"--dangerously-skip-permissions",
-		"--output-format", "stream-json",
-		"--",
-		prompt,
-	)
+	// Snapshot the work tree so the files the run changes can be reported
+	snapshot := takeWorkTreeSnapshot(ctx, execCtx.WorkDir)
+
+	// Bound the story so a hung process can't block forever; the process is killed on expiry
+	parentCtx := ctx
+	cancel := context.CancelFunc(func() {})
+	if e.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+	}
+
+	// Build the AI command with streaming JSON output
+	cmd := exec.CommandContext(ctx, e.command.Binary, e.command.BuildArgs(prompt)...)
 
 	// Set working directory
 	if execCtx.WorkDir != "" {
 		cmd.Dir = execCtx.WorkDir
 	}
+	if len(e.env) > 0 {
+		cmd.Env = append(os.Environ(), e.env...)
+	}
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
+		cancel()
 		return nil, domain.ErrClaudeError("failed to create stdout pipe", err)
 	}
 
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
+		cancel()
 		return nil, domain.ErrClaudeError("failed to create stderr pipe", err)
 	}
 
+	start := time.Now()
 	if err := cmd.Start(); err != nil {
+		cancel()
+		logging.Finished(cmd, err, time.Since(start))
 		return nil, domain.ErrClaudeError("failed to start Claude CLI", err)
 	}
+	logging.Started(cmd)
 
 	events := make(chan domain.ExecutionEvent, 100)
 
@@ -97,39 +143,53 @@ func (e *ClaudeExecutor) Execute(ctx context.Context, story *domain.Story, execC
 	// Read JSONL from stdout and convert to events
 	go func() {
 		defer close(events)
+		defer cancel()
 
 		// Send story started event
 		events <- domain.NewStoryStartedEvent(story)
 
-		scanner := bufio.NewScanner(stdout)
-		// Increase buffer size for potentially large JSON objects
-		buf := make([]byte, 64*1024)
-		scanner.Buffer(buf, 1024*1024)
+		// A Scanner caps line length, and one large message (such as a file
+		// dump) would abort the story, so read whole lines however long
+		reader := bufio.NewReaderSize(stdout, 64*1024)
+		var readErr error
 
 		parser := NewStreamParser()
 
-		for scanner.Scan() {
+		for {
+			line, err := reader.ReadBytes('\n')
+			if err != nil && err != io.EOF {
+				readErr = err
+			}
+			if len(line) == 0 && err != nil {
+				break
+			}
+
 			// Check for context cancellation
 			select {
 			case <-ctx.Done():
 				// Kill the process and clean up
 				cmd.Process.Kill()
 				<-stderrDone // Wait for stderr goroutine
-				cmd.Wait()
-				events <- domain.NewErrorEvent(story.ID, "execution cancelled")
+				logging.Finished(cmd, cmd.Wait(), time.Since(start))
+				if parentCtx.Err() == nil {
+					events <- domain.NewTimeoutEvent(story.ID, domain.ErrClaudeTimeout(e.timeout.String()))
+				} else {
+					events <- domain.NewErrorEvent(story.ID, "execution cancelled")
+				}
 				return
 			default:
 			}
 
-			line := scanner.Bytes()
-			if len(line) == 0 {
-				continue
+			line = bytes.TrimRight(line, "\r\n")
+			if len(line) > 0 {
+				// Parse the stream chunk
+				for _, event := range parser.ParseChunk(line, story.ID) {
+					events <- event
+				}
 			}
 
-			// Parse the stream chunk
-			event := parser.ParseChunk(line, story.ID)
-			if event != nil {
-				events <- *event
+			if err != nil {
+				break
 			}
 		}
 
@@ -138,13 +198,33 @@ func (e *ClaudeExecutor) Execute(ctx context.Context, story *domain.Story, execC
 
 		// Always wait for the command to finish
 		cmdErr := cmd.Wait()
+		logging.Finished(cmd, cmdErr, time.Since(start))
 
-		if err := scanner.Err(); err != nil {
-			events <- domain.NewErrorEvent(story.ID, err.Error())
+		// A timed-out story is not completed
+		if ctx.Err() == context.DeadlineExceeded && parentCtx.Err() == nil {
+			events <- domain.NewTimeoutEvent(story.ID, domain.ErrClaudeTimeout(e.timeout.String()))
+			return
+		}
+
+		// Nor is one whose process was killed because the run or story was cancelled
+		if parentCtx.Err() != nil {
+			events <- domain.NewErrorEvent(story.ID, "execution cancelled")
+			return
+		}
+
+		if readErr != nil {
+			events <- domain.NewErrorEvent(story.ID, readErr.Error())
 		} else if cmdErr != nil {
 			events <- domain.NewErrorEvent(story.ID, "command failed: "+cmdErr.Error())
 		}
 
+		// Record the files the run changed; they're persisted with the story
+		var filesChanged []string
+		if snapshot != nil {
+			filesChanged, _ = snapshot.ChangedSince(parentCtx)
+		}
+		story.SetFilesChanged(filesChanged)
+
 		// Send story completed event
 		events <- domain.NewStoryCompletedEvent(story)
 	}()
@@ -189,7 +269,13 @@ func (b *PromptBuilder) BuildStoryPrompt(story *domain.Story, execCtx ports.Exec
 	sb.WriteString("\n")
 	sb.WriteString("**Priority:** ")
 	sb.WriteString(strings.Repeat("!", story.Priority))
-	sb.WriteString("\n\n")
+	sb.WriteString("\n")
+	for _, key := range story.DeclaredMetadataKeys() {
+		sb.WriteString("**" + key + ":** ")
+		sb.WriteString(story.Metadata[key])
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
 
 	if story.Description != "" {
 		sb.WriteString("### Description\n")
@@ -243,6 +329,17 @@ func (b *PromptBuilder) BuildStoryPrompt(story *domain.Story, execCtx ports.Exec
 		sb.WriteString("\n\n")
 	}
 
+	// Continuation of an unfinished session
+	if execCtx.Pass > 1 {
+		sb.WriteString("## Continuing Previous Work\n")
+		sb.WriteString(fmt.Sprintf("This is session %d on this story. An earlier session worked on it but didn't finish.\n", execCtx.Pass))
+		sb.WriteString("Check the current state of the code and continue where it left off, without redoing finished work.\n\n")
+		if execCtx.PreviousProgress != "" {
+			sb.WriteString(execCtx.PreviousProgress)
+			sb.WriteString("\n\n")
+		}
+	}
+
 	// Instructions
 	sb.WriteString("## Instructions\n\n")
 	sb.WriteString("1. Read and understand the current story requirements\n")
@@ -252,7 +349,8 @@ func (b *PromptBuilder) BuildStoryPrompt(story *domain.Story, execCtx ports.Exec
 	sb.WriteString("5. Handle errors gracefully\n")
 	sb.WriteString("6. Keep changes focused on the current story\n\n")
 
-	sb.WriteString("When you have completed all acceptance criteria, clearly state that the story is complete.\n")
+	sb.WriteString("When you have completed all acceptance criteria, clearly state that the story is complete")
+	sb.WriteString(" and end your final message with a line containing only " + domain.StoryCompleteMarker + ".\n")
 
 	return sb.String()
 }
@@ -290,61 +388,61 @@ type AssistantMessage struct {
 
 // ContentBlock represents a content block in the message
 type ContentBlock struct {
-	Type     string `json:"type"`
-	Text     string `json:"text,omitempty"`
-	Thinking string `json:"thinking,omitempty"`
+	Type     string          `json:"type"` // "text", "thinking" or "tool_use"
+	Text     string          `json:"text,omitempty"`
+	Thinking string          `json:"thinking,omitempty"`
+	Name     string          `json:"name,omitempty"`  // Tool name, for tool_use
+	Input    json.RawMessage `json:"input,omitempty"` // Tool input, for tool_use
 }
 
-// ParseChunk parses a JSONL line and returns an execution event
-func (p *StreamParser) ParseChunk(line []byte, storyID string) *domain.ExecutionEvent {
+// ParseChunk parses a JSONL line and returns a thought event for each content block
+func (p *StreamParser) ParseChunk(line []byte, storyID string) []domain.ExecutionEvent {
 	var chunk StreamChunk
 	if err := json.Unmarshal(line, &chunk); err != nil {
 		return nil
 	}
 
-	// Extract text content
-	text := p.getText(&chunk)
-	if text == "" {
-		return nil
+	if chunk.Type == "result" && chunk.Result != "" {
+		return []domain.ExecutionEvent{p.textEvent(storyID, chunk.Result, p.classifyThought(chunk.Result))}
 	}
-
-	// Determine thought type
-	thoughtType := p.classifyThought(text)
-
-	// Extract file reference if present
-	file := p.extractFile(text)
-
-	event := domain.NewThoughtEvent(storyID, text, thoughtType)
-	if file != "" {
-		event = event.WithFile(file)
+	if chunk.Type != "assistant" || chunk.Message == nil {
+		return nil
 	}
 
-	return &event
-}
-
-// getText extracts text content from a chunk
-func (p *StreamParser) getText(chunk *StreamChunk) string {
-	if chunk.Type == "assistant" && chunk.Message != nil {
-		var text strings.Builder
-		for _, block := range chunk.Message.Content {
-			if block.Type == "text" && block.Text != "" {
-				text.WriteString(block.Text)
+	// The block type says what Claude is doing; keywords are only a guess at
+	// what kind of message plain text is
+	var events []domain.ExecutionEvent
+	for _, block := range chunk.Message.Content {
+		switch block.Type {
+		case "thinking":
+			if block.Thinking != "" {
+				events = append(events, p.textEvent(storyID, block.Thinking, domain.ThoughtTypeThinking))
+			}
+		case "tool_use":
+			event := domain.NewThoughtEvent(storyID, aicmd.DescribeToolUse(block.Name, block.Input), domain.ThoughtTypeTool)
+			if file := aicmd.ToolUseFile(block.Input); file != "" {
+				event = event.WithFile(file)
 			}
-			if block.Type == "thinking" && block.Thinking != "" {
-				text.WriteString(block.Thinking)
+			events = append(events, event)
+		case "text":
+			if block.Text != "" {
+				events = append(events, p.textEvent(storyID, block.Text, p.classifyThought(block.Text)))
 			}
 		}
-		return text.String()
 	}
+	return events
+}
 
-	if chunk.Type == "result" && chunk.Result != "" {
-		return chunk.Result
+// textEvent creates a thought event for text, with any file it mentions
+func (p *StreamParser) textEvent(storyID, text string, thoughtType domain.ThoughtType) domain.ExecutionEvent {
+	event := domain.NewThoughtEvent(storyID, text, thoughtType)
+	if file := p.extractFile(text); file != "" {
+		event = event.WithFile(file)
 	}
-
-	return ""
+	return event
 }
 
-// classifyThought determines the type of thought based on content
+// classifyThought guesses the type of a text message from its content
 func (p *StreamParser) classifyThought(text string) domain.ThoughtType {
 	lower := strings.ToLower(text)
 
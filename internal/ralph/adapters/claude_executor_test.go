@@ -0,0 +1,69 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DylanSharp/dtools/internal/aicmd"
+	"github.com/DylanSharp/dtools/internal/ralph/domain"
+	"github.com/DylanSharp/dtools/internal/ralph/ports"
+)
+
+func TestExecuteReadsLongStreamLines(t *testing.T) {
+	dir := t.TempDir()
+
+	// One assistant message well past bufio.Scanner's 64KB and 1MB limits,
+	// followed by the run's result
+	long := strings.Repeat("x", 2<<20)
+	var stream strings.Builder
+	for _, chunk := range []StreamChunk{
+		{Type: "assistant", Message: &AssistantMessage{Content: []ContentBlock{{Type: "text", Text: long}}}},
+		{Type: "result", Result: "done"},
+	} {
+		line, err := json.Marshal(chunk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stream.Write(line)
+		stream.WriteString("\n")
+	}
+	streamPath := filepath.Join(dir, "stream.jsonl")
+	if err := os.WriteFile(streamPath, []byte(stream.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	binary := filepath.Join(dir, "claude")
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\ncat '"+streamPath+"'\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	executor := NewClaudeExecutorWithCommand(aicmd.Command{Binary: binary})
+	story := domain.NewStory("1", "Long output")
+	events, err := executor.Execute(context.Background(), story, ports.ExecutionContext{WorkDir: dir})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	var thoughts []string
+	var completed bool
+	for event := range events {
+		switch event.Type {
+		case domain.EventTypeThought:
+			thoughts = append(thoughts, event.Content)
+		case domain.EventTypeError:
+			t.Errorf("unexpected error event: %s", event.Content)
+		case domain.EventTypeStoryCompleted:
+			completed = true
+		}
+	}
+
+	if len(thoughts) != 2 || thoughts[0] != long || thoughts[1] != "done" {
+		t.Errorf("got %d thoughts, want the long message whole and then the result", len(thoughts))
+	}
+	if !completed {
+		t.Error("story never completed")
+	}
+}
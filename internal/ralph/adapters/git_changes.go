@@ -0,0 +1,103 @@
+package adapters
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/DylanSharp/dtools/internal/logging"
+)
+
+// workTreeSnapshot records the content of every file that differs from a base
+// commit, so comparing snapshots taken before and after a story run gives the
+// files the run changed, including ones it committed
+type workTreeSnapshot struct {
+	root  string
+	base  string
+	files map[string]string // Path relative to root -> content hash ("" if deleted)
+}
+
+// takeWorkTreeSnapshot snapshots the git work tree containing dir against HEAD.
+// It returns nil if dir isn't in a git repository with at least one commit.
+func takeWorkTreeSnapshot(ctx context.Context, dir string) *workTreeSnapshot {
+	root, err := gitOutput(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil
+	}
+	base, err := gitOutput(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil
+	}
+	snapshot := &workTreeSnapshot{root: strings.TrimSpace(root), base: strings.TrimSpace(base)}
+	if err := snapshot.scan(ctx); err != nil {
+		return nil
+	}
+	return snapshot
+}
+
+// ChangedSince returns the files whose content differs between an earlier
+// snapshot and a fresh scan of the work tree
+func (s *workTreeSnapshot) ChangedSince(ctx context.Context) ([]string, error) {
+	after := &workTreeSnapshot{root: s.root, base: s.base}
+	if err := after.scan(ctx); err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for path, hash := range after.files {
+		if before, ok := s.files[path]; !ok || before != hash {
+			changed = append(changed, path)
+		}
+	}
+	// A file that was dirty before and is back to the base content changed too
+	for path := range s.files {
+		if _, ok := after.files[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// scan records the files that differ from the base commit, plus untracked files
+func (s *workTreeSnapshot) scan(ctx context.Context) error {
+	diff, err := gitOutput(ctx, s.root, "diff", "--name-only", "-z", s.base)
+	if err != nil {
+		return err
+	}
+	untracked, err := gitOutput(ctx, s.root, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return err
+	}
+
+	s.files = make(map[string]string)
+	for _, path := range strings.Split(diff+untracked, "\x00") {
+		if path == "" {
+			continue
+		}
+		s.files[path] = hashFile(filepath.Join(s.root, path))
+	}
+	return nil
+}
+
+// hashFile returns a hash of a file's content, or "" if it can't be read
+func hashFile(path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(content)
+	return string(sum[:])
+}
+
+// gitOutput runs a git command in dir and returns its stdout
+func gitOutput(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	out, err := logging.Output(cmd)
+	return string(out), err
+}
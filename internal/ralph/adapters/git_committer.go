@@ -0,0 +1,67 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/DylanSharp/dtools/internal/logging"
+	"github.com/DylanSharp/dtools/internal/ralph/domain"
+)
+
+// GitCommitter implements ports.Committer with git, committing each completed
+// story as "[STORY-ID] title"
+type GitCommitter struct {
+	push bool
+}
+
+// NewGitCommitter creates a new git committer
+func NewGitCommitter() *GitCommitter {
+	return &GitCommitter{}
+}
+
+// SetPush makes the committer push after each story commit
+func (c *GitCommitter) SetPush(push bool) {
+	c.push = push
+}
+
+// CommitStory stages and commits every change in workDir, then pushes if enabled
+func (c *GitCommitter) CommitStory(ctx context.Context, workDir string, story *domain.Story) (bool, error) {
+	if _, err := c.git(ctx, workDir, "add", "-A"); err != nil {
+		return false, domain.ErrCommit("failed to stage changes", err)
+	}
+
+	// diff --quiet exits 1 when something is staged
+	if _, err := c.git(ctx, workDir, "diff", "--cached", "--quiet"); err == nil {
+		return false, nil
+	} else if exitErr := (*exec.ExitError)(nil); !errors.As(err, &exitErr) {
+		return false, domain.ErrCommit("failed to check for staged changes", err)
+	}
+
+	message := fmt.Sprintf("[%s] %s", story.ID, story.Title)
+	if _, err := c.git(ctx, workDir, "commit", "-m", message); err != nil {
+		return false, domain.ErrCommit("failed to commit story "+story.ID, err)
+	}
+
+	if c.push {
+		if _, err := c.git(ctx, workDir, "push"); err != nil {
+			return true, domain.ErrCommit("committed story "+story.ID+" but failed to push", err)
+		}
+	}
+
+	return true, nil
+}
+
+// git runs a git command in dir, folding the first line of stderr into the error on failure
+func (c *GitCommitter) git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	out, err := logging.Output(cmd)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return string(out), fmt.Errorf("%w: %s", err, strings.SplitN(strings.TrimSpace(string(exitErr.Stderr)), "\n", 2)[0])
+	}
+	return string(out), err
+}
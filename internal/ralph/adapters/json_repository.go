@@ -8,6 +8,7 @@ import (
 
 	"github.com/DylanSharp/dtools/internal/ralph/domain"
 	"github.com/DylanSharp/dtools/internal/ralph/ports"
+	"github.com/DylanSharp/dtools/internal/statedir"
 )
 
 // JSONRepository implements ports.Repository using JSON files
@@ -17,13 +18,9 @@ type JSONRepository struct {
 
 // NewJSONRepository creates a new JSON-based repository
 func NewJSONRepository() (*JSONRepository, error) {
-	// Use ~/.config/dtools/ralph/projects/ for state
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, domain.ErrStatePersistence("init", err)
-	}
-
-	stateDir := filepath.Join(homeDir, ".config", "dtools", "ralph", "projects")
+	// Use ~/.config/dtools/ralph/projects/ (or under $DTOOLS_STATE_DIR) for state
+	statedir.MigrateLegacy("ralph")
+	stateDir := filepath.Join(statedir.Dir(), "ralph", "projects")
 	if err := os.MkdirAll(stateDir, 0755); err != nil {
 		return nil, domain.ErrStatePersistence("init", err)
 	}
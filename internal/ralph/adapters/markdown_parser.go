@@ -2,6 +2,7 @@ package adapters
 
 import (
 	"bufio"
+	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -64,7 +65,10 @@ func (p *MarkdownPRDParser) Parse(path string) (*domain.Project, error) {
 	storyHeaderRegex := regexp.MustCompile(`^###?\s*(?:Story:?\s*)?\[?([A-Z0-9_-]+)\]?\s*[:\-]?\s*(.*)$`)
 	priorityRegex := regexp.MustCompile(`(?i)\*\*priority\*\*:\s*(\d+)`)
 	dependsOnRegex := regexp.MustCompile(`(?i)\*\*depends?\s*on\*\*:\s*\[([^\]]*)\]`)
+	criterionRegex := regexp.MustCompile(`^[-*]\s*(?:\[([ xX])\]\s*)?(.*)$`)
 	statusRegex := regexp.MustCompile(`(?i)\*\*status\*\*:\s*(\w+)`)
+	criticalRegex := regexp.MustCompile(`(?i)^\*\*critical:?\*\*:?\s*(\w*)`)
+	metadataRegex := regexp.MustCompile(`^\*\*([A-Za-z][A-Za-z0-9 _-]*?):?\*\*:?\s*(.+)$`)
 
 	lineNum := 0
 	for scanner.Scan() {
@@ -146,10 +150,19 @@ func (p *MarkdownPRDParser) Parse(path string) (*domain.Project, error) {
 				continue
 			}
 
-			// Parse depends_on
+			// Parse depends_on, expanding shorthand against the stories so far
 			if matches := dependsOnRegex.FindStringSubmatch(trimmedLine); len(matches) >= 2 {
-				deps := parseDependencyList(matches[1])
-				currentStory.DependsOn = deps
+				previous := make([]string, 0, len(project.Stories))
+				for _, story := range project.Stories {
+					previous = append(previous, story.ID)
+				}
+				currentStory.DependsOn = parseDependencyList(matches[1], previous)
+				continue
+			}
+
+			// Parse critical flag; a bare **Critical** counts as yes
+			if matches := criticalRegex.FindStringSubmatch(trimmedLine); len(matches) >= 2 {
+				currentStory.Critical = parseCritical(matches[1])
 				continue
 			}
 
@@ -160,15 +173,25 @@ func (p *MarkdownPRDParser) Parse(path string) (*domain.Project, error) {
 				continue
 			}
 
+			// Parse other **Key:** value fields (labels, estimate, ...) as metadata
+			if currentSection == "" {
+				if matches := metadataRegex.FindStringSubmatch(trimmedLine); len(matches) >= 3 {
+					key := metadataKey(matches[1])
+					if !reservedMetadataKeys[key] && !domain.IsRunMetadataKey(key) {
+						currentStory.Metadata[key] = strings.TrimSpace(matches[2])
+					}
+					continue
+				}
+			}
+
 			// Parse acceptance criteria items
 			if inAcceptanceCriteria {
 				if strings.HasPrefix(trimmedLine, "- [ ]") || strings.HasPrefix(trimmedLine, "- [x]") ||
 					strings.HasPrefix(trimmedLine, "-") || strings.HasPrefix(trimmedLine, "*") {
-					criterion := strings.TrimLeft(trimmedLine, "- [x]*")
-					criterion = strings.TrimLeft(criterion, "] ")
-					criterion = strings.TrimSpace(criterion)
-					if criterion != "" {
-						currentStory.AcceptanceCriteria = append(currentStory.AcceptanceCriteria, criterion)
+					if matches := criterionRegex.FindStringSubmatch(trimmedLine); matches != nil {
+						if criterion := strings.TrimSpace(matches[2]); criterion != "" {
+							currentStory.AddCriterion(criterion, strings.EqualFold(matches[1], "x"))
+						}
 					}
 				}
 				continue
@@ -251,19 +274,102 @@ func (p *MarkdownPRDParser) Validate(project *domain.Project) error {
 	return nil
 }
 
-// parseDependencyList parses a comma-separated list of dependency IDs
-func parseDependencyList(s string) []string {
+// reservedMetadataKeys are story fields the parser handles itself, which
+// aren't stored as metadata even when their value doesn't parse
+var reservedMetadataKeys = map[string]bool{
+	"id":          true,
+	"title":       true,
+	"priority":    true,
+	"status":      true,
+	"depends_on":  true,
+	"depend_on":   true,
+	"critical":    true,
+	"description": true,
+	"notes":       true,
+}
+
+// metadataKey normalizes a field label like "Estimate" or "Story Points" to a
+// metadata key like "estimate" or "story_points"
+func metadataKey(label string) string {
+	key := strings.ToLower(strings.TrimSpace(label))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(key)
+}
+
+// allPreviousDependency is the dependency shorthand for every earlier story
+const allPreviousDependency = "all-previous"
+
+// dependencyRangeRegex matches a dependency range like S1..S3 or STORY-001..STORY-010
+var dependencyRangeRegex = regexp.MustCompile(`^(.*?)(\d+)\.\.(.*?)(\d+)$`)
+
+// maxDependencyRange caps how many IDs a range expands to, so a typo like
+// S1..S1000000 is kept as written instead of allocating a million IDs
+const maxDependencyRange = 1000
+
+// parseDependencyList parses a comma-separated list of dependency IDs.
+// "all-previous" expands to the IDs in previous, the stories defined earlier
+// in the PRD, and a range like S1..S3 expands to S1, S2, S3. Anything that
+// doesn't expand is kept as written, so validation reports it.
+func parseDependencyList(s string, previous []string) []string {
 	var deps []string
+	seen := make(map[string]bool)
+	add := func(dep string) {
+		if !seen[dep] {
+			seen[dep] = true
+			deps = append(deps, dep)
+		}
+	}
+
 	for _, part := range strings.Split(s, ",") {
 		dep := strings.TrimSpace(part)
 		dep = strings.Trim(dep, "\"'")
-		if dep != "" {
-			deps = append(deps, dep)
+		if dep == "" {
+			continue
+		}
+
+		if strings.EqualFold(dep, allPreviousDependency) {
+			for _, id := range previous {
+				add(id)
+			}
+			continue
+		}
+
+		expanded := expandDependencyRange(dep)
+		if expanded == nil {
+			add(dep)
+		}
+		for _, id := range expanded {
+			add(id)
 		}
 	}
 	return deps
 }
 
+// expandDependencyRange expands a range like S1..S3 or STORY-001..STORY-003,
+// keeping the zero padding of the first ID. It returns nil if dep isn't a
+// range, its ends don't share a prefix, or it spans more than maxDependencyRange IDs.
+func expandDependencyRange(dep string) []string {
+	matches := dependencyRangeRegex.FindStringSubmatch(dep)
+	if len(matches) < 5 || matches[1] != matches[3] {
+		return nil
+	}
+
+	first, err := strconv.Atoi(matches[2])
+	if err != nil {
+		return nil
+	}
+	last, err := strconv.Atoi(matches[4])
+	if err != nil || last < first || last-first >= maxDependencyRange {
+		return nil
+	}
+
+	prefix, width := matches[1], len(matches[2])
+	ids := make([]string, 0, last-first+1)
+	for n := first; n <= last; n++ {
+		ids = append(ids, fmt.Sprintf("%s%0*d", prefix, width, n))
+	}
+	return ids
+}
+
 // parseStatus converts a status string to StoryStatus
 func parseStatus(s string) domain.StoryStatus {
 	switch strings.ToLower(strings.TrimSpace(s)) {
@@ -280,3 +386,12 @@ func parseStatus(s string) domain.StoryStatus {
 	}
 }
 
+// parseCritical interprets the value of a **Critical:** field
+func parseCritical(value string) bool {
+	switch strings.ToLower(value) {
+	case "", "yes", "true", "y":
+		return true
+	default:
+		return false
+	}
+}
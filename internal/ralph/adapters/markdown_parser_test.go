@@ -0,0 +1,33 @@
+package adapters
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDependencyList(t *testing.T) {
+	previous := []string{"S1", "S2"}
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"S1, 'S2'", []string{"S1", "S2"}},
+		{"all-previous, S2", []string{"S1", "S2"}},
+		{"S1..S3", []string{"S1", "S2", "S3"}},
+		{"STORY-008..STORY-010", []string{"STORY-008", "STORY-009", "STORY-010"}},
+		{"S3..S1", []string{"S3..S1"}},
+		{"A1..B2", []string{"A1..B2"}},
+		{"S1..S1001", []string{"S1..S1001"}},
+		{"S1..S99999999999999999999", []string{"S1..S99999999999999999999"}},
+	}
+
+	for _, tt := range tests {
+		if got := parseDependencyList(tt.in, previous); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseDependencyList(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	if got := parseDependencyList("S1..S1000", nil); len(got) != 1000 || got[999] != "S1000" {
+		t.Errorf("a range of 1000 IDs gave %d, want S1 to S1000", len(got))
+	}
+}
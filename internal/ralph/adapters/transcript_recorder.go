@@ -0,0 +1,77 @@
+package adapters
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DylanSharp/dtools/internal/logging"
+	"github.com/DylanSharp/dtools/internal/ralph/domain"
+)
+
+// TranscriptRecorder implements ports.Recorder by appending each story's events
+// to stories/<STORY-ID>.log under a directory, and project-level events to run.log
+type TranscriptRecorder struct {
+	dir   string
+	files map[string]*os.File
+}
+
+// NewTranscriptRecorder creates a recorder writing under dir, creating it if needed
+func NewTranscriptRecorder(dir string) (*TranscriptRecorder, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "stories"), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create transcript directory: %w", err)
+	}
+	return &TranscriptRecorder{dir: dir, files: make(map[string]*os.File)}, nil
+}
+
+// Record appends an event to its story's transcript
+func (r *TranscriptRecorder) Record(event domain.ExecutionEvent) {
+	f, err := r.file(event.StoryID)
+	if err != nil {
+		logging.Warn("failed to open transcript", "story", event.StoryID, "err", err)
+		return
+	}
+	if _, err := f.WriteString(formatTranscriptEvent(event)); err != nil {
+		logging.Warn("failed to write transcript", "story", event.StoryID, "err", err)
+	}
+}
+
+// Close closes every transcript file
+func (r *TranscriptRecorder) Close() error {
+	var errs []error
+	for _, f := range r.files {
+		errs = append(errs, f.Close())
+	}
+	r.files = make(map[string]*os.File)
+	return errors.Join(errs...)
+}
+
+// file returns the open transcript for a story, or the run log for ""
+func (r *TranscriptRecorder) file(storyID string) (*os.File, error) {
+	if f, ok := r.files[storyID]; ok {
+		return f, nil
+	}
+	path := filepath.Join(r.dir, "run.log")
+	if storyID != "" {
+		path = filepath.Join(r.dir, "stories", sanitizeFilename(storyID)+".log")
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	r.files[storyID] = f
+	return f, nil
+}
+
+// formatTranscriptEvent renders an event as a timestamped entry, indenting
+// continuation lines so entries stay easy to tell apart
+func formatTranscriptEvent(event domain.ExecutionEvent) string {
+	kind := string(event.Type)
+	if event.ThoughtType != "" {
+		kind += "/" + string(event.ThoughtType)
+	}
+	content := strings.ReplaceAll(strings.TrimRight(event.Content, "\n"), "\n", "\n    ")
+	return fmt.Sprintf("%s [%s] %s\n", event.Timestamp.Format("15:04:05"), kind, content)
+}
@@ -14,10 +14,13 @@ const (
 	ErrCodeInvalidDependency   = "invalid_dependency"
 	ErrCodeClaudeNotFound      = "claude_not_found"
 	ErrCodeClaudeError         = "claude_error"
+	ErrCodeClaudeTimeout       = "claude_timeout"
 	ErrCodeExecutionFailed     = "execution_failed"
 	ErrCodeStatePersistence    = "state_persistence"
+	ErrCodeCommit              = "commit_failed"
 	ErrCodeNoStoriesReady      = "no_stories_ready"
 	ErrCodeAllStoriesCompleted = "all_stories_completed"
+	ErrCodeInvalidWorkDir      = "invalid_work_dir"
 )
 
 // RalphError represents a domain-specific error
@@ -99,6 +102,11 @@ func ErrClaudeError(message string, cause error) *RalphError {
 	return WrapError(ErrCodeClaudeError, message, cause)
 }
 
+// ErrClaudeTimeout returns an error when Claude exceeds its execution deadline
+func ErrClaudeTimeout(timeout string) *RalphError {
+	return NewError(ErrCodeClaudeTimeout, fmt.Sprintf("Claude CLI timed out after %s", timeout))
+}
+
 // ErrExecutionFailed returns an error for story execution failures
 func ErrExecutionFailed(storyID, reason string, cause error) *RalphError {
 	return WrapError(ErrCodeExecutionFailed, fmt.Sprintf("story %q execution failed: %s", storyID, reason), cause)
@@ -109,6 +117,11 @@ func ErrStatePersistence(operation string, cause error) *RalphError {
 	return WrapError(ErrCodeStatePersistence, fmt.Sprintf("state %s failed", operation), cause)
 }
 
+// ErrCommit returns an error for a failed story checkpoint commit or push
+func ErrCommit(message string, cause error) *RalphError {
+	return WrapError(ErrCodeCommit, message, cause)
+}
+
 // ErrNoStoriesReady returns an error when no stories can be executed
 func ErrNoStoriesReady() *RalphError {
 	return NewError(ErrCodeNoStoriesReady, "no stories are ready to execute (all blocked by dependencies or already completed)")
@@ -119,6 +132,11 @@ func ErrAllStoriesCompleted() *RalphError {
 	return NewError(ErrCodeAllStoriesCompleted, "all stories have been completed")
 }
 
+// ErrInvalidWorkDir returns an error for a work directory that doesn't exist or isn't a directory
+func ErrInvalidWorkDir(path string, cause error) *RalphError {
+	return WrapError(ErrCodeInvalidWorkDir, fmt.Sprintf("invalid work directory: %s", path), cause)
+}
+
 // IsRalphError checks if an error is a RalphError
 func IsRalphError(err error) bool {
 	_, ok := err.(*RalphError)
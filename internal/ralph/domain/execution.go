@@ -2,6 +2,7 @@ package domain
 
 import (
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -31,6 +32,8 @@ const (
 	ThoughtTypeSuggestion ThoughtType = "suggestion"
 	ThoughtTypeCode       ThoughtType = "code"
 	ThoughtTypeGeneral    ThoughtType = "general"
+	ThoughtTypeThinking   ThoughtType = "thinking" // Claude's extended thinking, shown muted and italic
+	ThoughtTypeTool       ThoughtType = "tool"     // A tool call, such as reading or editing a file
 )
 
 // ExecutionEvent represents a streaming execution update
@@ -93,6 +96,9 @@ func NewStoryCompletedEvent(story *Story) ExecutionEvent {
 	if story.Duration() > 0 {
 		event.Metadata["duration"] = story.Duration().String()
 	}
+	if files := story.Metadata[MetadataFilesChanged]; files != "" {
+		event.Metadata[MetadataFilesChanged] = files
+	}
 	return event
 }
 
@@ -149,6 +155,23 @@ func NewErrorEvent(storyID, err string) ExecutionEvent {
 	}
 }
 
+// NewTimeoutEvent creates an error event for a story whose execution timed out.
+// The error code is recorded in the event metadata so consumers can tell it apart.
+func NewTimeoutEvent(storyID string, err *RalphError) ExecutionEvent {
+	return ExecutionEvent{
+		Timestamp: time.Now(),
+		StoryID:   storyID,
+		Type:      EventTypeError,
+		Content:   err.Error(),
+		Metadata:  map[string]string{"error_code": err.Code},
+	}
+}
+
+// IsTimeout returns true if this event reports an execution timeout
+func (e ExecutionEvent) IsTimeout() bool {
+	return e.Type == EventTypeError && e.Metadata["error_code"] == ErrCodeClaudeTimeout
+}
+
 // IsStoryEvent returns true if this event is related to story execution
 func (e ExecutionEvent) IsStoryEvent() bool {
 	switch e.Type {
@@ -168,6 +191,21 @@ func (e ExecutionEvent) IsProjectEvent() bool {
 	return false
 }
 
+// StoryCompleteMarker is the line Claude is asked to end its final message with
+// once a story is done, so an unfinished session can be told apart
+const StoryCompleteMarker = "STORY_COMPLETE"
+
+// IsMessage returns true if this event is text Claude wrote to the user,
+// rather than its thinking or a tool call
+func (e ExecutionEvent) IsMessage() bool {
+	return e.IsThought() && e.ThoughtType != ThoughtTypeThinking && e.ThoughtType != ThoughtTypeTool
+}
+
+// ConfirmsCompletion returns true if Claude said in this event that the story is done
+func (e ExecutionEvent) ConfirmsCompletion() bool {
+	return e.IsMessage() && strings.Contains(e.Content, StoryCompleteMarker)
+}
+
 // IsThought returns true if this event is a thought
 func (e ExecutionEvent) IsThought() bool {
 	return e.Type == EventTypeThought
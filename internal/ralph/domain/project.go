@@ -244,6 +244,17 @@ func (p *Project) Duration() time.Duration {
 	return time.Since(*p.StartedAt)
 }
 
+// StoriesDuration returns the time spent across the running and completed stories
+func (p *Project) StoriesDuration() time.Duration {
+	var total time.Duration
+	for _, s := range p.Stories {
+		if s.IsTimed() {
+			total += s.Duration()
+		}
+	}
+	return total
+}
+
 // UpdateBlockedStatus updates blocked status for all stories based on dependencies
 func (p *Project) UpdateBlockedStatus() {
 	completedIDs := p.GetCompletedIDs()
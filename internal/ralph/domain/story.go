@@ -1,6 +1,10 @@
 package domain
 
 import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -15,14 +19,26 @@ const (
 	StoryStatusFailed    StoryStatus = "failed"
 )
 
+// SkippedByUser is the error recorded on a story the user skipped mid-run
+const SkippedByUser = "skipped by user"
+
+// MetadataFilesChanged is the story metadata key holding the files its last run changed
+const MetadataFilesChanged = "files_changed"
+
+// MetadataScope is the story metadata key declaring, from a **Scope:** field, the
+// comma-separated files and directories the story works in
+const MetadataScope = "scope"
+
 // Story represents a user story from the PRD
 type Story struct {
 	ID                 string            `json:"id"`
 	Title              string            `json:"title"`
 	Description        string            `json:"description"`
 	AcceptanceCriteria []string          `json:"acceptance_criteria"`
+	CriteriaDone       []bool            `json:"criteria_done,omitempty"` // Checkbox state, parallel to AcceptanceCriteria
 	DependsOn          []string          `json:"depends_on"`
 	Priority           int               `json:"priority"`
+	Critical           bool              `json:"critical,omitempty"` // Failure stops the whole run
 	Status             StoryStatus       `json:"status"`
 	StartedAt          *time.Time        `json:"started_at,omitempty"`
 	CompletedAt        *time.Time        `json:"completed_at,omitempty"`
@@ -98,6 +114,16 @@ func (s *Story) MarkFailed(err string) {
 	s.Error = err
 }
 
+// MarkSkipped marks the story as failed because the user skipped it
+func (s *Story) MarkSkipped() {
+	s.MarkFailed(SkippedByUser)
+}
+
+// IsSkipped returns true if the story failed because the user skipped it
+func (s *Story) IsSkipped() bool {
+	return s.IsFailed() && s.Error == SkippedByUser
+}
+
 // MarkBlocked marks the story as blocked
 func (s *Story) MarkBlocked() {
 	s.Status = StoryStatusBlocked
@@ -119,6 +145,144 @@ func (s *Story) Duration() time.Duration {
 	return time.Since(*s.StartedAt)
 }
 
+// IsTimed returns true if the story's Duration is meaningful: it's running or has
+// completed. A failed story has no end time, so its Duration would keep growing.
+func (s *Story) IsTimed() bool {
+	return s.StartedAt != nil && (s.IsRunning() || s.IsCompleted())
+}
+
+// SetFilesChanged records the files the story's last run changed
+func (s *Story) SetFilesChanged(files []string) {
+	if s.Metadata == nil {
+		s.Metadata = make(map[string]string)
+	}
+	if len(files) == 0 {
+		delete(s.Metadata, MetadataFilesChanged)
+		return
+	}
+	s.Metadata[MetadataFilesChanged] = strings.Join(files, "\n")
+}
+
+// Scope returns the files and directories the story declares it works in,
+// relative to the work dir, or nil if it doesn't declare any
+func (s *Story) Scope() []string {
+	var scope []string
+	for _, entry := range strings.Split(s.Metadata[MetadataScope], ",") {
+		entry = strings.Trim(strings.TrimSpace(entry), "`")
+		if entry == "" {
+			continue
+		}
+		scope = append(scope, path.Clean(filepath.ToSlash(entry)))
+	}
+	return scope
+}
+
+// ConflictsWith returns true if the story and other may edit the same files,
+// so they mustn't run at the same time. Stories that don't declare a scope
+// could touch anything, so they conflict with every other story.
+func (s *Story) ConflictsWith(other *Story) bool {
+	if s.ID == other.ID {
+		return false
+	}
+	return ScopesOverlap(s.Scope(), other.Scope())
+}
+
+// ScopesOverlap returns true if two scopes, as from Scope, may cover the same
+// files. An empty scope is undeclared, so it overlaps everything.
+func ScopesOverlap(scope, other []string) bool {
+	if len(scope) == 0 || len(other) == 0 {
+		return true
+	}
+	for _, a := range scope {
+		for _, b := range other {
+			if pathContains(a, b) || pathContains(b, a) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pathContains returns true if p is dir itself or inside it
+func pathContains(dir, p string) bool {
+	return dir == "." || p == dir || strings.HasPrefix(p, dir+"/")
+}
+
+// IsRunMetadataKey returns true for metadata ralph records itself while
+// running a story, as opposed to metadata declared in the PRD
+func IsRunMetadataKey(key string) bool {
+	return key == MetadataFilesChanged
+}
+
+// DeclaredMetadataKeys returns the keys of metadata declared in the PRD
+// (labels, estimate, owner, ...), sorted
+func (s *Story) DeclaredMetadataKeys() []string {
+	var keys []string
+	for key := range s.Metadata {
+		if !IsRunMetadataKey(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// DeclaredMetadata returns the metadata declared in the PRD, or nil if there is none
+func (s *Story) DeclaredMetadata() map[string]string {
+	keys := s.DeclaredMetadataKeys()
+	if len(keys) == 0 {
+		return nil
+	}
+	declared := make(map[string]string, len(keys))
+	for _, key := range keys {
+		declared[key] = s.Metadata[key]
+	}
+	return declared
+}
+
+// CopyRunMetadata copies the metadata ralph recorded on another copy of the
+// story, keeping this story's declared metadata
+func (s *Story) CopyRunMetadata(from *Story) {
+	for key, value := range from.Metadata {
+		if !IsRunMetadataKey(key) {
+			continue
+		}
+		if s.Metadata == nil {
+			s.Metadata = make(map[string]string)
+		}
+		s.Metadata[key] = value
+	}
+}
+
+// FilesChanged returns the files the story's last run changed
+func (s *Story) FilesChanged() []string {
+	if s.Metadata[MetadataFilesChanged] == "" {
+		return nil
+	}
+	return strings.Split(s.Metadata[MetadataFilesChanged], "\n")
+}
+
+// AddCriterion appends an acceptance criterion with its checkbox state
+func (s *Story) AddCriterion(text string, done bool) {
+	s.AcceptanceCriteria = append(s.AcceptanceCriteria, text)
+	s.CriteriaDone = append(s.CriteriaDone, done)
+}
+
+// IsCriterionDone returns true if the criterion at index i is checked off
+func (s *Story) IsCriterionDone(i int) bool {
+	return i >= 0 && i < len(s.CriteriaDone) && s.CriteriaDone[i]
+}
+
+// CriteriaProgress returns the number of checked and total acceptance criteria
+func (s *Story) CriteriaProgress() (done, total int) {
+	for i := range s.AcceptanceCriteria {
+		if s.IsCriterionDone(i) {
+			done++
+		}
+	}
+	return done, len(s.AcceptanceCriteria)
+}
+
 // HasDependencies returns true if the story has dependencies
 func (s *Story) HasDependencies() bool {
 	return len(s.DependsOn) > 0
@@ -136,3 +300,14 @@ func (s *Story) CanRun(completedIDs map[string]bool) bool {
 	}
 	return true
 }
+
+// UnmetDependencies returns the dependencies that aren't in completedIDs
+func (s *Story) UnmetDependencies(completedIDs map[string]bool) []string {
+	var unmet []string
+	for _, depID := range s.DependsOn {
+		if !completedIDs[depID] {
+			unmet = append(unmet, depID)
+		}
+	}
+	return unmet
+}
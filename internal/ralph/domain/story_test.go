@@ -0,0 +1,67 @@
+package domain
+
+import "testing"
+
+func TestPathContains(t *testing.T) {
+	tests := []struct {
+		dir, p string
+		want   bool
+	}{
+		{".", "api/handler.go", true},
+		{"api", "api", true},
+		{"api", "api/handler.go", true},
+		{"api", "api/v1/handler.go", true},
+		{"api", "apiclient/client.go", false},
+		{"api/handler.go", "api", false},
+		{"api", "web", false},
+		{"/repo/api", "/repo/api/handler.go", true},
+		{"/repo/api", "/repo/web", false},
+	}
+	for _, tt := range tests {
+		if got := pathContains(tt.dir, tt.p); got != tt.want {
+			t.Errorf("pathContains(%q, %q) = %v, want %v", tt.dir, tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestConflictsWith(t *testing.T) {
+	story := func(id, scope string) *Story {
+		s := NewStory(id, "Story "+id)
+		if scope != "" {
+			s.Metadata = map[string]string{MetadataScope: scope}
+		}
+		return s
+	}
+	tests := []struct {
+		name         string
+		scope, other string
+		want         bool
+	}{
+		{"no scopes", "", "", true},
+		{"one without a scope", "api/", "", true},
+		{"disjoint dirs", "api/", "web/", false},
+		{"same file", "api/handler.go", "`api/handler.go`", true},
+		{"file in dir", "api/v1/handler.go", "api", true},
+		{"dir in file's place", "api", "api/v1/handler.go", true},
+		{"shared prefix only", "api", "apiclient", false},
+		{"one of several overlaps", "web, docs/api.md", "api, docs", true},
+		{"several disjoint", "web, docs/web.md", "api, docs/api.md", false},
+		{"whole work dir", ".", "web", true},
+		{"unclean paths", "./api/../web/", "web/index.ts", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := story("1", tt.scope), story("2", tt.other)
+			if got := a.ConflictsWith(b); got != tt.want {
+				t.Errorf("ConflictsWith = %v, want %v", got, tt.want)
+			}
+			if got := b.ConflictsWith(a); got != tt.want {
+				t.Errorf("reversed ConflictsWith = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if s := story("1", ""); s.ConflictsWith(s) {
+		t.Error("a story conflicts with itself")
+	}
+}
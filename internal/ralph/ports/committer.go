@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/DylanSharp/dtools/internal/ralph/domain"
+)
+
+// Committer checkpoints a completed story's changes in version control
+type Committer interface {
+	// CommitStory stages and commits every change in workDir for the story.
+	// It returns false without an error if there was nothing to commit.
+	CommitStory(ctx context.Context, workDir string, story *domain.Story) (bool, error)
+}
@@ -31,6 +31,12 @@ type ExecutionContext struct {
 
 	// AdditionalContext is extra context to include in the prompt
 	AdditionalContext string
+
+	// Pass is the session number for a story that takes several; 0 or 1 for the first
+	Pass int
+
+	// PreviousProgress describes what earlier sessions did, for passes after the first
+	PreviousProgress string
 }
 
 // NewExecutionContext creates a new execution context
@@ -43,6 +49,13 @@ func NewExecutionContext(project *domain.Project) ExecutionContext {
 	}
 }
 
+// WithContinuation makes the execution context continue a story an earlier session left unfinished
+func (c ExecutionContext) WithContinuation(pass int, progress string) ExecutionContext {
+	c.Pass = pass
+	c.PreviousProgress = progress
+	return c
+}
+
 // WithAdditionalContext adds extra context to the execution context
 func (c ExecutionContext) WithAdditionalContext(ctx string) ExecutionContext {
 	c.AdditionalContext = ctx
@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"github.com/DylanSharp/dtools/internal/ralph/domain"
+)
+
+// Recorder keeps a record of a run's events, such as per-story transcripts
+type Recorder interface {
+	// Record stores an event. Failures are the recorder's to report; they don't
+	// affect the run.
+	Record(event domain.ExecutionEvent)
+
+	// Close flushes and releases whatever the recorder holds open
+	Close() error
+}
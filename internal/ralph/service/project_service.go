@@ -2,6 +2,14 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/DylanSharp/dtools/internal/ralph/domain"
 	"github.com/DylanSharp/dtools/internal/ralph/ports"
@@ -13,6 +21,15 @@ type ProjectService struct {
 	executor   ports.Executor
 	repository ports.Repository
 	scheduler  *Scheduler
+
+	stopOnFailure bool            // Abort the run when any story fails
+	committer     ports.Committer // Commits each completed story; nil leaves commits to the agent
+	maxPasses     int             // Claude sessions a story may take before it's taken as done
+	recorder      ports.Recorder  // Records every run event, e.g. as transcripts; nil records nothing
+
+	storyMu     sync.Mutex
+	cancelStory context.CancelFunc // Cancels the running story; nil when none is running
+	skipStory   bool               // The running story was skipped by the user
 }
 
 // NewProjectService creates a new project service
@@ -26,9 +43,29 @@ func NewProjectService(
 		executor:   executor,
 		repository: repository,
 		scheduler:  NewScheduler(),
+		maxPasses:  1,
 	}
 }
 
+// SetStopOnFailure makes RunProject abort as soon as any story fails.
+// Critical stories stop the run regardless.
+func (s *ProjectService) SetStopOnFailure(stop bool) {
+	s.stopOnFailure = stop
+}
+
+// SetMaxPasses lets a story run in up to n Claude sessions: while a session ends
+// without Claude confirming the story is complete, a new one continues it.
+// With the default of 1, a story is complete when its first session ends.
+func (s *ProjectService) SetMaxPasses(n int) {
+	s.maxPasses = max(n, 1)
+}
+
+// SetRecorder makes RunProject record every event of the run. The recorder is
+// closed when the run ends.
+func (s *ProjectService) SetRecorder(recorder ports.Recorder) {
+	s.recorder = recorder
+}
+
 // InitProject initializes a project from a PRD file
 func (s *ProjectService) InitProject(prdPath string) (*domain.Project, error) {
 	// Parse PRD
@@ -58,6 +95,43 @@ func (s *ProjectService) InitProject(prdPath string) (*domain.Project, error) {
 	return project, nil
 }
 
+// SetWorkDir makes a project's stories run in dir instead of the PRD's directory,
+// and saves it so later runs use the same directory
+func (s *ProjectService) SetWorkDir(projectID, dir string) (*domain.Project, error) {
+	project, err := s.GetProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, domain.ErrInvalidWorkDir(dir, err)
+	}
+	info, err := os.Stat(absDir)
+	if err != nil {
+		return nil, domain.ErrInvalidWorkDir(dir, err)
+	}
+	if !info.IsDir() {
+		return nil, domain.ErrInvalidWorkDir(dir, fmt.Errorf("not a directory"))
+	}
+
+	if project.WorkDir == absDir {
+		return project, nil
+	}
+	project.WorkDir = absDir
+	project.UpdatedAt = time.Now()
+	if err := s.repository.Save(project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// PreviewProject parses a PRD without validating or saving it, so a plan can
+// report invalid dependencies instead of failing on them
+func (s *ProjectService) PreviewProject(prdPath string) (*domain.Project, error) {
+	return s.parser.Parse(prdPath)
+}
+
 // GetProject retrieves a project by ID or PRD path
 func (s *ProjectService) GetProject(idOrPath string) (*domain.Project, error) {
 	// Try by ID first
@@ -106,6 +180,10 @@ func (s *ProjectService) RunProject(ctx context.Context, projectID string) (<-ch
 	}
 
 	events := make(chan domain.ExecutionEvent, 100)
+	out := events
+	if s.recorder != nil {
+		events = s.record(out)
+	}
 
 	go func() {
 		defer close(events)
@@ -145,6 +223,21 @@ func (s *ProjectService) RunProject(ctx context.Context, projectID string) (<-ch
 				events <- domain.NewErrorEvent(story.ID, err.Error())
 			}
 
+			// Stop the run on failure if asked to, or if the story is critical.
+			// A story the user skipped doesn't stop the run unless it's critical.
+			if story.IsFailed() && (s.stopOnFailure && !story.IsSkipped() || story.Critical) {
+				project.MarkFailed()
+				if err := s.repository.Save(project); err != nil {
+					events <- domain.NewErrorEvent("", "failed to save project state: "+err.Error())
+				}
+				reason := "story " + story.ID + " failed, stopping the run"
+				if story.Critical {
+					reason = "critical " + reason
+				}
+				events <- domain.NewExecutionEvent(domain.EventTypeProjectFailed, story.ID, reason)
+				return
+			}
+
 			// Save progress
 			if err := s.repository.Save(project); err != nil {
 				events <- domain.NewErrorEvent("", "failed to save progress: "+err.Error())
@@ -157,7 +250,11 @@ func (s *ProjectService) RunProject(ctx context.Context, projectID string) (<-ch
 			events <- domain.NewProjectCompleteEvent(project)
 		} else if project.HasFailures() {
 			project.MarkFailed()
-			events <- domain.NewExecutionEvent(domain.EventTypeProjectFailed, "", "project has failed stories")
+			reason := "project has failed stories"
+			if stalled := s.scheduler.GetStalledStories(project); len(stalled) > 0 {
+				reason += fmt.Sprintf(", and %d stalled stories that can't run until they're fixed", len(stalled))
+			}
+			events <- domain.NewExecutionEvent(domain.EventTypeProjectFailed, "", reason)
 		}
 
 		if err := s.repository.Save(project); err != nil {
@@ -165,7 +262,23 @@ func (s *ProjectService) RunProject(ctx context.Context, projectID string) (<-ch
 		}
 	}()
 
-	return events, nil
+	return out, nil
+}
+
+// record returns a channel whose events are recorded and then forwarded to out,
+// which is closed, along with the recorder, once the returned channel is
+func (s *ProjectService) record(out chan<- domain.ExecutionEvent) chan domain.ExecutionEvent {
+	in := make(chan domain.ExecutionEvent, 100)
+	recorder := s.recorder
+	go func() {
+		defer close(out)
+		for event := range in {
+			recorder.Record(event)
+			out <- event
+		}
+		recorder.Close()
+	}()
+	return in
 }
 
 // RunStory executes a single story
@@ -210,6 +323,46 @@ func (s *ProjectService) RunStory(ctx context.Context, projectID, storyID string
 	return events, nil
 }
 
+// SetCommitter makes completed stories get committed as deterministic checkpoints
+func (s *ProjectService) SetCommitter(committer ports.Committer) {
+	s.committer = committer
+}
+
+// SkipCurrentStory cancels the running story, which is marked failed as skipped by
+// the user while the run moves on to the next ready story. It returns false if no
+// story is running.
+func (s *ProjectService) SkipCurrentStory() bool {
+	s.storyMu.Lock()
+	defer s.storyMu.Unlock()
+	if s.cancelStory == nil {
+		return false
+	}
+	s.skipStory = true
+	s.cancelStory()
+	return true
+}
+
+// startStory gives a story its own context, so it can be skipped without cancelling the run
+func (s *ProjectService) startStory(ctx context.Context) context.Context {
+	s.storyMu.Lock()
+	defer s.storyMu.Unlock()
+	storyCtx, cancel := context.WithCancel(ctx)
+	s.cancelStory = cancel
+	s.skipStory = false
+	return storyCtx
+}
+
+// finishStory releases the running story's context and reports whether it was skipped
+func (s *ProjectService) finishStory() bool {
+	s.storyMu.Lock()
+	defer s.storyMu.Unlock()
+	if s.cancelStory != nil {
+		s.cancelStory()
+		s.cancelStory = nil
+	}
+	return s.skipStory
+}
+
 // executeStory runs a single story and sends events to the channel
 func (s *ProjectService) executeStory(ctx context.Context, project *domain.Project, story *domain.Story, events chan<- domain.ExecutionEvent) error {
 	// Mark story as running
@@ -219,27 +372,163 @@ func (s *ProjectService) executeStory(ctx context.Context, project *domain.Proje
 	// Build execution context
 	execCtx := ports.NewExecutionContext(project)
 
-	// Execute story
-	storyEvents, err := s.executor.Execute(ctx, story, execCtx)
-	if err != nil {
-		story.MarkFailed(err.Error())
-		project.ClearCurrentStory()
-		return err
-	}
+	// Each pass is a Claude session; the story stays running between them
+	var filesChanged []string
+	var lastMessage string
+	var ended bool // The last session ran to the end rather than being cancelled
+	for pass := 1; ; pass++ {
+		passCtx := execCtx
+		if pass > 1 {
+			passCtx = execCtx.WithContinuation(pass, previousProgress(lastMessage, filesChanged))
+		}
+
+		// Execute story
+		storyCtx := s.startStory(ctx)
+		storyEvents, err := s.executor.Execute(storyCtx, story, passCtx)
+		if err != nil {
+			s.finishStory()
+			story.MarkFailed(err.Error())
+			project.ClearCurrentStory()
+			return err
+		}
+
+		// Forward events, holding back completion until the last pass
+		var timeoutErr, sessionErr string
+		confirmed := false
+		ended = false
+		for event := range storyEvents {
+			if event.IsTimeout() {
+				timeoutErr = event.Content
+			} else if event.Type == domain.EventTypeError {
+				sessionErr = event.Content
+			}
+			if event.IsMessage() {
+				lastMessage = event.Content
+				confirmed = confirmed || event.ConfirmsCompletion()
+			}
+			if event.Type == domain.EventTypeStoryCompleted {
+				ended = true
+				continue
+			}
+			if event.Type == domain.EventTypeStoryStarted && pass > 1 {
+				continue
+			}
+			events <- event
+		}
+
+		// Each session reports only the files it changed
+		for _, file := range story.FilesChanged() {
+			if !slices.Contains(filesChanged, file) {
+				filesChanged = append(filesChanged, file)
+			}
+		}
+		story.SetFilesChanged(filesChanged)
+
+		// A skipped story is failed, and the run carries on unless it was cancelled too
+		if s.finishStory() && ctx.Err() == nil {
+			story.MarkSkipped()
+			project.ClearCurrentStory()
+			project.UpdateBlockedStatus()
+			events <- domain.NewStoryFailedEvent(story, domain.SkippedByUser)
+			return nil
+		}
+
+		// A timed-out story is failed rather than completed
+		if timeoutErr != "" {
+			story.MarkFailed(timeoutErr)
+			project.ClearCurrentStory()
+			project.UpdateBlockedStatus()
+			events <- domain.NewStoryFailedEvent(story, timeoutErr)
+			return nil
+		}
+
+		// A cancelled run leaves the story to be run again rather than completed
+		if ctx.Err() != nil {
+			story.MarkPending()
+			project.ClearCurrentStory()
+			return nil
+		}
+
+		// A story whose session ended in an error, such as Claude exiting non-zero, is failed too
+		if sessionErr != "" {
+			story.MarkFailed(sessionErr)
+			project.ClearCurrentStory()
+			project.UpdateBlockedStatus()
+			events <- domain.NewStoryFailedEvent(story, sessionErr)
+			return nil
+		}
 
-	// Forward events
-	for event := range storyEvents {
-		events <- event
+		if confirmed || !ended {
+			break
+		}
+		if pass >= s.maxPasses {
+			if s.maxPasses > 1 {
+				events <- domain.NewThoughtEvent(story.ID, fmt.Sprintf("Claude didn't confirm %s is complete after %d sessions", story.ID, pass), domain.ThoughtTypeProgress)
+			}
+			break
+		}
+		events <- domain.NewThoughtEvent(story.ID, fmt.Sprintf("Session %d ended before %s was complete; continuing in a new session (%d/%d)", pass, story.ID, pass+1, s.maxPasses), domain.ThoughtTypeProgress)
 	}
 
 	// Mark story as completed
 	story.MarkCompleted()
 	project.ClearCurrentStory()
 	project.UpdateBlockedStatus()
+	if ended {
+		events <- domain.NewStoryCompletedEvent(story)
+	}
+
+	if s.committer != nil && ctx.Err() == nil {
+		s.commitStory(ctx, project, story, events)
+	}
 
 	return nil
 }
 
+// maxProgressMessage bounds how much of the previous session's last message a
+// continuation prompt repeats
+const maxProgressMessage = 2000
+
+// previousProgress summarizes an unfinished session for the prompt continuing it
+func previousProgress(lastMessage string, filesChanged []string) string {
+	var b strings.Builder
+	if len(filesChanged) > 0 {
+		b.WriteString("Files changed so far:\n")
+		for _, file := range filesChanged {
+			b.WriteString("- " + file + "\n")
+		}
+	}
+	if lastMessage != "" {
+		if len(lastMessage) > maxProgressMessage {
+			start := len(lastMessage) - maxProgressMessage
+			for start < len(lastMessage) && !utf8.RuneStart(lastMessage[start]) {
+				start++
+			}
+			lastMessage = "..." + lastMessage[start:]
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("The previous session's last message:\n")
+		b.WriteString(lastMessage)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// commitStory checkpoints a completed story. A failed commit is reported but
+// doesn't fail the story.
+func (s *ProjectService) commitStory(ctx context.Context, project *domain.Project, story *domain.Story, events chan<- domain.ExecutionEvent) {
+	committed, err := s.committer.CommitStory(ctx, project.WorkDir, story)
+	switch {
+	case err != nil:
+		events <- domain.NewErrorEvent(story.ID, err.Error())
+	case committed:
+		events <- domain.NewThoughtEvent(story.ID, "Committed changes for "+story.ID, domain.ThoughtTypeProgress)
+	default:
+		events <- domain.NewThoughtEvent(story.ID, "No changes to commit for "+story.ID, domain.ThoughtTypeProgress)
+	}
+}
+
 // GetProjectStatus returns the current status of a project
 func (s *ProjectService) GetProjectStatus(projectID string) (*domain.Project, error) {
 	return s.GetProject(projectID)
@@ -250,30 +539,60 @@ func (s *ProjectService) GetScheduler() *Scheduler {
 	return s.scheduler
 }
 
-// RefreshProject reloads a project from its PRD file
-func (s *ProjectService) RefreshProject(projectID string) (*domain.Project, error) {
+// RefreshReport describes how a refresh changed a project's stories
+type RefreshReport struct {
+	Added   []string // Stories new in the PRD
+	Removed []string // Stories no longer in the PRD (their state is dropped)
+	Changed []string // Stories whose definition changed
+	Reset   []string // Completed stories reset to pending because a dependency is no longer done
+}
+
+// HasChanges returns true if the refresh changed anything
+func (r RefreshReport) HasChanges() bool {
+	return len(r.Added)+len(r.Removed)+len(r.Changed)+len(r.Reset) > 0
+}
+
+// RefreshProject reloads a project from its PRD file, keeping execution state
+// for stories that still exist
+func (s *ProjectService) RefreshProject(projectID string) (*domain.Project, RefreshReport, error) {
+	var report RefreshReport
+
 	// Load existing project
 	existing, err := s.GetProject(projectID)
 	if err != nil {
-		return nil, err
+		return nil, report, err
 	}
 
 	// Re-parse PRD
 	updated, err := s.parser.Parse(existing.PRDPath)
 	if err != nil {
-		return nil, err
+		return nil, report, err
 	}
 
 	// Merge state from existing project
 	for _, story := range updated.Stories {
 		existingStory := existing.GetStory(story.ID)
-		if existingStory != nil {
-			// Preserve execution state
-			story.Status = existingStory.Status
-			story.StartedAt = existingStory.StartedAt
-			story.CompletedAt = existingStory.CompletedAt
-			story.Error = existingStory.Error
-			story.Attempts = existingStory.Attempts
+		if existingStory == nil {
+			report.Added = append(report.Added, story.ID)
+			continue
+		}
+
+		if storyDefinitionChanged(existingStory, story) {
+			report.Changed = append(report.Changed, story.ID)
+		}
+
+		// Preserve execution state
+		story.Status = existingStory.Status
+		story.StartedAt = existingStory.StartedAt
+		story.CompletedAt = existingStory.CompletedAt
+		story.Error = existingStory.Error
+		story.Attempts = existingStory.Attempts
+		story.CopyRunMetadata(existingStory)
+	}
+
+	for _, story := range existing.Stories {
+		if !updated.StoryExists(story.ID) {
+			report.Removed = append(report.Removed, story.ID)
 		}
 	}
 
@@ -281,10 +600,43 @@ func (s *ProjectService) RefreshProject(projectID string) (*domain.Project, erro
 	updated.ID = existing.ID
 	updated.CreatedAt = existing.CreatedAt
 	updated.StartedAt = existing.StartedAt
+	updated.Status = existing.Status
+	updated.CompletedAt = existing.CompletedAt
+	updated.WorkDir = existing.WorkDir // May have been set with --work-dir
 
 	// Validate
 	if err := s.parser.Validate(updated); err != nil {
-		return nil, err
+		return nil, report, err
+	}
+	if err := s.scheduler.DetectCircularDependencies(updated); err != nil {
+		return nil, report, err
+	}
+
+	// A completed story whose dependencies aren't all done anymore must run again.
+	// Repeat until stable so resets cascade to stories depending on reset ones.
+	for changed := true; changed; {
+		changed = false
+		completedIDs := updated.GetCompletedIDs()
+		for _, story := range updated.Stories {
+			if !story.IsCompleted() {
+				continue
+			}
+			for _, depID := range story.DependsOn {
+				if !completedIDs[depID] {
+					story.MarkPending()
+					story.CompletedAt = nil
+					report.Reset = append(report.Reset, story.ID)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	// A completed project with new or reset work is resumable again
+	if updated.Status == domain.ProjectStatusCompleted && !updated.IsComplete() {
+		updated.Status = domain.ProjectStatusPaused
+		updated.CompletedAt = nil
 	}
 
 	// Update blocked status
@@ -292,8 +644,19 @@ func (s *ProjectService) RefreshProject(projectID string) (*domain.Project, erro
 
 	// Save
 	if err := s.repository.Save(updated); err != nil {
-		return nil, err
+		return nil, report, err
 	}
 
-	return updated, nil
+	return updated, report, nil
+}
+
+// storyDefinitionChanged reports whether the PRD definition of a story differs
+func storyDefinitionChanged(old, updated *domain.Story) bool {
+	return old.Title != updated.Title ||
+		old.Description != updated.Description ||
+		old.Priority != updated.Priority ||
+		old.Critical != updated.Critical ||
+		old.Notes != updated.Notes ||
+		!slices.Equal(old.AcceptanceCriteria, updated.AcceptanceCriteria) ||
+		!slices.Equal(old.DependsOn, updated.DependsOn)
 }
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DylanSharp/dtools/internal/ralph/domain"
+	"github.com/DylanSharp/dtools/internal/ralph/ports"
+)
+
+// fakeExecutor plays back the events of one Claude session per story
+type fakeExecutor struct {
+	session func(story *domain.Story) []domain.ExecutionEvent
+	ran     []string // IDs of the stories executed, in order
+}
+
+func (e *fakeExecutor) Execute(ctx context.Context, story *domain.Story, execCtx ports.ExecutionContext) (<-chan domain.ExecutionEvent, error) {
+	e.ran = append(e.ran, story.ID)
+	session := e.session(story)
+	events := make(chan domain.ExecutionEvent, len(session))
+	for _, event := range session {
+		events <- event
+	}
+	close(events)
+	return events, nil
+}
+
+func (e *fakeExecutor) IsAvailable() bool { return true }
+
+// memoryRepository keeps projects in memory
+type memoryRepository struct {
+	projects map[string]*domain.Project
+}
+
+func newMemoryRepository(projects ...*domain.Project) *memoryRepository {
+	r := &memoryRepository{projects: make(map[string]*domain.Project)}
+	for _, p := range projects {
+		r.projects[p.ID] = p
+	}
+	return r
+}
+
+func (r *memoryRepository) Save(project *domain.Project) error {
+	r.projects[project.ID] = project
+	return nil
+}
+
+func (r *memoryRepository) Load(projectID string) (*domain.Project, error) {
+	if p, ok := r.projects[projectID]; ok {
+		return p, nil
+	}
+	return nil, domain.ErrProjectNotFound(projectID)
+}
+
+func (r *memoryRepository) LoadByPRDPath(prdPath string) (*domain.Project, error) {
+	for _, p := range r.projects {
+		if p.PRDPath == prdPath {
+			return p, nil
+		}
+	}
+	return nil, domain.ErrProjectNotFound(prdPath)
+}
+
+func (r *memoryRepository) List() ([]ports.ProjectInfo, error) { return nil, nil }
+
+func (r *memoryRepository) Delete(projectID string) error {
+	delete(r.projects, projectID)
+	return nil
+}
+
+func (r *memoryRepository) Exists(projectID string) bool {
+	_, ok := r.projects[projectID]
+	return ok
+}
+
+func TestRunProjectFailsStoryOnSessionError(t *testing.T) {
+	project := domain.NewProject("test", "/repo/prd.md", "/repo")
+	first, second := domain.NewStory("1", "First"), domain.NewStory("2", "Second")
+	second.DependsOn = []string{"1"}
+	project.AddStory(first)
+	project.AddStory(second)
+
+	// Claude exits non-zero: the executor reports the failure, then the end of the session
+	executor := &fakeExecutor{session: func(story *domain.Story) []domain.ExecutionEvent {
+		return []domain.ExecutionEvent{
+			domain.NewStoryStartedEvent(story),
+			domain.NewErrorEvent(story.ID, "command failed: exit status 1"),
+			domain.NewStoryCompletedEvent(story),
+		}
+	}}
+	svc := NewProjectService(nil, executor, newMemoryRepository(project))
+	svc.SetStopOnFailure(true)
+
+	events, err := svc.RunProject(context.Background(), project.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var failed, completed bool
+	for event := range events {
+		switch event.Type {
+		case domain.EventTypeProjectFailed:
+			failed = true
+		case domain.EventTypeStoryCompleted:
+			completed = true
+		}
+	}
+
+	if !failed {
+		t.Error("no ProjectFailed event for a story whose session failed")
+	}
+	if completed {
+		t.Error("StoryCompleted sent for a story whose session failed")
+	}
+	if !first.IsFailed() {
+		t.Errorf("story 1 status = %v, want failed", first.Status)
+	}
+	if len(executor.ran) != 1 {
+		t.Errorf("executed stories %v, want only story 1", executor.ran)
+	}
+}
+
+func TestRunProjectLeavesCancelledStoryPending(t *testing.T) {
+	project := domain.NewProject("test", "/repo/prd.md", "/repo")
+	story := domain.NewStory("1", "First")
+	project.AddStory(story)
+
+	// The run is cancelled mid-session, and the executor reports it
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	executor := &fakeExecutor{session: func(story *domain.Story) []domain.ExecutionEvent {
+		cancel()
+		return []domain.ExecutionEvent{
+			domain.NewStoryStartedEvent(story),
+			domain.NewErrorEvent(story.ID, "execution cancelled"),
+		}
+	}}
+	svc := NewProjectService(nil, executor, newMemoryRepository(project))
+
+	events, err := svc.RunProject(ctx, project.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for event := range events {
+		if event.Type == domain.EventTypeStoryCompleted || event.Type == domain.EventTypeStoryFailed {
+			t.Errorf("got %s for a cancelled story", event.Type)
+		}
+	}
+
+	if story.Status != domain.StoryStatusPending {
+		t.Errorf("story status = %v, want pending", story.Status)
+	}
+	if project.IsComplete() {
+		t.Error("project complete after its only story was cancelled")
+	}
+}
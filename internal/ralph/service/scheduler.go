@@ -1,6 +1,8 @@
 package service
 
 import (
+	"path"
+	"path/filepath"
 	"sort"
 
 	"github.com/DylanSharp/dtools/internal/ralph/domain"
@@ -58,6 +60,37 @@ func (s *Scheduler) GetReadyStories(project *domain.Project) []*domain.Story {
 	return readyStories
 }
 
+// ConflictingStories returns an unfinished story of each project that may edit
+// the same files as the other if the projects run at the same time, or nils if
+// none can. Scopes are compared within the projects' work dirs; a story that
+// declares no scope conflicts with every story of the other project, so
+// without scopes two projects in the same checkout can't run side by side.
+func (s *Scheduler) ConflictingStories(project, other *domain.Project) (*domain.Story, *domain.Story) {
+	for _, story := range project.Stories {
+		if story.IsCompleted() {
+			continue
+		}
+		for _, otherStory := range other.Stories {
+			if otherStory.IsCompleted() {
+				continue
+			}
+			if domain.ScopesOverlap(workDirScope(project, story), workDirScope(other, otherStory)) {
+				return story, otherStory
+			}
+		}
+	}
+	return nil, nil
+}
+
+// workDirScope returns a story's declared scope as paths under its project's work dir
+func workDirScope(project *domain.Project, story *domain.Story) []string {
+	scope := story.Scope()
+	for i, entry := range scope {
+		scope[i] = path.Join(filepath.ToSlash(project.WorkDir), entry)
+	}
+	return scope
+}
+
 // GetBlockedStories returns all stories that are blocked by dependencies
 func (s *Scheduler) GetBlockedStories(project *domain.Project) []*domain.Story {
 	completedIDs := project.GetCompletedIDs()
@@ -225,3 +258,109 @@ func (s *Scheduler) GetExecutionOrder(project *domain.Project) []string {
 
 	return order
 }
+
+// GetExecutionWaves groups stories into waves whose dependencies are all in earlier
+// waves, so the stories within a wave could run in parallel. Stories that can never
+// run are returned with the reason: a missing or circular dependency, or a dependency
+// that itself never runs.
+func (s *Scheduler) GetExecutionWaves(project *domain.Project) ([][]string, map[string]string) {
+	placed := make(map[string]bool)
+	remaining := append([]*domain.Story{}, project.Stories...)
+
+	var waves [][]string
+	for len(remaining) > 0 {
+		var wave []*domain.Story
+		var next []*domain.Story
+		for _, story := range remaining {
+			ready := true
+			for _, depID := range story.DependsOn {
+				if !placed[depID] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				wave = append(wave, story)
+			} else {
+				next = append(next, story)
+			}
+		}
+		if len(wave) == 0 {
+			break
+		}
+
+		sort.SliceStable(wave, func(i, j int) bool {
+			return wave[i].Priority < wave[j].Priority
+		})
+		ids := make([]string, len(wave))
+		for i, story := range wave {
+			ids[i] = story.ID
+			placed[story.ID] = true
+		}
+		waves = append(waves, ids)
+		remaining = next
+	}
+
+	unreachable := make(map[string]string)
+	for _, story := range remaining {
+		unreachable[story.ID] = s.unreachableReason(project, story, placed)
+	}
+
+	return waves, unreachable
+}
+
+// GetStalledStories returns the pending and blocked stories that can never run
+// without changing the PRD, with the reason: a dependency that failed, directly
+// or further up the chain, or one that's missing or part of a cycle
+func (s *Scheduler) GetStalledStories(project *domain.Project) map[string]string {
+	_, unreachable := s.GetExecutionWaves(project)
+
+	stalled := make(map[string]string)
+	for _, story := range project.Stories {
+		if !story.IsPending() && !story.IsBlocked() {
+			continue
+		}
+		if reason, ok := unreachable[story.ID]; ok {
+			stalled[story.ID] = reason
+			continue
+		}
+		for _, depID := range s.GetDependencyChain(project, story.ID) {
+			if dep := project.GetStory(depID); depID != story.ID && dep != nil && dep.IsFailed() {
+				stalled[story.ID] = "depends on failed story " + depID
+				break
+			}
+		}
+	}
+
+	return stalled
+}
+
+// unreachableReason explains why a story that was never placed in a wave can't run
+func (s *Scheduler) unreachableReason(project *domain.Project, story *domain.Story, placed map[string]bool) string {
+	for _, depID := range story.DependsOn {
+		if !project.StoryExists(depID) {
+			return "depends on missing story " + depID
+		}
+	}
+	for _, depID := range s.GetDependencyChain(project, story.ID) {
+		if depID != story.ID && s.dependsOn(project, depID, story.ID) {
+			return "circular dependency with " + depID
+		}
+	}
+	for _, depID := range story.DependsOn {
+		if !placed[depID] {
+			return "depends on " + depID + ", which never runs"
+		}
+	}
+	return "unknown"
+}
+
+// dependsOn reports whether story id transitively depends on target
+func (s *Scheduler) dependsOn(project *domain.Project, id, target string) bool {
+	for _, depID := range s.GetDependencyChain(project, id) {
+		if depID == target && depID != id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/DylanSharp/dtools/internal/ralph/domain"
+)
+
+func TestConflictingStories(t *testing.T) {
+	project := func(workDir string, scopes ...string) *domain.Project {
+		p := domain.NewProject(workDir, workDir+"/prd.md", workDir)
+		for i, scope := range scopes {
+			story := domain.NewStory(string(rune('1'+i)), "Story")
+			story.Metadata = map[string]string{domain.MetadataScope: scope}
+			p.AddStory(story)
+		}
+		return p
+	}
+
+	tests := []struct {
+		name     string
+		a, b     *domain.Project
+		conflict bool
+	}{
+		{"disjoint", project("/repo", "api"), project("/repo", "web"), false},
+		{"overlapping", project("/repo", "api", "docs"), project("/repo", "web", "docs/web.md"), true},
+		{"overlapping from another work dir", project("/repo", "api/handler.go"), project("/repo/api", "handler.go"), true},
+		{"disjoint from another work dir", project("/repo", "web"), project("/repo/api", "."), false},
+		{"undeclared", project("/repo", "api"), project("/repo", ""), true},
+	}
+	scheduler := NewScheduler()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			story, other := scheduler.ConflictingStories(tt.a, tt.b)
+			if got := story != nil; got != tt.conflict {
+				t.Errorf("conflict = %v (%v, %v), want %v", got, story, other, tt.conflict)
+			}
+		})
+	}
+
+	t.Run("completed stories", func(t *testing.T) {
+		a, b := project("/repo", "api"), project("/repo", "api")
+		a.Stories[0].MarkCompleted()
+		if story, _ := scheduler.ConflictingStories(a, b); story != nil {
+			t.Errorf("completed story %s conflicts", story.ID)
+		}
+	})
+}
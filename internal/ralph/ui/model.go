@@ -8,6 +8,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/DylanSharp/dtools/internal/ralph/domain"
 	"github.com/DylanSharp/dtools/internal/ralph/service"
+	dtui "github.com/DylanSharp/dtools/internal/ui"
 )
 
 // Model is the Bubbletea model for the ralph TUI
@@ -22,9 +23,15 @@ type Model struct {
 	width        int
 	height       int
 	scrollOffset int
+	follow       bool // Keep the latest events in view; scrolling up turns this off
 	err          error
 	streaming    bool
 	complete     bool
+	search       dtui.Search
+
+	// Wait indicator, shown until Claude's first output
+	streamStarted time.Time
+	ticks         int
 
 	// Services
 	service *service.ProjectService
@@ -48,6 +55,8 @@ func NewModel(
 		statusBar: NewStatusBar(),
 		service:   svc,
 		projectID: projectID,
+		search:    dtui.NewSearch(),
+		follow:    true,
 		ctx:       ctx,
 		cancel:    cancel,
 	}
@@ -73,6 +82,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		return m.handleKeyPress(msg)
 
+	case tea.MouseMsg:
+		return m.handleMouse(msg)
+
 	case ProjectLoadedMsg:
 		m.project = msg.Project
 		m.statusBar.Update(msg.Project)
@@ -82,6 +94,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case StreamStartedMsg:
 		m.eventsChan = msg.Events
 		m.streaming = true
+		m.streamStarted = time.Now()
 		return m, m.readEventCmd()
 
 	case ExecutionEventMsg:
@@ -98,8 +111,10 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
-		// Auto-scroll to bottom
-		m.scrollToBottom()
+		// Auto-scroll to bottom while following, unless the user is looking at search results
+		if m.follow && !m.search.Active() {
+			m.scrollToBottom()
+		}
 
 		// Continue reading events
 		if m.eventsChan != nil {
@@ -124,6 +139,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case TickMsg:
+		m.ticks++
 		return m, tickCmd()
 
 	case ProjectCompleteMsg:
@@ -153,45 +169,95 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// Handle search input
+	if m.search.Editing {
+		if msg.String() == "ctrl+c" {
+			m.cancel()
+			return m, tea.Quit
+		}
+		if m.search.HandleInput(msg) && m.search.Active() {
+			// Incremental search: stay on the current match while it still matches
+			from := m.search.Current
+			if from < 0 {
+				from = m.scrollOffset
+			}
+			m.jumpToMatch(from, 1)
+		}
+		return m, nil
+	}
+
 	switch msg.String() {
 	case "q", "Q", "ctrl+c":
 		m.cancel()
 		return m, tea.Quit
 
-	case "up", "k":
-		if m.scrollOffset > 0 {
-			m.scrollOffset--
+	case "/":
+		m.search = dtui.NewSearch()
+		m.search.Editing = true
+		return m, nil
+
+	case "n":
+		if m.search.Active() {
+			m.jumpToMatch(m.search.Current+1, 1)
+		}
+		return m, nil
+
+	case "N":
+		if m.search.Active() {
+			m.jumpToMatch(m.search.Current-1, -1)
 		}
 		return m, nil
 
+	case "esc":
+		m.search.Clear()
+		return m, nil
+
+	case "up", "k":
+		m.scrollBy(-1)
+		return m, nil
+
 	case "down", "j":
-		m.scrollOffset++
+		m.scrollBy(1)
 		return m, nil
 
 	case "pgup":
-		m.scrollOffset -= 10
-		if m.scrollOffset < 0 {
-			m.scrollOffset = 0
-		}
+		m.scrollBy(-10)
 		return m, nil
 
 	case "pgdown":
-		m.scrollOffset += 10
+		m.scrollBy(10)
 		return m, nil
 
 	case "home", "g":
-		m.scrollOffset = 0
+		m.scrollBy(-m.scrollOffset)
 		return m, nil
 
 	case "end", "G":
 		m.scrollToBottom()
 		return m, nil
 
+	case "f", "F":
+		// Toggle following new output; turning it on jumps to the latest
+		if m.follow {
+			m.follow = false
+		} else {
+			m.scrollToBottom()
+		}
+		return m, nil
+
+	case "s":
+		// Skip just the running story; the run continues with the next one
+		if m.streaming {
+			m.service.SkipCurrentStory()
+		}
+		return m, nil
+
 	case "r", "R":
 		if !m.streaming && !m.complete {
 			// Restart execution
 			m.events = []domain.ExecutionEvent{}
 			m.scrollOffset = 0
+			m.follow = true
 			return m, m.startExecutionCmd()
 		}
 		return m, nil
@@ -200,16 +266,71 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// scrollToBottom scrolls to show the latest content
+// handleMouse scrolls the event list with the mouse wheel
+func (m *Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.scrollBy(-mouseWheelStep)
+
+	case tea.MouseButtonWheelDown:
+		m.scrollBy(mouseWheelStep)
+	}
+
+	return m, nil
+}
+
+// scrollToBottom scrolls to show the latest content and follows new content from there
 func (m *Model) scrollToBottom() {
+	m.scrollOffset = m.maxScrollOffset()
+	m.follow = true
+}
+
+// scrollBy scrolls by delta lines within bounds. Scrolling away from the bottom
+// stops following new content; scrolling back down to it follows again.
+func (m *Model) scrollBy(delta int) {
+	m.scrollOffset, m.follow = dtui.ScrollBy(m.scrollOffset, delta, m.maxScrollOffset())
+}
+
+// maxScrollOffset returns the scroll offset that shows the last event
+func (m *Model) maxScrollOffset() int {
+	viewHeight := m.viewportHeight()
+
+	if len(m.events) > viewHeight {
+		return len(m.events) - viewHeight
+	}
+	return 0
+}
+
+// viewportHeight returns the number of event lines that fit on screen
+func (m *Model) viewportHeight() int {
 	viewHeight := m.height - statusBarHeight - helpHeight - headerHeight - 2
 	if viewHeight < minViewportHeight {
 		viewHeight = minViewportHeight
 	}
+	return viewHeight
+}
 
-	if len(m.events) > viewHeight {
-		m.scrollOffset = len(m.events) - viewHeight
-	} else {
+// searchTexts returns the searchable text of each event
+func (m *Model) searchTexts() []string {
+	texts := make([]string, len(m.events))
+	for i, event := range m.events {
+		texts[i] = eventSearchText(event)
+	}
+	return texts
+}
+
+// jumpToMatch moves to the next search match starting at from in direction dir
+// and scrolls it into the middle of the viewport
+func (m *Model) jumpToMatch(from, dir int) {
+	idx := m.search.Find(m.searchTexts(), from, dir)
+	m.search.Current = idx
+	if idx < 0 {
+		return
+	}
+	m.follow = false
+
+	m.scrollOffset = idx - m.viewportHeight()/2
+	if m.scrollOffset < 0 {
 		m.scrollOffset = 0
 	}
 }
@@ -275,21 +396,27 @@ func (m *Model) IsStreaming() bool {
 	return m.streaming
 }
 
-// StatusModel creates a simple model for displaying status (non-interactive)
+// StatusModel displays project status with expandable acceptance criteria per story
 type StatusModel struct {
-	project *domain.Project
-	width   int
-	height  int
+	project  *domain.Project
+	width    int
+	height   int
+	cursor   int             // Index of the selected story
+	expanded map[string]bool // Story IDs whose criteria are shown
 }
 
 // NewStatusModel creates a new status display model
 func NewStatusModel(project *domain.Project) *StatusModel {
-	return &StatusModel{project: project}
+	return &StatusModel{
+		project:  project,
+		expanded: make(map[string]bool),
+	}
 }
 
 // Init implements tea.Model
 func (m *StatusModel) Init() tea.Cmd {
-	return nil
+	// Tick so running stories' durations stay current
+	return tickCmd()
 }
 
 // Update implements tea.Model
@@ -299,8 +426,51 @@ func (m *StatusModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 	case tea.KeyMsg:
+		return m.handleKeyPress(msg)
+	case TickMsg:
+		return m, tickCmd()
+	}
+	return m, nil
+}
+
+// handleKeyPress moves the selection and expands/collapses stories
+func (m *StatusModel) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.project == nil || len(m.project.Stories) == 0 {
 		return m, tea.Quit
 	}
+
+	switch msg.String() {
+	case "q", "Q", "esc", "ctrl+c":
+		return m, tea.Quit
+
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+
+	case "down", "j":
+		if m.cursor < len(m.project.Stories)-1 {
+			m.cursor++
+		}
+
+	case "enter", " ", "right", "left", "l", "h":
+		id := m.project.Stories[m.cursor].ID
+		m.expanded[id] = !m.expanded[id]
+
+	case "a":
+		// Expand all, or collapse all if everything is already expanded
+		expandAll := false
+		for _, story := range m.project.Stories {
+			if !m.expanded[story.ID] {
+				expandAll = true
+				break
+			}
+		}
+		for _, story := range m.project.Stories {
+			m.expanded[story.ID] = expandAll
+		}
+	}
+
 	return m, nil
 }
 
@@ -320,12 +490,16 @@ func (m *StatusModel) View() string {
 	summary := RenderProgressSummary(m.project)
 	sections = append(sections, summary)
 
-	// Story list
-	storyList := RenderStoryList(m.project, "", m.width)
+	if warning := RenderStalledWarning(m.project, service.NewScheduler().GetStalledStories(m.project), m.width); warning != "" {
+		sections = append(sections, warning)
+	}
+
+	// Story list with acceptance criteria
+	storyList := RenderStoryCriteriaList(m.project, m.cursor, m.expanded, m.width)
 	sections = append(sections, storyList)
 
 	// Help
-	help := helpStyle.Render("Press any key to exit")
+	help := helpStyle.Render("↑/↓ select • enter expand • a expand all • q quit")
 	sections = append(sections, help)
 
 	return lipgloss.JoinVertical(lipgloss.Left, sections...)
@@ -7,6 +7,7 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/DylanSharp/dtools/internal/ralph/domain"
+	dtui "github.com/DylanSharp/dtools/internal/ui"
 )
 
 // StatusBar displays project execution progress
@@ -185,10 +186,10 @@ func (s *StatusBar) RenderCompact(width int) string {
 	status := fmt.Sprintf("%d/%d (%d%%)", s.CompletedStories, s.TotalStories, percent)
 
 	if s.CurrentStory != "" {
-		maxLen := width - len(status) - 10
+		maxLen := width - lipgloss.Width(status) - 10
 		story := s.CurrentStory
-		if len(story) > maxLen && maxLen > 10 {
-			story = story[:maxLen-3] + "..."
+		if maxLen > 10 {
+			story = dtui.TruncateWidth(story, maxLen)
 		}
 		return fmt.Sprintf("%s │ %s", status, story)
 	}
@@ -41,6 +41,16 @@ var (
 			Bold(true)
 )
 
+// Search styles
+var (
+	searchMatchStyle = lipgloss.NewStyle().
+				Foreground(colorWarning)
+
+	searchCurrentStyle = lipgloss.NewStyle().
+				Foreground(colorWarning).
+				Bold(true)
+)
+
 // Story status styles
 var (
 	pendingStyle = lipgloss.NewStyle().
@@ -76,6 +86,13 @@ var (
 
 	thoughtGeneralStyle = lipgloss.NewStyle().
 				Foreground(lipgloss.Color("252"))
+
+	thoughtThinkingStyle = lipgloss.NewStyle().
+				Foreground(colorMuted).
+				Italic(true)
+
+	thoughtToolStyle = lipgloss.NewStyle().
+				Foreground(colorSecondary)
 )
 
 // Box styles
@@ -139,6 +156,10 @@ func GetThoughtStyle(thoughtType string) lipgloss.Style {
 		return thoughtSuggestionStyle
 	case "code":
 		return thoughtCodeStyle
+	case "thinking":
+		return thoughtThinkingStyle
+	case "tool":
+		return thoughtToolStyle
 	default:
 		return thoughtGeneralStyle
 	}
@@ -3,9 +3,11 @@ package ui
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/DylanSharp/dtools/internal/ralph/domain"
+	dtui "github.com/DylanSharp/dtools/internal/ui"
 )
 
 const (
@@ -13,6 +15,7 @@ const (
 	helpHeight        = 1
 	headerHeight      = 4
 	minViewportHeight = 5
+	mouseWheelStep    = 3
 )
 
 // RenderView renders the complete TUI view
@@ -69,14 +72,29 @@ func renderHeader(m *Model) string {
 func renderEventList(m *Model, height int) string {
 	if len(m.events) == 0 {
 		if m.streaming {
-			return mutedStyle.Render("Waiting for Claude...")
+			wait := fmt.Sprintf("%s Waiting for Claude... %s", dtui.SpinnerFrame(m.ticks), time.Since(m.streamStarted).Round(time.Second))
+			return mutedStyle.Render(wait)
 		}
 		return mutedStyle.Render("No events yet. Run a project to see progress.")
 	}
 
 	var lines []string
-	for _, event := range m.events {
-		line := renderEvent(event, m.width)
+	for i, event := range m.events {
+		if !m.search.Active() {
+			lines = append(lines, renderEvent(event, m.width))
+			continue
+		}
+
+		// Leave room for the match gutter
+		line := renderEvent(event, m.width-2)
+		switch {
+		case i == m.search.Current:
+			line = searchCurrentStyle.Render("▶") + " " + line
+		case m.search.Matches(eventSearchText(event)):
+			line = searchMatchStyle.Render("▌") + " " + line
+		default:
+			line = "  " + line
+		}
 		lines = append(lines, line)
 	}
 
@@ -123,7 +141,12 @@ func renderEvent(event domain.ExecutionEvent, width int) string {
 		return highlightStyle.Render(fmt.Sprintf("━━━ Starting: [%s] %s ━━━", event.StoryID, event.Content))
 
 	case domain.EventTypeStoryCompleted:
-		return successStyle.Render(fmt.Sprintf("✓ Completed: [%s] %s", event.StoryID, event.Content))
+		line := successStyle.Render(fmt.Sprintf("✓ Completed: [%s] %s", event.StoryID, event.Content))
+		if files := event.Metadata[domain.MetadataFilesChanged]; files != "" {
+			changed := strings.Split(files, "\n")
+			line += mutedStyle.Render(fmt.Sprintf(" · %d files: %s", len(changed), strings.Join(changed, ", ")))
+		}
+		return dtui.TruncateWidth(line, width)
 
 	case domain.EventTypeStoryFailed:
 		return errorStyle.Render(fmt.Sprintf("✗ Failed: [%s] %s", event.StoryID, event.Content))
@@ -139,15 +162,33 @@ func renderEvent(event domain.ExecutionEvent, width int) string {
 	}
 }
 
+// eventSearchText returns the plain text of an event that search matches against
+func eventSearchText(event domain.ExecutionEvent) string {
+	parts := []string{event.Content}
+	if event.StoryID != "" {
+		parts = append(parts, event.StoryID)
+	}
+	if event.File != "" {
+		parts = append(parts, event.File)
+	}
+	if files := event.Metadata[domain.MetadataFilesChanged]; files != "" {
+		parts = append(parts, strings.Split(files, "\n")...)
+	}
+	return strings.Join(parts, " ")
+}
+
 // renderThought renders a thought event with appropriate styling
 func renderThought(event domain.ExecutionEvent, width int) string {
 	style := GetThoughtStyle(string(event.ThoughtType))
 
 	// Truncate long content
 	content := event.Content
+	if event.ThoughtType == domain.ThoughtTypeTool {
+		content = "→ " + content
+	}
 	maxLen := width - 4
-	if maxLen > 0 && len(content) > maxLen {
-		content = content[:maxLen-3] + "..."
+	if maxLen > 0 {
+		content = dtui.TruncateWidth(content, maxLen)
 	}
 
 	// Add file context if present
@@ -173,10 +214,26 @@ func renderHelp(m *Model) string {
 		"g/G: top/bottom",
 	)
 
+	if !m.follow {
+		keys = append(keys, "f: follow")
+	}
+
+	if m.streaming {
+		keys = append(keys, "s: skip story")
+	}
+
 	if !m.streaming && m.project != nil && !m.project.IsComplete() {
 		keys = append(keys, "r: restart")
 	}
 
+	if m.search.Editing {
+		keys = []string{m.search.RenderPrompt(nil), "enter: done", "esc: cancel"}
+	} else if m.search.Active() {
+		keys = append(keys, "n/N: next/prev", "esc: clear search", m.search.RenderPrompt(m.searchTexts()))
+	} else {
+		keys = append(keys, "/: search")
+	}
+
 	return helpStyle.Render(strings.Join(keys, " │ "))
 }
 
@@ -211,6 +268,9 @@ func RenderStoryList(project *domain.Project, currentID string, width int) strin
 		}
 
 		line := fmt.Sprintf("%s%s %s: %s", prefix, icon, story.ID, story.Title)
+		if elapsed := FormatStoryDuration(story); elapsed != "" {
+			line += mutedStyle.Render(" · " + elapsed)
+		}
 
 		// Add dependency info for blocked stories
 		if story.IsBlocked() && len(story.DependsOn) > 0 {
@@ -220,8 +280,8 @@ func RenderStoryList(project *domain.Project, currentID string, width int) strin
 
 		// Truncate if needed
 		maxLen := width - 2
-		if maxLen > 0 && len(line) > maxLen {
-			line = line[:maxLen-3] + "..."
+		if maxLen > 0 {
+			line = dtui.TruncateWidth(line, maxLen)
 		}
 
 		lines = append(lines, style.Render(line))
@@ -230,6 +290,81 @@ func RenderStoryList(project *domain.Project, currentID string, width int) strin
 	return strings.Join(lines, "\n")
 }
 
+// RenderStoryCriteriaList renders stories with their acceptance-criteria progress,
+// showing the checklist for expanded stories
+func RenderStoryCriteriaList(project *domain.Project, cursor int, expanded map[string]bool, width int) string {
+	if project == nil || len(project.Stories) == 0 {
+		return mutedStyle.Render("No stories")
+	}
+
+	var lines []string
+	for i, story := range project.Stories {
+		icon := GetStatusIcon(string(story.Status))
+		style := GetStoryStatusStyle(string(story.Status))
+
+		prefix := "  "
+		if i == cursor {
+			prefix = "▶ "
+		}
+
+		metadataKeys := story.DeclaredMetadataKeys()
+		toggle := " "
+		if len(story.AcceptanceCriteria) > 0 || len(metadataKeys) > 0 {
+			toggle = "▸"
+			if expanded[story.ID] {
+				toggle = "▾"
+			}
+		}
+
+		line := fmt.Sprintf("%s%s %s %s: %s", prefix, toggle, icon, story.ID, story.Title)
+		elapsed := FormatStoryDuration(story)
+
+		// Truncate if needed, leaving room for the duration and criteria count
+		maxLen := width - 10
+		if elapsed != "" {
+			maxLen -= len(elapsed) + 3
+		}
+		if maxLen > 3 {
+			line = dtui.TruncateWidth(line, maxLen)
+		}
+
+		rendered := style.Render(line)
+		if i == cursor {
+			rendered = highlightStyle.Render(line)
+		}
+		if elapsed != "" {
+			rendered += mutedStyle.Render(" · " + elapsed)
+		}
+
+		done, total := story.CriteriaProgress()
+		if total > 0 {
+			count := fmt.Sprintf(" [%d/%d]", done, total)
+			if done == total {
+				rendered += successStyle.Render(count)
+			} else {
+				rendered += mutedStyle.Render(count)
+			}
+		}
+		lines = append(lines, rendered)
+
+		if !expanded[story.ID] {
+			continue
+		}
+		for _, key := range metadataKeys {
+			lines = append(lines, mutedStyle.Render(fmt.Sprintf("      %s: %s", key, story.Metadata[key])))
+		}
+		for j, criterion := range story.AcceptanceCriteria {
+			if story.IsCriterionDone(j) {
+				lines = append(lines, successStyle.Render("      ✓ ")+criterion)
+			} else {
+				lines = append(lines, mutedStyle.Render("      ☐ ")+criterion)
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
 // RenderProgressSummary renders a progress summary
 func RenderProgressSummary(project *domain.Project) string {
 	if project == nil {
@@ -250,6 +385,38 @@ func RenderProgressSummary(project *domain.Project) string {
 	if project.FailedStories() > 0 {
 		parts = append(parts, errorStyle.Render(fmt.Sprintf("Failed: %d", project.FailedStories())))
 	}
+	if total := project.StoriesDuration(); total > 0 {
+		parts = append(parts, mutedStyle.Render("Time: "+total.Round(time.Second).String()))
+	}
 
 	return strings.Join(parts, " │ ")
 }
+
+// RenderStalledWarning lists the stories that can never run because of a failed,
+// missing or circular dependency, or returns "" if there are none
+func RenderStalledWarning(project *domain.Project, stalled map[string]string, width int) string {
+	if len(stalled) == 0 {
+		return ""
+	}
+
+	lines := []string{warningStyle.Render(fmt.Sprintf("⚠ %d stalled, fix the PRD and run 'dtools ralph refresh':", len(stalled)))}
+	for _, story := range project.Stories {
+		if reason, ok := stalled[story.ID]; ok {
+			line := fmt.Sprintf("  %s: %s", story.ID, reason)
+			if width > 2 {
+				line = dtui.TruncateWidth(line, width-2)
+			}
+			lines = append(lines, mutedStyle.Render(line))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FormatStoryDuration returns the time spent on a running or completed story,
+// rounded to the second, or "" if the story isn't timed
+func FormatStoryDuration(story *domain.Story) string {
+	if !story.IsTimed() {
+		return ""
+	}
+	return story.Duration().Round(time.Second).String()
+}
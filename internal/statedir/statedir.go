@@ -0,0 +1,76 @@
+// Package statedir resolves the directories dtools keeps its config and state in.
+package statedir
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// EnvVar overrides the state directory, e.g. for tests or separate accounts
+const EnvVar = "DTOOLS_STATE_DIR"
+
+// ConfigDir returns dtools' directory in the user config directory
+// ($XDG_CONFIG_HOME/dtools or ~/.config/dtools on Linux). If that can't be
+// determined, e.g. because HOME isn't set, it falls back to a dtools directory
+// in the system temp directory rather than the current directory.
+func ConfigDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil || dir == "" {
+		return filepath.Join(os.TempDir(), "dtools")
+	}
+	return filepath.Join(dir, "dtools")
+}
+
+// Dir returns the base directory for dtools state: $DTOOLS_STATE_DIR if set,
+// otherwise ConfigDir. Every tool resolves it the same way, so concurrent
+// review and ralph runs see the same override.
+func Dir() string {
+	if dir := os.Getenv(EnvVar); dir != "" {
+		return dir
+	}
+	return ConfigDir()
+}
+
+// MigrateLegacy moves name (a file or directory) from the legacy
+// ~/.config/dtools into Dir, unless it's already there. It's best-effort:
+// on failure the old location is simply left in place.
+func MigrateLegacy(name string) {
+	migrate(name, Dir())
+}
+
+// MigrateLegacyConfig is MigrateLegacy for config files, which live in
+// ConfigDir even when $DTOOLS_STATE_DIR moves the state elsewhere
+func MigrateLegacyConfig(name string) {
+	migrate(name, ConfigDir())
+}
+
+// migrate moves name from the legacy directory into target
+func migrate(name, target string) {
+	legacy := legacyDir()
+	if legacy == "" || filepath.Clean(legacy) == filepath.Clean(target) {
+		return
+	}
+
+	from := filepath.Join(legacy, name)
+	to := filepath.Join(target, name)
+	if _, err := os.Stat(from); err != nil {
+		return
+	}
+	if _, err := os.Stat(to); err == nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return
+	}
+	_ = os.Rename(from, to)
+}
+
+// legacyDir is where dtools kept everything before following the XDG spec
+func legacyDir() string {
+	home := os.Getenv("HOME")
+	if home == "" {
+		return ""
+	}
+	return filepath.Join(home, ".config", "dtools")
+}
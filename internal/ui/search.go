@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Search holds the state of an in-list text search in a TUI
+type Search struct {
+	Editing bool   // Typing a query in the search input
+	Query   string // Current query; empty means no search is active
+	Current int    // Index of the current match in the list, -1 if none
+}
+
+// NewSearch creates an inactive search
+func NewSearch() Search {
+	return Search{Current: -1}
+}
+
+// Active reports whether a query is set
+func (s *Search) Active() bool {
+	return s.Query != ""
+}
+
+// Matches reports whether text matches the query (case-insensitive)
+func (s *Search) Matches(text string) bool {
+	if s.Query == "" {
+		return false
+	}
+	return strings.Contains(strings.ToLower(text), strings.ToLower(s.Query))
+}
+
+// Clear resets the search
+func (s *Search) Clear() {
+	*s = NewSearch()
+}
+
+// HandleInput applies a key press to the search input.
+// It returns true if the query changed.
+func (s *Search) HandleInput(msg tea.KeyMsg) bool {
+	switch msg.Type {
+	case tea.KeyEnter:
+		s.Editing = false
+		if s.Query == "" {
+			s.Clear()
+		}
+		return false
+
+	case tea.KeyEsc:
+		s.Clear()
+		return true
+
+	case tea.KeyBackspace:
+		if s.Query == "" {
+			return false
+		}
+		runes := []rune(s.Query)
+		s.Query = string(runes[:len(runes)-1])
+		return true
+
+	case tea.KeySpace:
+		s.Query += " "
+		return true
+
+	case tea.KeyRunes:
+		s.Query += string(msg.Runes)
+		return true
+	}
+
+	return false
+}
+
+// Find returns the index of the next matching item starting at from and
+// moving in direction dir (1 or -1), wrapping around. Returns -1 if nothing matches.
+func (s *Search) Find(texts []string, from, dir int) int {
+	n := len(texts)
+	if n == 0 || s.Query == "" {
+		return -1
+	}
+
+	from = ((from % n) + n) % n
+	for i := 0; i < n; i++ {
+		idx := ((from+i*dir)%n + n) % n
+		if s.Matches(texts[idx]) {
+			return idx
+		}
+	}
+	return -1
+}
+
+// Count returns the number of matching items and the 1-based position
+// of the current match among them
+func (s *Search) Count(texts []string) (total, position int) {
+	for i, text := range texts {
+		if s.Matches(text) {
+			total++
+			if i == s.Current {
+				position = total
+			}
+		}
+	}
+	return total, position
+}
+
+// RenderPrompt renders the search input or the active query with its match count
+func (s *Search) RenderPrompt(texts []string) string {
+	if s.Editing {
+		return "/" + s.Query + "█"
+	}
+	if s.Query == "" {
+		return ""
+	}
+
+	total, position := s.Count(texts)
+	if total == 0 {
+		return "/" + s.Query + " (no matches)"
+	}
+	return fmt.Sprintf("/%s (%d/%d)", s.Query, position, total)
+}
@@ -0,0 +1,17 @@
+package ui
+
+// SpinnerFrames animate waits in the TUIs, one frame per tick
+var SpinnerFrames = []string{"◐", "◓", "◑", "◒"}
+
+// SpinnerFrame returns the spinner frame for a tick count
+func SpinnerFrame(ticks int) string {
+	return SpinnerFrames[ticks%len(SpinnerFrames)]
+}
+
+// ScrollBy moves a scroll offset by delta lines, keeping it between 0 and limit,
+// the offset that shows the last line. follow reports whether the new offset is
+// at the bottom, where a view keeps up with new content.
+func ScrollBy(offset, delta, limit int) (newOffset int, follow bool) {
+	offset = min(max(offset+delta, 0), limit)
+	return offset, offset >= limit
+}
@@ -77,7 +77,7 @@ func selectExistingBranch(repo *worktree.Repo) (string, error) {
 	}
 
 	if len(local) == 0 && len(remote) == 0 {
-		return "", fmt.Errorf("no other branches available\nCreate a new branch first or use: worktree-dev create <new-branch-name>")
+		return "", fmt.Errorf("no other branches available\nCreate a new branch first or use: dtools worktree create <new-branch-name>")
 	}
 
 	// Build options list
@@ -112,3 +112,28 @@ func selectExistingBranch(repo *worktree.Repo) (string, error) {
 
 	return selected, nil
 }
+
+// Confirm asks the user a yes/no question, defaulting to no
+func Confirm(title string) (bool, error) {
+	var confirmed bool
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title(title).
+				Affirmative("Yes").
+				Negative("No").
+				Value(&confirmed),
+		),
+	)
+
+	err := form.Run()
+	if err != nil {
+		if err == huh.ErrUserAborted {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return confirmed, nil
+}
@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SplitAtWidth splits plain text after the last rune that fits within width
+// terminal cells
+func SplitAtWidth(s string, width int) (string, string) {
+	used := 0
+	for i, r := range s {
+		w := lipgloss.Width(string(r))
+		if used+w > width {
+			return s[:i], s[i:]
+		}
+		used += w
+	}
+	return s, ""
+}
+
+// TruncateWidth shortens s to at most maxWidth terminal cells, ending with "..."
+// when there's room for it. Width is measured the way the terminal displays it,
+// so wide runes such as emoji count double. ANSI escape sequences take no width
+// and are all kept, so styles opened before the cut are still reset after it.
+func TruncateWidth(s string, maxWidth int) string {
+	if lipgloss.Width(s) <= maxWidth {
+		return s
+	}
+
+	limit, ellipsis := maxWidth-3, "..."
+	if maxWidth <= 3 {
+		limit, ellipsis = maxWidth, ""
+	}
+
+	var b strings.Builder
+	used := 0
+	inEscape, cut := false, false
+	for _, r := range s {
+		if inEscape {
+			b.WriteRune(r)
+			// CSI sequences end with a byte in the range @ to ~
+			if r != '[' && r >= '@' && r <= '~' {
+				inEscape = false
+			}
+			continue
+		}
+		if r == '\x1b' {
+			inEscape = true
+			b.WriteRune(r)
+			continue
+		}
+
+		w := lipgloss.Width(string(r))
+		if cut || used+w > limit {
+			cut = true
+			continue
+		}
+		b.WriteRune(r)
+		used += w
+	}
+
+	return b.String() + ellipsis
+}
@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestTruncateWidth(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		maxWidth int
+		want     string
+	}{
+		{"fits", "hello", 5, "hello"},
+		{"ascii", "hello world", 8, "hello..."},
+		{"emoji count double", "🐛 Fix 🎉 party", 9, "🐛 Fix..."},
+		{"wide rune not split", "ab🎉cd", 5, "ab..."},
+		{"bullets", "• one • two • three", 10, "• one •..."},
+		{"cjk", "日本語のテキスト", 9, "日本語..."},
+		{"no room for ellipsis", "🐛🐛🐛", 3, "🐛"},
+		{"zero width", "hello", 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateWidth(tt.s, tt.maxWidth)
+			if got != tt.want {
+				t.Errorf("TruncateWidth(%q, %d) = %q, want %q", tt.s, tt.maxWidth, got, tt.want)
+			}
+			if w := lipgloss.Width(got); w > tt.maxWidth {
+				t.Errorf("TruncateWidth(%q, %d) is %d cells wide", tt.s, tt.maxWidth, w)
+			}
+		})
+	}
+}
+
+func TestTruncateWidthKeepsEscapes(t *testing.T) {
+	bold, reset := "\x1b[1m", "\x1b[0m"
+	got := TruncateWidth(bold+"🚀 Deploy to production"+reset, 10)
+	if want := bold + "🚀 Depl" + reset + "..."; got != want {
+		t.Errorf("TruncateWidth = %q, want %q", got, want)
+	}
+}
+
+func TestSplitAtWidth(t *testing.T) {
+	head, tail := SplitAtWidth("✅✅✅done", 5)
+	if head != "✅✅" || tail != "✅done" {
+		t.Errorf("SplitAtWidth = %q, %q, want %q, %q", head, tail, "✅✅", "✅done")
+	}
+}
@@ -0,0 +1,115 @@
+package worktree
+
+import (
+	"strings"
+)
+
+// composeSegment is a run of docker-compose.yml lines: either one service's
+// block or text outside the services section
+type composeSegment struct {
+	Service  string   // Empty outside the services section
+	Profiles []string // Profiles the service is gated behind; empty means always active
+	Text     string
+}
+
+// parseComposeSegments splits docker-compose.yml into segments in file order.
+// It reads indentation rather than fully parsing YAML, which is enough to tell
+// which service a line belongs to and which profiles gate it.
+func parseComposeSegments(content string) []composeSegment {
+	var segments []composeSegment
+	var current *composeSegment
+	var lines []string
+
+	flush := func() {
+		if current != nil {
+			current.Text = strings.Join(lines, "\n")
+			current.Profiles = parseServiceProfiles(lines)
+			segments = append(segments, *current)
+		}
+		current = &composeSegment{}
+		lines = nil
+	}
+	flush()
+
+	inServices := false
+	serviceIndent := -1
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			lines = append(lines, line)
+			continue
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		switch {
+		case indent == 0:
+			// A top-level key starts or ends the services section
+			flush()
+			inServices = strings.TrimSpace(stripYAMLComment(line)) == "services:"
+			serviceIndent = -1
+
+		case inServices && (serviceIndent == -1 || indent == serviceIndent) && strings.HasSuffix(stripYAMLComment(trimmed), ":"):
+			serviceIndent = indent
+			flush()
+			current.Service = unquoteYAML(strings.TrimSuffix(stripYAMLComment(trimmed), ":"))
+		}
+
+		lines = append(lines, line)
+	}
+	flush()
+
+	var nonEmpty []composeSegment
+	for _, segment := range segments {
+		if strings.TrimSpace(segment.Text) != "" {
+			nonEmpty = append(nonEmpty, segment)
+		}
+	}
+	return nonEmpty
+}
+
+// parseServiceProfiles finds a service's profiles, written either as
+// profiles: [a, b] or as a block list
+func parseServiceProfiles(lines []string) []string {
+	var profiles []string
+	for i, line := range lines {
+		value, ok := strings.CutPrefix(strings.TrimSpace(stripYAMLComment(line)), "profiles:")
+		if !ok {
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+		if strings.HasPrefix(value, "[") {
+			for _, item := range strings.Split(strings.Trim(value, "[]"), ",") {
+				if item = unquoteYAML(item); item != "" {
+					profiles = append(profiles, item)
+				}
+			}
+			return profiles
+		}
+
+		for _, next := range lines[i+1:] {
+			item, ok := strings.CutPrefix(strings.TrimSpace(stripYAMLComment(next)), "-")
+			if !ok {
+				break
+			}
+			if item = unquoteYAML(item); item != "" {
+				profiles = append(profiles, item)
+			}
+		}
+		return profiles
+	}
+	return nil
+}
+
+// stripYAMLComment removes a trailing " # comment" from a line
+func stripYAMLComment(line string) string {
+	if i := strings.Index(line, " #"); i >= 0 {
+		return strings.TrimRight(line[:i], " \t")
+	}
+	return line
+}
+
+// unquoteYAML trims whitespace and surrounding quotes from a scalar
+func unquoteYAML(value string) string {
+	return strings.Trim(strings.TrimSpace(value), `"'`)
+}
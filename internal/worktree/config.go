@@ -0,0 +1,64 @@
+package worktree
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// ConfigFileName is the optional per-repository worktree configuration, kept
+// in the repository root so it can be committed alongside docker-compose.yml
+const ConfigFileName = ".worktree.json"
+
+// Config holds per-repository worktree settings
+type Config struct {
+	// SharedPorts are port variables kept at their default in every worktree,
+	// for services that should be reachable at a stable port (e.g. a mail catcher)
+	SharedPorts []string `json:"shared_ports,omitempty"`
+
+	// Profiles are the Compose profiles the dev script enables on `up`
+	Profiles []string `json:"profiles,omitempty"`
+
+	// PortProfiles are further profiles whose services still get offset ports,
+	// for profiles started by hand with `./dev --profile <name> up`
+	PortProfiles []string `json:"port_profiles,omitempty"`
+}
+
+// loadConfig reads the repository's config file, returning an empty config if it doesn't exist
+func loadConfig(root string) (*Config, error) {
+	path := filepath.Join(root, ConfigFileName)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// isSharedPort returns true if a port variable is kept at its default across worktrees
+func (c *Config) isSharedPort(varName string) bool {
+	return slices.Contains(c.SharedPorts, varName)
+}
+
+// allocatesPorts returns true if a service gated behind profiles gets offset
+// ports, which is when it has no profiles or one of them is configured
+func (c *Config) allocatesPorts(profiles []string) bool {
+	if len(profiles) == 0 {
+		return true
+	}
+	for _, profile := range profiles {
+		if slices.Contains(c.Profiles, profile) || slices.Contains(c.PortProfiles, profile) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,50 +1,240 @@
 package worktree
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"hash/crc32"
+	"io"
+	"net"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"syscall"
+
+	"github.com/DylanSharp/dtools/internal/exec"
+	"github.com/DylanSharp/dtools/internal/logging"
 )
 
+// portVarPattern matches port variables with defaults like ${DJANGO_PORT:-8000}
+var portVarPattern = regexp.MustCompile(`\$\{([A-Z_]+_PORT):-(\d+)\}`)
+
 // PortVar represents a port variable found in docker-compose.yml
 type PortVar struct {
 	VarName string
 	Default int
+	Shared  bool // Kept at its default in every worktree rather than offset
+}
+
+// PortAssignment is the host port chosen for a port variable in a worktree
+type PortAssignment struct {
+	VarName string
+	Port    int
+	Reused  bool // Kept from a previous allocation rather than derived from the offset
+	Shared  bool // Not offset, so it conflicts between worktrees running at once
 }
 
-// detectPorts finds port variables in docker-compose.yml
-// Looks for patterns like ${DJANGO_PORT:-8000}
-func (r *Repo) detectPorts() []PortVar {
-	composePath := filepath.Join(r.Root, "docker-compose.yml")
-	content, err := os.ReadFile(composePath)
+// assignPorts picks a host port for each detected port variable. Ports from a
+// previous allocation are kept so recreating a worktree doesn't move its
+// services; only variables without one get their default plus offset.
+// Shared ports always get their default. Defaults come from .env when it sets
+// them (see applyEnvDefaults), so the precedence is .env.local > .env > compose.
+func assignPorts(ports []PortVar, offset int, previous map[string]int) []PortAssignment {
+	assignments := make([]PortAssignment, 0, len(ports))
+	for _, p := range ports {
+		if p.Shared {
+			assignments = append(assignments, PortAssignment{VarName: p.VarName, Port: p.Default, Shared: true})
+			continue
+		}
+		if port, ok := previous[p.VarName]; ok {
+			assignments = append(assignments, PortAssignment{VarName: p.VarName, Port: port, Reused: true})
+			continue
+		}
+		assignments = append(assignments, PortAssignment{VarName: p.VarName, Port: p.Default + offset})
+	}
+	return assignments
+}
+
+// applyEnvDefaults replaces compose defaults with the values an env file sets,
+// so offsets apply to a team's customized base ports
+func applyEnvDefaults(ports []PortVar, env map[string]int) []PortVar {
+	for i, p := range ports {
+		if port, ok := env[p.VarName]; ok {
+			ports[i].Default = port
+		}
+	}
+	return ports
+}
+
+// readEnvPorts reads numeric VAR=value assignments from an env file
+func readEnvPorts(path string) map[string]int {
+	content, err := os.ReadFile(path)
 	if err != nil {
 		return nil
 	}
 
-	// Match patterns like ${VAR_NAME:-default}
-	re := regexp.MustCompile(`\$\{([A-Z_]+_PORT):-(\d+)\}`)
-	matches := re.FindAllStringSubmatch(string(content), -1)
+	ports := make(map[string]int)
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			continue
+		}
+		if port, err := strconv.Atoi(unquoteYAML(value)); err == nil {
+			ports[strings.TrimSpace(name)] = port
+		}
+	}
+	return ports
+}
+
+// detectPorts finds port variables in docker-compose.yml. It returns nothing
+// if the file doesn't exist or can't be read; see readPorts.
+func (r *Repo) detectPorts() (ports []PortVar, skipped []PortVar) {
+	f, err := os.Open(filepath.Join(r.Root, "docker-compose.yml"))
+	if err != nil {
+		return nil, nil
+	}
+	defer f.Close()
+
+	ports, skipped, err = readPorts(f, r.Config)
+	if err != nil {
+		logging.Warn("failed to read docker-compose.yml", "err", err)
+		return nil, nil
+	}
+	return ports, skipped
+}
+
+// readPorts finds port variables in compose YAML, in the order they first
+// appear. Looks for patterns like ${DJANGO_PORT:-8000}. Variables used only by
+// services behind profiles cfg doesn't configure are returned as skipped instead.
+func readPorts(compose io.Reader, cfg *Config) (ports []PortVar, skipped []PortVar, err error) {
+	content, err := io.ReadAll(compose)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg == nil {
+		cfg = &Config{}
+	}
 
+	var found []PortVar
 	seen := make(map[string]bool)
-	var ports []PortVar
+	active := make(map[string]bool)
+
+	for _, segment := range parseComposeSegments(string(content)) {
+		allocate := cfg.allocatesPorts(segment.Profiles)
+		for _, match := range portVarPattern.FindAllStringSubmatch(segment.Text, -1) {
+			if len(match) < 3 {
+				continue
+			}
+			if allocate {
+				active[match[1]] = true
+			}
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				defaultPort, _ := strconv.Atoi(match[2])
+				found = append(found, PortVar{
+					VarName: match[1],
+					Default: defaultPort,
+					Shared:  cfg.isSharedPort(match[1]),
+				})
+			}
+		}
+	}
+
+	for _, p := range found {
+		if active[p.VarName] {
+			ports = append(ports, p)
+		} else {
+			skipped = append(skipped, p)
+		}
+	}
+	return ports, skipped, nil
+}
+
+// servicePort is a port variable used by a docker-compose.yml service
+type servicePort struct {
+	Service string
+	VarName string
+	Default int
+}
+
+// servicePorts returns the port variables each service in docker-compose.yml uses
+func (r *Repo) servicePorts() []servicePort {
+	content, err := os.ReadFile(filepath.Join(r.Root, "docker-compose.yml"))
+	if err != nil {
+		return nil
+	}
 
-	for _, match := range matches {
-		if len(match) >= 3 && !seen[match[1]] {
+	var ports []servicePort
+	for _, segment := range parseComposeSegments(string(content)) {
+		if segment.Service == "" {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, match := range portVarPattern.FindAllStringSubmatch(segment.Text, -1) {
+			if seen[match[1]] {
+				continue
+			}
 			seen[match[1]] = true
 			defaultPort, _ := strconv.Atoi(match[2])
-			ports = append(ports, PortVar{
-				VarName: match[1],
-				Default: defaultPort,
-			})
+			ports = append(ports, servicePort{Service: segment.Service, VarName: match[1], Default: defaultPort})
 		}
 	}
-
 	return ports
 }
 
+// PortStatus is whether a host port is currently bound
+type PortStatus int
+
+const (
+	PortUnknown PortStatus = iota // The check couldn't tell (e.g. a privileged port)
+	PortFree
+	PortInUse
+)
+
+// checkPort reports whether a host port is bound, by trying to listen on it
+func checkPort(port int) PortStatus {
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err == nil {
+		ln.Close()
+		return PortFree
+	}
+	if errors.Is(err, syscall.EADDRINUSE) {
+		return PortInUse
+	}
+	return PortUnknown
+}
+
+// publishedPorts maps host ports published by running containers to the
+// container's name. It's best-effort and returns nil if docker isn't available.
+func publishedPorts() map[int]string {
+	out, err := exec.Output(context.Background(), "docker", "ps", "--format", "{{.Names}}\t{{.Ports}}")
+	if err != nil {
+		return nil
+	}
+
+	// Ports look like 0.0.0.0:8001->8000/tcp, :::8001->8000/tcp
+	re := regexp.MustCompile(`:(\d+)->`)
+	owners := make(map[int]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		name, ports, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		for _, match := range re.FindAllStringSubmatch(ports, -1) {
+			if port, err := strconv.Atoi(match[1]); err == nil {
+				owners[port] = name
+			}
+		}
+	}
+	return owners
+}
+
 // getPortOffset calculates a stable port offset (1-99) from a branch name
 func getPortOffset(branch string) int {
 	hash := crc32.ChecksumIEEE([]byte(branch))
@@ -60,6 +250,42 @@ func sanitizeName(name string) string {
 	// Remove any character that isn't alphanumeric or hyphen
 	re := regexp.MustCompile(`[^a-z0-9-]`)
 	name = re.ReplaceAllString(name, "")
+	name = strings.Trim(name, "-")
+	if name == "" {
+		name = "worktree"
+	}
+	return name
+}
+
+// disambiguateName appends a short hash of the branch to a sanitized name,
+// for branches whose sanitized name is already taken by another branch
+func disambiguateName(safeName, branch string) string {
+	return fmt.Sprintf("%s-%06x", safeName, crc32.ChecksumIEEE([]byte(branch))&0xffffff)
+}
+
+// maxProjectNameLen keeps Compose project names within a DNS label, since
+// Compose derives network and container names from them
+const maxProjectNameLen = 63
+
+// invalidProjectChars matches characters Docker rejects in a project name
+var invalidProjectChars = regexp.MustCompile(`[^a-z0-9_-]`)
+
+// composeProjectName builds the COMPOSE_PROJECT_NAME for a worktree from the repo
+// name and the worktree's sanitized name. Docker only accepts lowercase letters,
+// digits, dashes and underscores, starting with a letter or digit. Names that are
+// too long are cut short and end in a hash of the full name, so they stay unique
+// and come out the same every time they're rebuilt for teardown.
+func composeProjectName(repoName, safeName string) string {
+	name := strings.ToLower(fmt.Sprintf("%s-%s", getProjectPrefix(repoName), safeName))
+	name = invalidProjectChars.ReplaceAllString(name, "")
+	name = strings.TrimLeft(name, "-_")
+	if name == "" {
+		name = "worktree"
+	}
+	if len(name) > maxProjectNameLen {
+		suffix := fmt.Sprintf("-%08x", crc32.ChecksumIEEE([]byte(name)))
+		name = strings.TrimRight(name[:maxProjectNameLen-len(suffix)], "-_") + suffix
+	}
 	return name
 }
 
@@ -0,0 +1,111 @@
+package worktree
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadPorts(t *testing.T) {
+	tests := []struct {
+		name        string
+		compose     string
+		cfg         *Config
+		wantPorts   []PortVar
+		wantSkipped []PortVar
+	}{
+		{
+			name: "ports in file order without duplicates",
+			compose: `services:
+  web:
+    ports:
+      - "${DJANGO_PORT:-8000}:8000"
+  db:
+    ports:
+      - "${POSTGRES_PORT:-5432}:5432"
+  worker:
+    environment:
+      DJANGO_PORT: ${DJANGO_PORT:-8000}
+`,
+			wantPorts: []PortVar{
+				{VarName: "DJANGO_PORT", Default: 8000},
+				{VarName: "POSTGRES_PORT", Default: 5432},
+			},
+		},
+		{
+			name: "shared ports from config",
+			compose: `services:
+  mail:
+    ports:
+      - "${MAILPIT_PORT:-8025}:8025"
+`,
+			cfg:       &Config{SharedPorts: []string{"MAILPIT_PORT"}},
+			wantPorts: []PortVar{{VarName: "MAILPIT_PORT", Default: 8025, Shared: true}},
+		},
+		{
+			name: "services behind unconfigured profiles are skipped",
+			compose: `services:
+  web:
+    ports:
+      - "${WEB_PORT:-3000}:3000"
+  docs:
+    profiles: [docs]
+    ports:
+      - "${DOCS_PORT:-4000}:4000"
+  flower:
+    profiles:
+      - monitoring # started by hand
+    ports:
+      - "${FLOWER_PORT:-5555}:5555"
+`,
+			cfg:         &Config{PortProfiles: []string{"monitoring"}},
+			wantPorts:   []PortVar{{VarName: "WEB_PORT", Default: 3000}, {VarName: "FLOWER_PORT", Default: 5555}},
+			wantSkipped: []PortVar{{VarName: "DOCS_PORT", Default: 4000}},
+		},
+		{
+			name: "a variable used by an active service is allocated",
+			compose: `services:
+  debug:
+    profiles: ["debug"]
+    ports:
+      - "${API_PORT:-8080}:8080"
+  api:
+    ports:
+      - "${API_PORT:-8080}:8080"
+`,
+			wantPorts: []PortVar{{VarName: "API_PORT", Default: 8080}},
+		},
+		{
+			name: "text outside services is always active",
+			compose: `x-common: &common
+  environment:
+    REDIS_PORT: ${REDIS_PORT:-6379}
+
+services:
+  cache:
+    profiles: [cache]
+    <<: *common
+`,
+			wantPorts: []PortVar{{VarName: "REDIS_PORT", Default: 6379}},
+		},
+		{
+			name:    "variables without a default are ignored",
+			compose: "services:\n  web:\n    ports:\n      - \"${WEB_PORT}:3000\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ports, skipped, err := readPorts(strings.NewReader(tt.compose), tt.cfg)
+			if err != nil {
+				t.Fatalf("readPorts() error = %v", err)
+			}
+			if !reflect.DeepEqual(ports, tt.wantPorts) {
+				t.Errorf("ports = %+v, want %+v", ports, tt.wantPorts)
+			}
+			if !reflect.DeepEqual(skipped, tt.wantSkipped) {
+				t.Errorf("skipped = %+v, want %+v", skipped, tt.wantSkipped)
+			}
+		})
+	}
+}
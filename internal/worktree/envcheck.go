@@ -0,0 +1,96 @@
+package worktree
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/DylanSharp/dtools/internal/envfile"
+)
+
+// productionEnvVars name the deployment environment in common frameworks
+var productionEnvVars = map[string]bool{
+	"APP_ENV":         true,
+	"APP_ENVIRONMENT": true,
+	"ENV":             true,
+	"ENVIRONMENT":     true,
+	"NODE_ENV":        true,
+	"RAILS_ENV":       true,
+	"RACK_ENV":        true,
+	"FLASK_ENV":       true,
+	"DJANGO_ENV":      true,
+	"GO_ENV":          true,
+	"MIX_ENV":         true,
+}
+
+// datastoreWords mark connection-string variables worth checking for a remote host
+var datastoreWords = []string{"DATABASE", "DB", "POSTGRES", "PG", "MYSQL", "MONGO", "REDIS", "AMQP", "RABBIT", "ELASTIC"}
+
+// productionMarkers returns the settings in an env file that suggest it points at
+// production: a production environment name, or a datastore on a remote host.
+// Descriptions never include secrets, only variable names and hosts.
+func productionMarkers(path string) ([]string, error) {
+	env, err := envfile.Read(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var markers []string
+	for _, assignment := range env {
+		name, value, _ := strings.Cut(assignment, "=")
+		name = strings.ToUpper(name)
+
+		switch {
+		case productionEnvVars[name]:
+			if v := strings.ToLower(value); v == "production" || v == "prod" || v == "live" {
+				markers = append(markers, fmt.Sprintf("%s=%s", name, value))
+			}
+		case strings.HasSuffix(name, "_HOST") && isDatastoreVar(name):
+			if remoteHost(value) {
+				markers = append(markers, fmt.Sprintf("%s points at %s", name, value))
+			}
+		case isDatastoreURLVar(name):
+			if u, err := url.Parse(value); err == nil && remoteHost(u.Hostname()) {
+				markers = append(markers, fmt.Sprintf("%s points at %s", name, u.Hostname()))
+			}
+		}
+	}
+	return markers, nil
+}
+
+// isDatastoreURLVar returns true for variables like DATABASE_URL or REDIS_URI
+func isDatastoreURLVar(name string) bool {
+	if !strings.HasSuffix(name, "_URL") && !strings.HasSuffix(name, "_URI") && !strings.HasSuffix(name, "_DSN") {
+		return false
+	}
+	return isDatastoreVar(name)
+}
+
+// isDatastoreVar returns true if a part of the variable's name is a datastore
+// word, as in POSTGRES_HOST or DB_URL but not API_HOST
+func isDatastoreVar(name string) bool {
+	for _, part := range strings.Split(name, "_") {
+		for _, word := range datastoreWords {
+			if strings.HasPrefix(part, word) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// remoteHost returns true for hosts outside this machine and its containers.
+// Bare names like "db" are docker-compose services, so only dotted names and
+// non-loopback IPs count.
+func remoteHost(host string) bool {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if host == "" || host == "localhost" || host == "host.docker.internal" ||
+		strings.HasSuffix(host, ".localhost") || strings.HasSuffix(host, ".local") {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return !ip.IsLoopback() && !ip.IsUnspecified()
+	}
+	return strings.Contains(host, ".")
+}
@@ -1,14 +1,17 @@
 package worktree
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/DylanSharp/dtools/internal/exec"
 )
 
 // Styles for output
@@ -22,11 +25,20 @@ var (
 	dimStyle     = lipgloss.NewStyle().Faint(true)
 )
 
+// envLocalBranchPrefix marks the line in .env.local recording the worktree's
+// real branch name, which can't be recovered from the sanitized directory name
+const envLocalBranchPrefix = "# Worktree: "
+
 // Repo represents a git repository with worktree management
 type Repo struct {
 	Root         string
 	Name         string
 	WorktreesDir string
+	Config       *Config
+
+	// Quiet limits CreateWorktree's output to machine-readable lines, sending
+	// warnings to stderr
+	Quiet bool
 }
 
 // NewRepo creates a new Repo from the current directory
@@ -46,10 +58,16 @@ func NewRepo() (*Repo, error) {
 		}
 	}
 
+	cfg, err := loadConfig(mainRoot)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Repo{
 		Root:         mainRoot,
 		Name:         filepath.Base(mainRoot),
 		WorktreesDir: filepath.Join(mainRoot, ".worktrees"),
+		Config:       cfg,
 	}, nil
 }
 
@@ -94,17 +112,45 @@ func (r *Repo) CurrentWorktree() string {
 	return ""
 }
 
-// CreateWorktree creates a new worktree for the given branch
-func (r *Repo) CreateWorktree(branch string) error {
-	safeName := sanitizeName(branch)
+// CreateWorktree creates a new worktree for the given branch.
+// Ports allocated to a previous worktree for the branch are reused unless reallocate is set.
+// With Quiet set it prints only the worktree path and its environment, as KEY=VALUE lines.
+// Containers left under the worktree's Compose project by an earlier run are
+// reported, or removed if cleanContainers is set.
+func (r *Repo) CreateWorktree(branch, from string, reallocate, allowProdEnv, cleanContainers bool) error {
+	if from != "" && !r.refExists(from) {
+		return fmt.Errorf("'%s' is not a branch, tag or commit in this repository\nRun 'git fetch' first if it's a remote branch", from)
+	}
+
+	// A worktree running against production infrastructure could do real damage.
+	// .env.example only holds placeholders, so only a real .env is checked.
+	var envMarkers []string
+	if envSource := r.envSource(); filepath.Base(envSource) == ".env" {
+		markers, err := productionMarkers(envSource)
+		if err != nil && !allowProdEnv {
+			return fmt.Errorf("could not check .env for production settings: %w\nFix it, or use --allow-prod-env to copy it anyway", err)
+		}
+		if len(markers) > 0 && !allowProdEnv {
+			return fmt.Errorf(".env looks like a production config:\n  %s\nPoint it at local services, or use --allow-prod-env to copy it anyway",
+				strings.Join(markers, "\n  "))
+		}
+		envMarkers = markers
+	}
+
+	safeName := r.resolveWorktreeName(branch)
 	worktreePath := filepath.Join(r.WorktreesDir, safeName)
 	offset := getPortOffset(safeName)
-	prefix := getProjectPrefix(r.Name)
+	projectName := composeProjectName(r.Name, safeName)
 
-	fmt.Println(infoStyle.Render("Creating worktree for branch:"), warnStyle.Render(branch))
-	fmt.Println(infoStyle.Render("Repository:"), r.Name)
-	fmt.Println(infoStyle.Render("Location:"), worktreePath)
-	fmt.Println()
+	r.say(infoStyle.Render("Creating worktree for branch:"), warnStyle.Render(branch))
+	r.say(infoStyle.Render("Repository:"), r.Name)
+	r.say(infoStyle.Render("Location:"), worktreePath)
+	r.say()
+
+	if safeName != sanitizeName(branch) {
+		r.warn(warnStyle.Render(fmt.Sprintf("'%s' is already used by another branch, using '%s'", sanitizeName(branch), safeName)))
+		r.say()
+	}
 
 	// Create worktrees directory
 	if err := os.MkdirAll(r.WorktreesDir, 0755); err != nil {
@@ -113,12 +159,12 @@ func (r *Repo) CreateWorktree(branch string) error {
 
 	// Add .worktrees to .gitignore
 	if err := r.ensureGitignore(); err != nil {
-		fmt.Println(warnStyle.Render("Warning: could not update .gitignore:"), err)
+		r.warn(warnStyle.Render("Warning: could not update .gitignore:"), err)
 	}
 
 	// Check if worktree already exists
 	if _, err := os.Stat(worktreePath); err == nil {
-		return fmt.Errorf("worktree already exists at %s\nUse 'worktree-dev remove %s' first if you want to recreate it", worktreePath, branch)
+		return fmt.Errorf("worktree already exists at %s\nUse 'dtools worktree remove %s' first if you want to recreate it", worktreePath, branch)
 	}
 
 	// Check if branch is currently checked out
@@ -127,30 +173,73 @@ func (r *Repo) CreateWorktree(branch string) error {
 		return fmt.Errorf("'%s' is currently checked out in the main repo\nSwitch to a different branch first, or create a worktree for a different branch", branch)
 	}
 
+	// Containers from a crashed run would clash with the new worktree's on ./dev up
+	if leftover := projectContainers(projectName, true); len(leftover) > 0 {
+		if cleanContainers {
+			r.say(infoStyle.Render(fmt.Sprintf("Removing %d leftover container(s) of project %s...", len(leftover), projectName)))
+			if err := r.removeContainers(projectName); err != nil {
+				return err
+			}
+		} else {
+			r.warn(warnStyle.Render(fmt.Sprintf("Docker project %s already has containers from an earlier run:", projectName)))
+			for _, name := range leftover {
+				r.warn(warnStyle.Render("  " + name))
+			}
+			r.warn(warnStyle.Render("./dev up may fail with \"container name already in use\". Use --clean-containers to remove them."))
+			r.say()
+		}
+	}
+
 	// Check if branch exists, create if not
 	if !r.branchExists(branch) {
 		if r.remoteBranchExists(branch) {
-			fmt.Println(infoStyle.Render("Branch exists on remote, will track origin/" + branch))
+			r.say(infoStyle.Render("Branch exists on remote, will track origin/" + branch))
+			if from != "" {
+				r.warn(warnStyle.Render("Ignoring --from " + from + ": it only applies to new branches"))
+			}
+		} else if from != "" {
+			r.say(warnStyle.Render("Branch '" + branch + "' doesn't exist. Creating new branch from " + from + "..."))
+			if err := r.git("branch", "--no-track", branch, from); err != nil {
+				return fmt.Errorf("failed to create branch: %w", err)
+			}
 		} else {
-			fmt.Println(warnStyle.Render("Branch '" + branch + "' doesn't exist. Creating new branch from current HEAD..."))
+			r.say(warnStyle.Render("Branch '" + branch + "' doesn't exist. Creating new branch from current HEAD..."))
 			if err := r.git("branch", branch); err != nil {
 				return fmt.Errorf("failed to create branch: %w", err)
 			}
 		}
+	} else if from != "" {
+		r.warn(warnStyle.Render("Ignoring --from " + from + ": branch '" + branch + "' already exists"))
 	}
 
 	// Create the worktree
-	fmt.Println(infoStyle.Render("Creating git worktree..."))
+	r.say(infoStyle.Render("Creating git worktree..."))
 	if err := r.git("worktree", "add", worktreePath, branch); err != nil {
 		return fmt.Errorf("failed to create worktree: %w", err)
 	}
 
 	// Copy .env files
 	r.copyEnvFiles(worktreePath)
+	if len(envMarkers) > 0 {
+		r.say()
+		r.warn(errorStyle.Bold(true).Render("WARNING: the copied .env looks like a production config:"))
+		for _, marker := range envMarkers {
+			r.warn(errorStyle.Render("  " + marker))
+		}
+		r.warn(warnStyle.Render("Services started in this worktree may run against real infrastructure."))
+		r.say()
+	}
 
 	// Detect ports and create config
-	ports := r.detectPorts()
-	projectName := fmt.Sprintf("%s-%s", prefix, safeName)
+	var previous map[string]int
+	if !reallocate {
+		previous = r.previousPorts(worktreePath, safeName)
+	}
+	detected, skipped := r.detectPorts()
+	env := r.envPorts(worktreePath)
+	detected = applyEnvDefaults(detected, env)
+	skipped = applyEnvDefaults(skipped, env)
+	ports := assignPorts(detected, offset, previous)
 
 	// Create .env.local with isolated configuration
 	if err := r.createEnvLocal(worktreePath, branch, projectName, offset, ports); err != nil {
@@ -158,10 +247,19 @@ func (r *Repo) CreateWorktree(branch string) error {
 	}
 
 	// Create the dev helper script
-	if err := r.createDevScript(worktreePath, projectName, offset, ports); err != nil {
+	if err := r.createDevScript(worktreePath, projectName, ports, r.Config.Profiles); err != nil {
 		return fmt.Errorf("failed to create dev script: %w", err)
 	}
 
+	if r.Quiet {
+		fmt.Println("WORKTREE_PATH:" + worktreePath)
+		fmt.Println("COMPOSE_PROJECT_NAME=" + projectName)
+		for _, p := range ports {
+			fmt.Printf("%s=%d\n", p.VarName, p.Port)
+		}
+		return nil
+	}
+
 	// Print success
 	fmt.Println()
 	fmt.Println(successStyle.Render("========================================"))
@@ -175,12 +273,34 @@ func (r *Repo) CreateWorktree(branch string) error {
 
 	if len(ports) > 0 {
 		fmt.Println(warnStyle.Render(fmt.Sprintf("Ports allocated (offset +%d):", offset)))
+		shared := 0
 		for _, p := range ports {
-			fmt.Printf("  %s: %d\n", p.VarName, p.Default+offset)
+			switch {
+			case p.Shared:
+				fmt.Printf("  %s: %d %s\n", p.VarName, p.Port, dimStyle.Render("(shared, not offset)"))
+				shared++
+			case p.Reused:
+				fmt.Printf("  %s: %d %s\n", p.VarName, p.Port, dimStyle.Render("(kept from previous worktree)"))
+			default:
+				fmt.Printf("  %s: %d\n", p.VarName, p.Port)
+			}
+		}
+		if shared > 0 {
+			fmt.Println(warnStyle.Render("Shared ports conflict if several worktrees run them at once"))
 		}
 		fmt.Println()
 	}
 
+	if len(skipped) > 0 {
+		printSkippedPorts(skipped)
+		fmt.Println()
+	}
+
+	if len(r.Config.Profiles) > 0 {
+		fmt.Println(infoStyle.Render("Compose profiles:"), strings.Join(r.Config.Profiles, ", "))
+		fmt.Println()
+	}
+
 	fmt.Println(warnStyle.Render("Commands:"))
 	fmt.Println("  ./dev up              # Start services")
 	fmt.Println("  ./dev logs            # View logs")
@@ -201,22 +321,35 @@ func (r *Repo) ListWorktrees() error {
 		return err
 	}
 
+	orphans, dangling := r.findStale(worktrees)
+	isDangling := make(map[string]bool)
+	for _, wt := range dangling {
+		isDangling[wt.Path] = true
+	}
+
 	found := false
 	for _, wt := range worktrees {
+		if isDangling[wt.Path] {
+			continue // Listed with the stale entries below
+		}
 		if strings.Contains(wt.Path, ".worktrees") {
 			found = true
-			safeName := filepath.Base(wt.Path)
-			prefix := getProjectPrefix(r.Name)
-			project := fmt.Sprintf("%s-%s", prefix, safeName)
+			project := r.projectName(wt.Path)
+
+			// Prefer the branch recorded at creation; git shows nothing useful for detached worktrees
+			branch := readEnvLocalBranch(wt.Path)
+			if branch == "" {
+				branch = wt.Branch
+			}
 
 			running := r.countRunningContainers(project)
 
 			if running > 0 {
-				fmt.Printf("  %s %s\n", successStyle.Render("●"), wt.Branch)
+				fmt.Printf("  %s %s\n", successStyle.Render("●"), branch)
 				fmt.Printf("    Path: %s\n", wt.Path)
 				fmt.Printf("    Project: %s (%d containers running)\n", project, running)
 			} else {
-				fmt.Printf("  %s %s\n", warnStyle.Render("○"), wt.Branch)
+				fmt.Printf("  %s %s\n", warnStyle.Render("○"), branch)
 				fmt.Printf("    Path: %s\n", wt.Path)
 				fmt.Printf("    Project: %s (stopped)\n", project)
 			}
@@ -230,19 +363,123 @@ func (r *Repo) ListWorktrees() error {
 
 	if !found {
 		fmt.Println(warnStyle.Render("  No worktrees created yet."))
-		fmt.Println("  Run: worktree-dev create <branch-name>")
+		fmt.Println("  Run: dtools worktree create <branch-name>")
+		fmt.Println()
+	}
+
+	if len(orphans) > 0 || len(dangling) > 0 {
+		fmt.Println(warnStyle.Render("Stale worktrees:"))
+		for _, path := range orphans {
+			fmt.Printf("  %s %s %s\n", errorStyle.Render("✗"), filepath.Base(path), dimStyle.Render("(directory not tracked by git)"))
+			fmt.Printf("    Path: %s\n", path)
+		}
+		for _, wt := range dangling {
+			fmt.Printf("  %s %s %s\n", errorStyle.Render("✗"), wt.Branch, dimStyle.Render("(directory missing)"))
+			fmt.Printf("    Path: %s\n", wt.Path)
+		}
+		fmt.Println()
+		fmt.Println("  Run: dtools worktree prune")
+		fmt.Println()
+	}
+
+	return nil
+}
+
+// PruneWorktrees cleans up stale worktrees: git references whose directory is
+// gone are pruned, and directories in .worktrees that git no longer tracks have
+// their Docker services stopped and are deleted. If confirm is set, it's asked
+// before anything is removed.
+func (r *Repo) PruneWorktrees(dryRun bool, confirm func(title string) (bool, error)) error {
+	worktrees, err := r.getWorktrees()
+	if err != nil {
+		return err
+	}
+
+	orphans, dangling := r.findStale(worktrees)
+	if len(orphans) == 0 && len(dangling) == 0 {
+		fmt.Println(successStyle.Render("No stale worktrees found."))
+		return nil
+	}
+
+	for _, wt := range dangling {
+		fmt.Println(warnStyle.Render("Pruning reference:"), wt.Branch, dimStyle.Render(wt.Path))
+	}
+	for _, path := range orphans {
+		fmt.Println(warnStyle.Render("Removing orphaned directory:"), path)
+	}
+
+	if dryRun {
 		fmt.Println()
+		fmt.Println(infoStyle.Render("Dry run, nothing removed."))
+		return nil
+	}
+
+	if confirm != nil {
+		confirmed, err := confirm(fmt.Sprintf("Prune %d stale worktree(s)?", len(orphans)+len(dangling)))
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	if len(dangling) > 0 {
+		if err := r.git("worktree", "prune"); err != nil {
+			return fmt.Errorf("failed to prune worktree references: %w", err)
+		}
 	}
 
+	for _, path := range orphans {
+		// Stop the orphan's services first, or its containers and volumes outlive it
+		project := r.projectName(path)
+		r.dockerComposeDown(path, project)
+		if err := r.removeContainers(project); err != nil {
+			fmt.Println(warnStyle.Render("Warning:"), err)
+		}
+
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+
+	fmt.Println(successStyle.Render(fmt.Sprintf("Pruned %d stale worktree(s).", len(orphans)+len(dangling))))
 	return nil
 }
 
+// findStale cross-checks git's worktrees against the .worktrees directory.
+// Orphans are directories git doesn't know about; dangling entries are git
+// worktrees whose directory no longer exists.
+func (r *Repo) findStale(worktrees []WorktreeInfo) (orphans []string, dangling []WorktreeInfo) {
+	tracked := make(map[string]bool)
+	for _, wt := range worktrees {
+		tracked[wt.Path] = true
+		if _, err := os.Stat(wt.Path); os.IsNotExist(err) {
+			dangling = append(dangling, wt)
+		}
+	}
+
+	entries, _ := os.ReadDir(r.WorktreesDir)
+	for _, entry := range entries {
+		// Dot directories hold dtools metadata, not worktrees
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(r.WorktreesDir, entry.Name())
+		if !tracked[path] {
+			orphans = append(orphans, path)
+		}
+	}
+
+	return orphans, dangling
+}
+
 // RemoveWorktree removes a worktree and cleans up Docker resources
 func (r *Repo) RemoveWorktree(branch string) error {
-	safeName := sanitizeName(branch)
+	safeName := r.resolveWorktreeName(branch)
 	worktreePath := filepath.Join(r.WorktreesDir, safeName)
-	prefix := getProjectPrefix(r.Name)
-	project := fmt.Sprintf("%s-%s", prefix, safeName)
+	project := r.projectName(worktreePath)
 
 	if _, err := os.Stat(worktreePath); os.IsNotExist(err) {
 		return fmt.Errorf("worktree not found at %s", worktreePath)
@@ -259,12 +496,19 @@ func (r *Repo) RemoveWorktree(branch string) error {
 
 	fmt.Println(warnStyle.Render("Removing worktree:"), branch)
 
+	// Keep the port allocation so recreating the worktree reuses it
+	if err := r.savePorts(worktreePath, safeName); err != nil {
+		fmt.Println(warnStyle.Render("Warning: could not save port allocation:"), err)
+	}
+
 	// Stop and remove Docker containers and volumes
 	fmt.Println(infoStyle.Render("Stopping Docker containers and removing volumes..."))
 	r.dockerComposeDown(worktreePath, project)
 
 	// Remove any remaining containers
-	r.removeContainers(project)
+	if err := r.removeContainers(project); err != nil {
+		fmt.Println(warnStyle.Render("Warning:"), err)
+	}
 
 	// Remove worktree
 	fmt.Println(infoStyle.Render("Removing git worktree..."))
@@ -282,33 +526,376 @@ func (r *Repo) RemoveWorktree(branch string) error {
 	return nil
 }
 
+// Up starts a worktree's services with its dev script. With wait it blocks until
+// every running service's ports accept connections and its healthcheck passes,
+// or timeout elapses.
+func (r *Repo) Up(branch string, wait bool, timeout time.Duration) error {
+	worktreePath, err := r.WorktreePath(branch)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"up"}
+	if wait {
+		args = append(args, "--wait")
+	}
+
+	cmd := exec.Command(filepath.Join(worktreePath, "dev"), args...)
+	cmd.Dir = worktreePath
+	cmd.Env = []string{fmt.Sprintf("WAIT_TIMEOUT=%d", int(timeout.Seconds()))}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(context.Background()); err != nil {
+		if wait {
+			return fmt.Errorf("services for '%s' didn't start or weren't ready within %s", branch, timeout)
+		}
+		return fmt.Errorf("failed to start services for '%s': %w", branch, err)
+	}
+	return nil
+}
+
+// Logs follows the logs of a worktree's services, or of every service if none are
+// given, with the environment its ./dev script would load
+func (r *Repo) Logs(branch string, services []string) error {
+	worktreePath, err := r.WorktreePath(branch)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("docker-compose", append([]string{"logs", "-f"}, services...)...)
+	cmd.Dir = worktreePath
+	cmd.Env = append(readEnvLocal(worktreePath), "COMPOSE_PROJECT_NAME="+r.projectName(worktreePath))
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(context.Background()); err != nil {
+		return fmt.Errorf("failed to follow logs for '%s': %w", branch, err)
+	}
+	return nil
+}
+
+// WorktreePath returns the path of the worktree checked out for a branch.
+// It returns an error if git has no worktree for the branch or its directory is missing.
+func (r *Repo) WorktreePath(branch string) (string, error) {
+	worktrees, err := r.getWorktrees()
+	if err != nil {
+		return "", fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	for _, wt := range worktrees {
+		if wt.Branch != branch || !strings.Contains(wt.Path, ".worktrees") {
+			continue
+		}
+		if _, err := os.Stat(wt.Path); err != nil {
+			return "", fmt.Errorf("worktree for '%s' is registered at %s but the directory is missing", branch, wt.Path)
+		}
+		return wt.Path, nil
+	}
+
+	return "", fmt.Errorf("no worktree for branch '%s'\nRun: dtools worktree create %s", branch, branch)
+}
+
 // ShowPorts shows the ports that would be allocated for a branch
 func (r *Repo) ShowPorts(branch string) error {
-	safeName := sanitizeName(branch)
+	safeName := r.resolveWorktreeName(branch)
 	offset := getPortOffset(safeName)
 
 	fmt.Println(infoStyle.Render("Ports for branch:"), warnStyle.Render(branch), fmt.Sprintf("(offset +%d)", offset))
 	fmt.Println()
 
-	ports := r.detectPorts()
-	if len(ports) == 0 {
+	detected, skipped := r.detectPorts()
+	if len(detected) == 0 && len(skipped) == 0 {
 		fmt.Println(warnStyle.Render("No docker-compose.yml found"))
 		return nil
 	}
 
-	for _, p := range ports {
-		fmt.Printf("  %s: %d\n", p.VarName, p.Default+offset)
+	worktreePath := filepath.Join(r.WorktreesDir, safeName)
+	project := r.projectName(worktreePath)
+	env := r.envPorts(worktreePath)
+	detected = applyEnvDefaults(detected, env)
+	skipped = applyEnvDefaults(skipped, env)
+
+	var owners map[int]string // Looked up only if a port turns out to be in use
+	conflicts := 0
+	for _, p := range assignPorts(detected, offset, r.previousPorts(worktreePath, safeName)) {
+		line := fmt.Sprintf("  %s: %d", p.VarName, p.Port)
+		switch {
+		case p.Shared:
+			line += " " + dimStyle.Render("(shared, not offset)")
+		case p.Reused:
+			line += " " + dimStyle.Render("(already allocated)")
+		}
+
+		switch checkPort(p.Port) {
+		case PortFree:
+			line += " " + successStyle.Render("free")
+		case PortInUse:
+			if owners == nil {
+				owners = publishedPorts()
+			}
+			owner := owners[p.Port]
+			switch {
+			case p.Shared:
+				// Expected when another worktree runs the shared service
+				line += " " + warnStyle.Render("in use (shared)")
+			case owner != "" && strings.HasPrefix(owner, project):
+				line += " " + successStyle.Render("in use by this worktree ("+owner+")")
+			case owner != "":
+				line += " " + errorStyle.Render("in use by "+owner)
+				conflicts++
+			default:
+				line += " " + errorStyle.Render("in use")
+				conflicts++
+			}
+		}
+		fmt.Println(line)
+	}
+
+	if conflicts > 0 {
+		fmt.Println()
+		fmt.Println(warnStyle.Render(fmt.Sprintf("%d port(s) are taken by something else; './dev up' will fail to bind them", conflicts)))
+	}
+
+	if len(skipped) > 0 {
+		fmt.Println()
+		printSkippedPorts(skipped)
 	}
 
 	return nil
 }
 
+// PortsReport is the port allocation of a branch's worktree, for tools such as
+// editors that need to find its services
+type PortsReport struct {
+	Branch  string     `json:"branch"`
+	Project string     `json:"project"`
+	Path    string     `json:"path"`
+	Exists  bool       `json:"exists"` // Whether the worktree has been created
+	Offset  int        `json:"offset"`
+	Ports   []PortInfo `json:"ports"`
+}
+
+// PortInfo is one port variable's resolved host port
+type PortInfo struct {
+	VarName  string   `json:"var"`
+	Services []string `json:"services"`
+	Port     int      `json:"port"`
+	Default  int      `json:"default"`
+	Shared   bool     `json:"shared"`
+	Reused   bool     `json:"reused"`
+	Status   string   `json:"status"`          // free, in_use or unknown
+	Owner    string   `json:"owner,omitempty"` // Container publishing the port, if known
+	URL      string   `json:"url"`             // A guess: not every service speaks HTTP
+}
+
+// Ports returns the ports allocated, or that would be allocated, for a branch
+// along with whether each is currently bound. Ports skipped because their
+// service's profile isn't enabled aren't included.
+func (r *Repo) Ports(branch string) *PortsReport {
+	safeName := r.resolveWorktreeName(branch)
+	worktreePath := filepath.Join(r.WorktreesDir, safeName)
+	_, statErr := os.Stat(worktreePath)
+
+	report := &PortsReport{
+		Branch:  branch,
+		Project: r.projectName(worktreePath),
+		Path:    worktreePath,
+		Exists:  statErr == nil,
+		Offset:  getPortOffset(safeName),
+		Ports:   []PortInfo{},
+	}
+
+	detected, _ := r.detectPorts()
+	detected = applyEnvDefaults(detected, r.envPorts(worktreePath))
+
+	defaults := make(map[string]int)
+	for _, p := range detected {
+		defaults[p.VarName] = p.Default
+	}
+	services := make(map[string][]string)
+	for _, sp := range r.servicePorts() {
+		services[sp.VarName] = append(services[sp.VarName], sp.Service)
+	}
+
+	var owners map[int]string
+	for _, p := range assignPorts(detected, report.Offset, r.previousPorts(worktreePath, safeName)) {
+		info := PortInfo{
+			VarName:  p.VarName,
+			Services: services[p.VarName],
+			Port:     p.Port,
+			Default:  defaults[p.VarName],
+			Shared:   p.Shared,
+			Reused:   p.Reused,
+			Status:   "unknown",
+			URL:      fmt.Sprintf("http://localhost:%d", p.Port),
+		}
+		if info.Services == nil {
+			info.Services = []string{}
+		}
+		switch checkPort(p.Port) {
+		case PortFree:
+			info.Status = "free"
+		case PortInUse:
+			info.Status = "in_use"
+			if owners == nil {
+				owners = publishedPorts()
+			}
+			info.Owner = owners[p.Port]
+		}
+		report.Ports = append(report.Ports, info)
+	}
+
+	return report
+}
+
+// printSkippedPorts lists port variables that weren't allocated because only
+// services behind unconfigured Compose profiles use them
+func printSkippedPorts(skipped []PortVar) {
+	fmt.Println(dimStyle.Render("Not offset (only used by services behind other profiles):"))
+	for _, p := range skipped {
+		fmt.Println(dimStyle.Render(fmt.Sprintf("  %s: %d", p.VarName, p.Default)))
+	}
+}
+
+// portsFile returns where a removed worktree's port allocation is kept
+func (r *Repo) portsFile(safeName string) string {
+	return filepath.Join(r.WorktreesDir, ".ports", safeName+".env")
+}
+
+// previousPorts returns ports already allocated to a worktree, from its
+// .env.local or, if the worktree was removed, from the saved allocation
+func (r *Repo) previousPorts(worktreePath, safeName string) map[string]int {
+	if ports := readEnvPorts(filepath.Join(worktreePath, ".env.local")); len(ports) > 0 {
+		return ports
+	}
+	return readEnvPorts(r.portsFile(safeName))
+}
+
+// envPorts returns the port values set in a worktree's .env, or in the .env
+// (or .env.example) the main repo would copy into it if it doesn't exist yet
+func (r *Repo) envPorts(worktreePath string) map[string]int {
+	for _, path := range []string{
+		filepath.Join(worktreePath, ".env"),
+		filepath.Join(r.Root, ".env"),
+		filepath.Join(r.Root, ".env.example"),
+	} {
+		if _, err := os.Stat(path); err == nil {
+			return readEnvPorts(path)
+		}
+	}
+	return nil
+}
+
+// savePorts keeps a copy of a worktree's .env.local for a later recreate
+func (r *Repo) savePorts(worktreePath, safeName string) error {
+	envLocal := filepath.Join(worktreePath, ".env.local")
+	if _, err := os.Stat(envLocal); os.IsNotExist(err) {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.portsFile(safeName)), 0755); err != nil {
+		return err
+	}
+	return copyFile(envLocal, r.portsFile(safeName))
+}
+
+// resolveWorktreeName returns the directory name used for a branch's worktree.
+// An existing worktree for the branch keeps its name; otherwise the sanitized
+// branch name is used, disambiguated if another branch already owns it
+// (e.g. feature/x and feature-x).
+func (r *Repo) resolveWorktreeName(branch string) string {
+	entries, _ := os.ReadDir(r.WorktreesDir)
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") && r.worktreeBranch(filepath.Join(r.WorktreesDir, entry.Name())) == branch {
+			return entry.Name()
+		}
+	}
+
+	safeName := sanitizeName(branch)
+	if _, err := os.Stat(filepath.Join(r.WorktreesDir, safeName)); err == nil {
+		return disambiguateName(safeName, branch)
+	}
+	return safeName
+}
+
+// worktreeBranch returns the branch a worktree directory belongs to,
+// from its .env.local metadata or, failing that, from git
+func (r *Repo) worktreeBranch(worktreePath string) string {
+	if branch := readEnvLocalBranch(worktreePath); branch != "" {
+		return branch
+	}
+
+	worktrees, err := r.getWorktrees()
+	if err != nil {
+		return ""
+	}
+	for _, wt := range worktrees {
+		if wt.Path == worktreePath {
+			return wt.Branch
+		}
+	}
+	return ""
+}
+
+// projectName returns a worktree's Compose project name: the one recorded in
+// its .env.local, so worktrees created under older naming rules still match,
+// or else the name CreateWorktree would give it
+func (r *Repo) projectName(worktreePath string) string {
+	if project := readEnvLocalProject(worktreePath); project != "" {
+		return project
+	}
+	return composeProjectName(r.Name, filepath.Base(worktreePath))
+}
+
+// readEnvLocal returns the KEY=value assignments in a worktree's .env.local
+func readEnvLocal(worktreePath string) []string {
+	content, err := os.ReadFile(filepath.Join(worktreePath, ".env.local"))
+	if err != nil {
+		return nil
+	}
+	var env []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") && strings.Contains(line, "=") {
+			env = append(env, line)
+		}
+	}
+	return env
+}
+
+// readEnvLocalProject reads COMPOSE_PROJECT_NAME from a worktree's .env.local
+func readEnvLocalProject(worktreePath string) string {
+	content, err := os.ReadFile(filepath.Join(worktreePath, ".env.local"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if value, ok := strings.CutPrefix(line, "COMPOSE_PROJECT_NAME="); ok {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// readEnvLocalBranch reads the branch recorded in a worktree's .env.local
+func readEnvLocalBranch(worktreePath string) string {
+	content, err := os.ReadFile(filepath.Join(worktreePath, ".env.local"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(line, envLocalBranchPrefix) {
+			return strings.TrimSpace(strings.TrimPrefix(line, envLocalBranchPrefix))
+		}
+	}
+	return ""
+}
+
 // GetBranches returns all available branches (local and remote)
 func (r *Repo) GetBranches() (local []string, remote []string, err error) {
 	currentBranch, _ := r.currentBranch()
 
 	// Get local branches
-	out, err := exec.Command("git", "-C", r.Root, "branch", "--format=%(refname:short)").Output()
+	out, err := exec.Output(context.Background(), "git", "-C", r.Root, "branch", "--format=%(refname:short)")
 	if err != nil {
 		return nil, nil, err
 	}
@@ -319,7 +906,7 @@ func (r *Repo) GetBranches() (local []string, remote []string, err error) {
 	}
 
 	// Get remote branches
-	out, err = exec.Command("git", "-C", r.Root, "branch", "-r", "--format=%(refname:short)").Output()
+	out, err = exec.Output(context.Background(), "git", "-C", r.Root, "branch", "-r", "--format=%(refname:short)")
 	if err == nil {
 		localMap := make(map[string]bool)
 		for _, b := range local {
@@ -343,12 +930,31 @@ func (r *Repo) GetBranches() (local []string, remote []string, err error) {
 func (r *Repo) git(args ...string) error {
 	cmd := exec.Command("git", append([]string{"-C", r.Root}, args...)...)
 	cmd.Stdout = os.Stdout
+	if r.Quiet {
+		cmd.Stdout = io.Discard
+	}
 	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	return cmd.Run(context.Background())
+}
+
+// say prints progress output, unless Quiet is set
+func (r *Repo) say(a ...any) {
+	if !r.Quiet {
+		fmt.Println(a...)
+	}
+}
+
+// warn prints a warning, to stderr if Quiet is set so stdout stays parseable
+func (r *Repo) warn(a ...any) {
+	if r.Quiet {
+		fmt.Fprintln(os.Stderr, a...)
+		return
+	}
+	fmt.Println(a...)
 }
 
 func (r *Repo) currentBranch() (string, error) {
-	out, err := exec.Command("git", "-C", r.Root, "branch", "--show-current").Output()
+	out, err := exec.Output(context.Background(), "git", "-C", r.Root, "branch", "--show-current")
 	if err != nil {
 		return "", err
 	}
@@ -356,15 +962,29 @@ func (r *Repo) currentBranch() (string, error) {
 }
 
 func (r *Repo) branchExists(branch string) bool {
-	err := exec.Command("git", "-C", r.Root, "show-ref", "--verify", "--quiet", "refs/heads/"+branch).Run()
+	err := exec.Run(context.Background(), "git", "-C", r.Root, "show-ref", "--verify", "--quiet", "refs/heads/"+branch)
+	return err == nil
+}
+
+// refExists returns true if ref names a commit, such as a branch, tag or SHA
+func (r *Repo) refExists(ref string) bool {
+	err := exec.Run(context.Background(), "git", "-C", r.Root, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
 	return err == nil
 }
 
 func (r *Repo) remoteBranchExists(branch string) bool {
-	err := exec.Command("git", "-C", r.Root, "show-ref", "--verify", "--quiet", "refs/remotes/origin/"+branch).Run()
+	err := exec.Run(context.Background(), "git", "-C", r.Root, "show-ref", "--verify", "--quiet", "refs/remotes/origin/"+branch)
 	return err == nil
 }
 
+// gitignoreEntries are ignored at the repository root: the worktrees directory, and
+// defensively the files dtools generates in each worktree, in case they end up
+// outside it
+var gitignoreEntries = []string{".worktrees", "/.env.local", "/dev"}
+
+// ensureGitignore adds whichever gitignoreEntries .gitignore is missing. Entries
+// already present in an equivalent form (/.worktrees, .worktrees/, ...) are left
+// alone, as are entries that would hide files the repository tracks.
 func (r *Repo) ensureGitignore() error {
 	gitignorePath := filepath.Join(r.Root, ".gitignore")
 	content, err := os.ReadFile(gitignorePath)
@@ -372,7 +992,19 @@ func (r *Repo) ensureGitignore() error {
 		return err
 	}
 
-	if strings.Contains(string(content), ".worktrees") {
+	present := make(map[string]bool)
+	for _, line := range strings.Split(string(content), "\n") {
+		present[gitignorePattern(line)] = true
+	}
+
+	var missing []string
+	for _, entry := range gitignoreEntries {
+		if present[gitignorePattern(entry)] || r.tracksPath(strings.TrimPrefix(entry, "/")) {
+			continue
+		}
+		missing = append(missing, entry)
+	}
+	if len(missing) == 0 {
 		return nil
 	}
 
@@ -382,50 +1014,116 @@ func (r *Repo) ensureGitignore() error {
 	}
 	defer f.Close()
 
-	fmt.Println(infoStyle.Render("Adding .worktrees to .gitignore..."))
-	_, err = f.WriteString("\n# Git worktrees with isolated Docker environments\n.worktrees\n")
+	var b strings.Builder
+	if len(content) > 0 && !strings.HasSuffix(string(content), "\n") {
+		b.WriteString("\n")
+	}
+	if header := "# Git worktrees with isolated Docker environments"; !present[header] {
+		b.WriteString("\n" + header + "\n")
+	}
+	for _, entry := range missing {
+		b.WriteString(entry + "\n")
+	}
+
+	r.say(infoStyle.Render("Adding " + strings.Join(missing, ", ") + " to .gitignore..."))
+	_, err = f.WriteString(b.String())
 	return err
 }
 
+// gitignorePattern normalizes a .gitignore line for comparison, so ".worktrees",
+// "/.worktrees" and ".worktrees/" match
+func gitignorePattern(line string) string {
+	return strings.Trim(strings.TrimSpace(line), "/")
+}
+
+// tracksPath returns true if git tracks path or anything under it
+func (r *Repo) tracksPath(path string) bool {
+	out, err := exec.Output(context.Background(), "git", "-C", r.Root, "ls-files", "--", path)
+	return err == nil && len(strings.TrimSpace(string(out))) > 0
+}
+
+// envSource returns the env file copyEnvFiles copies into new worktrees, or "" if there's none
+func (r *Repo) envSource() string {
+	for _, name := range []string{".env", ".env.example"} {
+		path := filepath.Join(r.Root, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
 func (r *Repo) copyEnvFiles(worktreePath string) {
 	// Copy .env if exists
 	envPath := filepath.Join(r.Root, ".env")
 	if _, err := os.Stat(envPath); err == nil {
-		fmt.Println(infoStyle.Render("Copying .env..."))
+		r.say(infoStyle.Render("Copying .env..."))
 		copyFile(envPath, filepath.Join(worktreePath, ".env"))
 	} else {
 		// Try .env.example
 		examplePath := filepath.Join(r.Root, ".env.example")
 		if _, err := os.Stat(examplePath); err == nil {
-			fmt.Println(warnStyle.Render("No .env found, copying .env.example..."))
+			r.say(warnStyle.Render("No .env found, copying .env.example..."))
 			copyFile(examplePath, filepath.Join(worktreePath, ".env"))
 		}
 	}
 }
 
-func (r *Repo) createEnvLocal(worktreePath, branch, projectName string, offset int, ports []PortVar) error {
-	fmt.Println(infoStyle.Render("Creating .env.local with isolated configuration..."))
+func (r *Repo) createEnvLocal(worktreePath, branch, projectName string, offset int, ports []PortAssignment) error {
+	r.say(infoStyle.Render("Creating .env.local with isolated configuration..."))
 
 	var b strings.Builder
-	b.WriteString("# Auto-generated by worktree-dev\n")
+	b.WriteString("# Auto-generated by dtools worktree\n")
 	b.WriteString(fmt.Sprintf("# Repository: %s\n", r.Name))
-	b.WriteString(fmt.Sprintf("# Worktree: %s\n", branch))
+	b.WriteString(envLocalBranchPrefix + branch + "\n")
 	b.WriteString(fmt.Sprintf("# Created: %s\n\n", time.Now().Format(time.RFC3339)))
 	b.WriteString("# Docker Compose project name (isolates containers, networks, and volumes)\n")
 	b.WriteString(fmt.Sprintf("COMPOSE_PROJECT_NAME=%s\n\n", projectName))
 	b.WriteString(fmt.Sprintf("# Port mappings (offset by %d from defaults)\n", offset))
 
 	for _, p := range ports {
-		b.WriteString(fmt.Sprintf("%s=%d\n", p.VarName, p.Default+offset))
+		if p.Shared {
+			b.WriteString(fmt.Sprintf("# %s is shared between worktrees and not offset\n", p.VarName))
+			b.WriteString(fmt.Sprintf("%s=%d\n", p.VarName, p.Port))
+			continue
+		}
+		b.WriteString(fmt.Sprintf("%s=%d\n", p.VarName, p.Port))
 	}
 
 	return os.WriteFile(filepath.Join(worktreePath, ".env.local"), []byte(b.String()), 0644)
 }
 
-func (r *Repo) createDevScript(worktreePath, projectName string, offset int, ports []PortVar) error {
+func (r *Repo) createDevScript(worktreePath, projectName string, ports []PortAssignment, profiles []string) error {
+	// Default profiles are enabled on up; others can still be passed as ./dev --profile <name> up
+	var profileArgs, profilesDisplay string
+	for _, profile := range profiles {
+		profileArgs += fmt.Sprintf(" --profile %q", profile)
+	}
+	if len(profiles) > 0 {
+		profilesDisplay = fmt.Sprintf("    echo \"\"\n    echo \"Profiles enabled on up: %s\"\n", strings.Join(profiles, ", "))
+	}
+
 	var portsDisplay strings.Builder
 	for _, p := range ports {
-		portsDisplay.WriteString(fmt.Sprintf("    echo \"  %s: %d\"\n", p.VarName, p.Default+offset))
+		if p.Shared {
+			portsDisplay.WriteString(fmt.Sprintf("    echo \"  %s: %d (shared with other worktrees)\"\n", p.VarName, p.Port))
+			continue
+		}
+		portsDisplay.WriteString(fmt.Sprintf("    echo \"  %s: %d\"\n", p.VarName, p.Port))
+	}
+
+	// Host ports per service for up --wait; unallocated variables keep their default
+	allocated := make(map[string]int)
+	for _, p := range ports {
+		allocated[p.VarName] = p.Port
+	}
+	var servicePorts []string
+	for _, sp := range r.servicePorts() {
+		port, ok := allocated[sp.VarName]
+		if !ok {
+			port = sp.Default
+		}
+		servicePorts = append(servicePorts, fmt.Sprintf("%s:%d", sp.Service, port))
 	}
 
 	script := fmt.Sprintf(`#!/bin/bash
@@ -442,12 +1140,62 @@ if [ -f "$SCRIPT_DIR/.env.local" ]; then
     set +a
 fi
 
+# Host ports published by each service, as service:port
+SERVICE_PORTS="%s"
+
+# Wait until each running service's ports accept connections and its
+# healthcheck (if any) passes, or WAIT_TIMEOUT seconds elapse
+wait_for_services() {
+    local timeout="${WAIT_TIMEOUT:-120}"
+    local deadline=$((SECONDS + timeout))
+    local failed=0
+
+    echo ""
+    echo "Waiting for services (timeout ${timeout}s)..."
+    for service in $(docker-compose ps --services --filter status=running 2>/dev/null); do
+        local ports="" waiting="" entry name port id health
+        for entry in $SERVICE_PORTS; do
+            IFS=: read -r name port <<< "$entry"
+            if [ "$name" = "$service" ]; then
+                ports="$ports $port"
+            fi
+        done
+
+        while true; do
+            waiting=""
+            for port in $ports; do
+                if ! (exec 3<>"/dev/tcp/127.0.0.1/$port") 2>/dev/null; then
+                    waiting="$waiting port $port not listening;"
+                fi
+            done
+            id=$(docker-compose ps -q "$service" 2>/dev/null | head -n 1)
+            health=$(docker inspect --format '{{if .State.Health}}{{.State.Health.Status}}{{end}}' "$id" 2>/dev/null || true)
+            if [ -n "$health" ] && [ "$health" != "healthy" ]; then
+                waiting="$waiting health $health;"
+            fi
+            if [ -z "$waiting" ] || [ "$SECONDS" -ge "$deadline" ]; then
+                break
+            fi
+            sleep 1
+        done
+
+        if [ -z "$waiting" ]; then
+            echo "  ✓ $service ready${ports:+ (port$ports)}"
+        else
+            echo "  ✗ $service not ready:${waiting%%;}"
+            failed=1
+        fi
+    done
+    return $failed
+}
+
 # Show help
 show_help() {
     echo "Worktree dev helper for: $COMPOSE_PROJECT_NAME"
     echo ""
     echo "Commands:"
     echo "  up [services...]     Start services (default: all)"
+    echo "  up --wait [...]      Start services and wait until they're ready"
     echo "  down                 Stop services"
     echo "  logs [service]       View logs (follows)"
     echo "  ps                   Show running containers"
@@ -458,18 +1206,26 @@ show_help() {
     echo "  <any>                Passed to docker-compose"
     echo ""
     echo "Ports:"
-%s}
+%s%s}
 
 CMD="${1:-help}"
 shift 2>/dev/null || true
 
 case "$CMD" in
     up)
+        WAIT=false
+        if [ "$1" = "--wait" ]; then
+            WAIT=true
+            shift
+        fi
         echo "Starting $COMPOSE_PROJECT_NAME..."
-        docker-compose up -d "$@"
+        docker-compose%s up -d "$@"
         echo ""
         echo "Services started. Ports:"
-%s        ;;
+%s        if [ "$WAIT" = true ]; then
+            wait_for_services
+        fi
+        ;;
     down)
         echo "Stopping $COMPOSE_PROJECT_NAME..."
         docker-compose down "$@"
@@ -499,7 +1255,7 @@ case "$CMD" in
         docker-compose "$CMD" "$@"
         ;;
 esac
-`, portsDisplay.String(), portsDisplay.String())
+`, strings.Join(servicePorts, " "), portsDisplay.String(), profilesDisplay, profileArgs, portsDisplay.String())
 
 	scriptPath := filepath.Join(worktreePath, "dev")
 	if err := os.WriteFile(scriptPath, []byte(script), 0755); err != nil {
@@ -514,56 +1270,68 @@ type WorktreeInfo struct {
 }
 
 func (r *Repo) getWorktrees() ([]WorktreeInfo, error) {
-	out, err := exec.Command("git", "-C", r.Root, "worktree", "list").Output()
+	// Porcelain output keeps paths with spaces and unusual branch names intact
+	out, err := exec.Output(context.Background(), "git", "-C", r.Root, "worktree", "list", "--porcelain")
 	if err != nil {
 		return nil, err
 	}
 
 	var worktrees []WorktreeInfo
 	for _, line := range strings.Split(string(out), "\n") {
-		if line == "" {
-			continue
-		}
-		parts := strings.Fields(line)
-		if len(parts) >= 3 {
-			branch := strings.Trim(parts[2], "[]")
+		switch {
+		case strings.HasPrefix(line, "worktree "):
 			worktrees = append(worktrees, WorktreeInfo{
-				Path:   parts[0],
-				Branch: branch,
+				Path: strings.TrimPrefix(line, "worktree "),
 			})
+		case strings.HasPrefix(line, "branch ") && len(worktrees) > 0:
+			worktrees[len(worktrees)-1].Branch = strings.TrimPrefix(strings.TrimPrefix(line, "branch "), "refs/heads/")
+		case line == "detached" && len(worktrees) > 0:
+			worktrees[len(worktrees)-1].Branch = "(detached HEAD)"
 		}
 	}
 	return worktrees, nil
 }
 
 func (r *Repo) countRunningContainers(project string) int {
-	out, _ := exec.Command("docker", "ps", "--filter", "name="+project, "--format", "{{.Names}}").Output()
-	if len(out) == 0 {
-		return 0
+	return len(projectContainers(project, false))
+}
+
+// projectContainers returns the names of the running containers that belong to
+// a Compose project, or with all set the stopped ones too. It matches the
+// project label exactly, unlike a name filter, so foo-bar's containers don't
+// count for foo. It's best-effort and returns nil if docker isn't available.
+func projectContainers(project string, all bool) []string {
+	args := []string{"ps", "--filter", "label=com.docker.compose.project=" + project, "--format", "{{.Names}}"}
+	if all {
+		args = append(args, "-a")
 	}
-	return len(strings.Split(strings.TrimSpace(string(out)), "\n"))
+	out, err := exec.Output(context.Background(), "docker", args...)
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(out))
 }
 
 func (r *Repo) dockerComposeDown(worktreePath, project string) {
 	cmd := exec.Command("docker-compose", "down", "-v")
 	cmd.Dir = worktreePath
-	cmd.Env = append(os.Environ(), "COMPOSE_PROJECT_NAME="+project)
-	cmd.Run()
+	cmd.Env = []string{"COMPOSE_PROJECT_NAME=" + project}
+	cmd.Run(context.Background())
 }
 
-func (r *Repo) removeContainers(project string) {
-	out, _ := exec.Command("docker", "ps", "-a", "--filter", "name="+project, "--format", "{{.ID}}").Output()
-	if len(out) > 0 {
-		for _, id := range strings.Split(strings.TrimSpace(string(out)), "\n") {
-			if id != "" {
-				exec.Command("docker", "rm", "-f", id).Run()
-			}
+// removeContainers force-removes every container of a Compose project,
+// returning the first failure
+func (r *Repo) removeContainers(project string) error {
+	for _, name := range projectContainers(project, true) {
+		if err := exec.Run(context.Background(), "docker", "rm", "-f", name); err != nil {
+			return fmt.Errorf("failed to remove container %s: %w", name, err)
 		}
 	}
+	return nil
 }
 
 func gitRoot() (string, error) {
-	out, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	out, err := exec.Output(context.Background(), "git", "rev-parse", "--show-toplevel")
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,220 @@
+// Package review runs dtools' CodeRabbit review loop from other Go programs,
+// without the TUI. Claude addresses a PR's review comments and CI failures the
+// way `dtools review` does, and the counts come back as a Result.
+//
+// Like `dtools review`, it runs Claude without permission prompts in the
+// current directory, which must be a checkout of the PR's branch.
+package review
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/DylanSharp/dtools/internal/aicmd"
+	"github.com/DylanSharp/dtools/internal/coderabbit/adapters"
+	"github.com/DylanSharp/dtools/internal/coderabbit/config"
+	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
+	"github.com/DylanSharp/dtools/internal/coderabbit/service"
+)
+
+// Options configures a review. DefaultOptions matches `dtools review`'s defaults.
+type Options struct {
+	PRNumber int // The PR to review; 0 uses the current branch's PR
+
+	IncludeNits      bool    // Address nitpick comments
+	IncludeOutdated  bool    // Address comments on outdated code
+	MarkAddressed    bool    // Resolve addressed comment threads on GitHub (not in watch mode, as with the CLI)
+	ReplyToDeclined  bool    // Reply to comments Claude declines with its rationale
+	ApplySuggestions bool    // Apply trivial committable suggestions with git apply before invoking Claude
+	WithDiff         bool    // Include the diff of commented files in the prompt
+	IncludeSummary   bool    // Include CodeRabbit's walkthrough as background
+	IncludePRContext bool    // Include the PR's title and description as background
+	MaxComments      int     // Address at most this many comments per Claude run (0 means no cap)
+	MaxPromptKb      float64 // Prompt budget in KB (0 means the default)
+	Since            string  // Only address comments created after this commit
+	ResetState       bool    // Forget which comments earlier runs processed
+
+	AICommand    string        // Command line to run instead of claude, as --ai-command takes
+	Timeout      time.Duration // Maximum time for a single Claude run (0 disables)
+	ReviewerBots []string      // Bot logins whose comments are addressed (default: from the config file, else CodeRabbit)
+
+	// Watch keeps going after Claude's run, like `dtools review --watch`: it waits
+	// for CodeRabbit to re-review each push and addresses what it finds, until
+	// CodeRabbit is satisfied, the PR is closed or MaxIterations runs have
+	// happened. Without Watch, Run returns after one pass over the current
+	// comments and CI failures, in as many batches as MaxComments takes.
+	Watch         bool
+	MaxIterations int           // Watch: stop after this many Claude runs (0 means no limit; bound ctx instead)
+	PollInterval  time.Duration // Watch: how often to check the PR (0 means the default)
+	Cooldown      time.Duration // Watch: how long to wait after a run for CodeRabbit to re-review (0 means the default)
+	BatchWait     time.Duration // Watch: how long to let comments arrive before a run (0 means the default)
+}
+
+// DefaultOptions returns the options `dtools review --watch=false` uses
+func DefaultOptions() Options {
+	return Options{
+		IncludeNits:     true,
+		IncludeOutdated: true,
+		MarkAddressed:   true,
+		ReplyToDeclined: true,
+		Timeout:         aicmd.DefaultTimeout,
+	}
+}
+
+// Result summarizes a review
+type Result struct {
+	PRNumber   int
+	Iterations int // Claude runs, counting each batch
+
+	CommentsProcessed  int // Comments sent to Claude or applied as suggestions
+	SuggestionsApplied int // Comments whose committable suggestions were applied locally
+	Resolved           int // Comment threads resolved on GitHub
+	Deferred           int // Comments left for a later run by MaxComments or the prompt budget
+	CIFailuresFixed    int // CI failures Claude was asked to fix
+
+	// Satisfied is true if there's nothing left to address and CodeRabbit
+	// appears to be done with the PR
+	Satisfied      bool
+	Reasons        []string // Why the PR was judged satisfied or not
+	ActionRequired []string // What still seems to need doing, if not satisfied
+	StopReason     string   // Watch: why the review stopped
+}
+
+// Run reviews a PR with Claude and returns what was done. If Claude fails or
+// times out, the partial result is returned along with the error.
+func Run(ctx context.Context, opts Options) (*Result, error) {
+	svc, err := newService(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return run(ctx, svc, opts)
+}
+
+// newService wires the review service to GitHub and Claude the way `dtools review` does
+func newService(ctx context.Context, opts Options) (*service.ReviewService, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	bots := domain.ReviewerBots(opts.ReviewerBots)
+	if len(bots) == 0 {
+		bots = cfg.ReviewerBots
+	}
+	github, ci := adapters.NewGitHubAdapters(bots)
+	if err := github.CheckAuth(ctx); err != nil {
+		return nil, err
+	}
+
+	command := aicmd.Default()
+	if opts.AICommand != "" {
+		if command, err = aicmd.Parse(opts.AICommand); err != nil {
+			return nil, fmt.Errorf("invalid AI command: %w", err)
+		}
+	}
+	claude := adapters.NewClaudeClientWithCommand(command)
+	claude.SetTimeout(opts.Timeout)
+	if !claude.IsAvailable() {
+		return nil, fmt.Errorf("AI command %q not found in PATH", command.Binary)
+	}
+
+	svc := service.NewReviewService(github, ci, claude)
+	if err := svc.ConfigurePrompt(ctx, cfg, ""); err != nil {
+		return nil, err
+	}
+	return svc, nil
+}
+
+// run reviews the PR with an already wired service
+func run(ctx context.Context, svc *service.ReviewService, opts Options) (*Result, error) {
+	prNumber := opts.PRNumber
+	if prNumber == 0 {
+		detected, err := svc.DetectCurrentPR(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not detect PR number: %w", err)
+		}
+		prNumber = detected
+	}
+
+	var (
+		result *service.ReviewResult
+		err    error
+	)
+	if opts.Watch {
+		result, err = svc.WatchToCompletion(ctx, prNumber, watchOptions(opts))
+	} else {
+		result, err = svc.RunReviewToCompletion(ctx, reviewConfig(prNumber, opts))
+	}
+	if result == nil {
+		return nil, err
+	}
+	return newResult(prNumber, result), err
+}
+
+// reviewConfig translates opts for a single review pass
+func reviewConfig(prNumber int, opts Options) service.ReviewConfig {
+	return service.ReviewConfig{
+		PRNumber:         prNumber,
+		IncludeNits:      opts.IncludeNits,
+		IncludeOutdated:  opts.IncludeOutdated,
+		MaxPromptKb:      opts.MaxPromptKb,
+		WithDiff:         opts.WithDiff,
+		IncludeSummary:   opts.IncludeSummary,
+		IncludePRContext: opts.IncludePRContext,
+		ReplyToDeclined:  opts.ReplyToDeclined,
+		ResetState:       opts.ResetState,
+		MarkAddressed:    opts.MarkAddressed,
+		Since:            opts.Since,
+		ApplySuggestions: opts.ApplySuggestions,
+		MaxComments:      opts.MaxComments,
+	}
+}
+
+// watchOptions translates opts for watch mode, keeping its defaults where opts has none
+func watchOptions(opts Options) service.WatchOptions {
+	watch := service.DefaultWatchOptions()
+	watch.IncludeNits = opts.IncludeNits
+	watch.IncludeOutdated = opts.IncludeOutdated
+	watch.MaxPromptKb = opts.MaxPromptKb
+	watch.WithDiff = opts.WithDiff
+	watch.IncludeSummary = opts.IncludeSummary
+	watch.IncludePRContext = opts.IncludePRContext
+	watch.ReplyToDeclined = opts.ReplyToDeclined
+	watch.ResetState = opts.ResetState
+	watch.Since = opts.Since
+	watch.ApplySuggestions = opts.ApplySuggestions
+	watch.MaxComments = opts.MaxComments
+	watch.MaxIterations = opts.MaxIterations
+	if opts.PollInterval > 0 {
+		watch.PollInterval = opts.PollInterval
+	}
+	if opts.Cooldown > 0 {
+		watch.CooldownDuration = opts.Cooldown
+	}
+	if opts.BatchWait > 0 {
+		watch.BatchWaitDuration = opts.BatchWait
+		watch.BatchWaitMax = max(watch.BatchWaitMax, opts.BatchWait)
+	}
+	return watch
+}
+
+// newResult copies the service's result into the package's own type
+func newResult(prNumber int, r *service.ReviewResult) *Result {
+	result := &Result{
+		PRNumber:           prNumber,
+		Iterations:         r.Iterations,
+		CommentsProcessed:  r.CommentsProcessed,
+		SuggestionsApplied: r.SuggestionsApplied,
+		Resolved:           r.Resolved,
+		Deferred:           r.Deferred,
+		CIFailuresFixed:    r.CIFailuresFixed,
+		Satisfied:          r.Satisfied,
+		StopReason:         r.StopReason,
+	}
+	if r.Satisfaction != nil {
+		result.Reasons = r.Satisfaction.Reasons
+		result.ActionRequired = r.Satisfaction.ActionRequired
+	}
+	return result
+}
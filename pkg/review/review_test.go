@@ -0,0 +1,274 @@
+package review
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DylanSharp/dtools/internal/coderabbit/domain"
+	"github.com/DylanSharp/dtools/internal/coderabbit/ports"
+	"github.com/DylanSharp/dtools/internal/coderabbit/service"
+	"github.com/DylanSharp/dtools/internal/statedir"
+)
+
+// fakeGitHub serves one PR whose comments are resolved as the review resolves them
+type fakeGitHub struct {
+	mu       sync.Mutex
+	comments []domain.Comment
+	resolved map[int]bool
+	replies  map[int]string
+	replyErr error // Returned by every reply, if set
+}
+
+func newFakeGitHub(comments ...domain.Comment) *fakeGitHub {
+	return &fakeGitHub{comments: comments, resolved: make(map[int]bool), replies: make(map[int]string)}
+}
+
+// addComment posts a new comment on the PR
+func (g *fakeGitHub) addComment(c domain.Comment) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.comments = append(g.comments, c)
+}
+
+func (g *fakeGitHub) GetPullRequest(ctx context.Context, owner, repo string, number int) (*ports.PullRequest, error) {
+	return &ports.PullRequest{Number: number, Title: "Add feature", Branch: "feature", HeadCommit: "abc123", State: "OPEN"}, nil
+}
+
+func (g *fakeGitHub) ListCodeRabbitComments(ctx context.Context, owner, repo string, number int) ([]domain.Comment, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	comments := make([]domain.Comment, len(g.comments))
+	for i, c := range g.comments {
+		c.IsResolved = g.resolved[c.ID]
+		comments[i] = c
+	}
+	return comments, nil
+}
+
+func (g *fakeGitHub) GetCodeRabbitSummary(ctx context.Context, owner, repo string, number int) (string, error) {
+	return "", nil
+}
+
+func (g *fakeGitHub) GetLatestCommit(ctx context.Context, owner, repo string, number int) (string, error) {
+	return "abc123", nil
+}
+
+func (g *fakeGitHub) GetCommitTime(ctx context.Context, owner, repo, sha string) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+func (g *fakeGitHub) GetDiff(ctx context.Context, owner, repo string, number int) (string, error) {
+	return "", nil
+}
+
+func (g *fakeGitHub) GetCurrentPR(ctx context.Context) (int, error) { return 7, nil }
+
+func (g *fakeGitHub) GetPRForBranch(ctx context.Context, branch string) (int, error) { return 7, nil }
+
+func (g *fakeGitHub) GetRepoInfo(ctx context.Context) (string, string, error) {
+	return "owner", "repo", nil
+}
+
+func (g *fakeGitHub) GetCurrentBranch(ctx context.Context) (string, error) { return "feature", nil }
+
+func (g *fakeGitHub) ReplyToComment(ctx context.Context, owner, repo string, prNumber, commentID int, body string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.replyErr != nil {
+		return g.replyErr
+	}
+	g.replies[commentID] = body
+	return nil
+}
+
+func (g *fakeGitHub) ResolveComment(ctx context.Context, owner, repo string, prNumber, commentID int) error {
+	_, failed := g.ResolveComments(ctx, owner, repo, prNumber, []int{commentID})
+	return failed[commentID]
+}
+
+func (g *fakeGitHub) ResolveComments(ctx context.Context, owner, repo string, prNumber int, commentIDs []int) ([]int, map[int]error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var resolved []int
+	for _, id := range commentIDs {
+		if !g.resolved[id] {
+			g.resolved[id] = true
+			resolved = append(resolved, id)
+		}
+	}
+	return resolved, nil
+}
+
+// fakeCI reports CodeRabbit's check as complete and nothing failing
+type fakeCI struct{}
+
+func (fakeCI) GetTestFailures(ctx context.Context, owner, repo, commitSHA string) ([]domain.CITestFailure, error) {
+	return nil, nil
+}
+
+func (fakeCI) GetCIStatus(ctx context.Context, owner, repo, commitSHA string) (domain.CIStatus, error) {
+	return domain.CIStatus{CodeRabbitFound: true, CodeRabbitCompleted: true}, nil
+}
+
+func (fakeCI) GetWorkflowRuns(ctx context.Context, owner, repo string, prNumber int) ([]ports.WorkflowRun, error) {
+	return nil, nil
+}
+
+func (fakeCI) RerunFailedJobs(ctx context.Context, owner, repo string, runID int64) error { return nil }
+
+// fakeClaude records each prompt and answers that everything was addressed
+type fakeClaude struct {
+	mu      sync.Mutex
+	prompts []string
+	onRun   func() // Called for each run, e.g. to have CodeRabbit comment on the push
+	answer  string // Replaces the default answer, if set
+}
+
+func (c *fakeClaude) StreamReview(ctx context.Context, prompt string) (<-chan ports.StreamChunk, error) {
+	c.mu.Lock()
+	c.prompts = append(c.prompts, prompt)
+	c.mu.Unlock()
+	if c.onRun != nil {
+		c.onRun()
+	}
+
+	answer := "All addressed, looks good. LGTM."
+	if c.answer != "" {
+		answer = c.answer
+	}
+	chunks := make(chan ports.StreamChunk, 2)
+	chunks <- ports.StreamChunk{Type: "assistant", Message: &ports.AssistantMessage{
+		Content: []ports.ContentBlock{{Type: "text", Text: answer}},
+	}}
+	chunks <- ports.StreamChunk{Type: "result", Result: "All comments have been addressed."}
+	close(chunks)
+	return chunks, nil
+}
+
+func (c *fakeClaude) IsAvailable() bool { return true }
+
+func (c *fakeClaude) runs() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.prompts)
+}
+
+// isolateState keeps the test's processed-comment state out of the user's
+func isolateState(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(statedir.EnvVar, t.TempDir())
+}
+
+func testComments() []domain.Comment {
+	return []domain.Comment{
+		{ID: 1, FilePath: "a.go", LineNumber: 10, Body: "Handle the error"},
+		{ID: 2, FilePath: "a.go", LineNumber: 20, Body: "Rename this variable"},
+		{ID: 3, FilePath: "b.go", LineNumber: 5, Body: "Add a doc comment"},
+	}
+}
+
+func TestRunAddressesCommentsInBatches(t *testing.T) {
+	isolateState(t)
+	github, claude := newFakeGitHub(testComments()...), &fakeClaude{}
+	svc := service.NewReviewService(github, fakeCI{}, claude)
+
+	opts := DefaultOptions()
+	opts.MaxComments = 2
+	result, err := run(context.Background(), svc, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.PRNumber != 7 {
+		t.Errorf("PRNumber = %d, want the detected PR 7", result.PRNumber)
+	}
+	if result.Iterations != 2 || claude.runs() != 2 {
+		t.Errorf("Iterations = %d with %d Claude runs, want 2 batches", result.Iterations, claude.runs())
+	}
+	if result.CommentsProcessed != 3 {
+		t.Errorf("CommentsProcessed = %d, want 3", result.CommentsProcessed)
+	}
+	if result.Resolved != 3 {
+		t.Errorf("Resolved = %d, want 3", result.Resolved)
+	}
+	if result.Deferred != 0 {
+		t.Errorf("Deferred = %d, want 0 once every batch ran", result.Deferred)
+	}
+	if !result.Satisfied {
+		t.Errorf("not satisfied with every comment resolved: %v", result.ActionRequired)
+	}
+}
+
+func TestRunLeavesDeclinedCommentsUnresolved(t *testing.T) {
+	isolateState(t)
+	github := newFakeGitHub(testComments()...)
+	claude := &fakeClaude{answer: "Fixed a.go.\nDECLINED 2: the name matches the API it wraps"}
+	svc := service.NewReviewService(github, fakeCI{}, claude)
+
+	if _, err := run(context.Background(), svc, DefaultOptions()); err != nil {
+		t.Fatal(err)
+	}
+
+	if github.resolved[2] {
+		t.Error("declined comment 2 was resolved")
+	}
+	if !github.resolved[1] || !github.resolved[3] {
+		t.Errorf("resolved = %v, want the addressed comments 1 and 3", github.resolved)
+	}
+	if github.replies[2] != "the name matches the API it wraps" || len(github.replies) != 1 {
+		t.Errorf("replies = %v, want the rationale on comment 2 only", github.replies)
+	}
+}
+
+func TestRunWithNothingToAddress(t *testing.T) {
+	isolateState(t)
+	claude := &fakeClaude{}
+	svc := service.NewReviewService(newFakeGitHub(), fakeCI{}, claude)
+
+	result, err := run(context.Background(), svc, DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Satisfied || result.Iterations != 0 || claude.runs() != 0 {
+		t.Errorf("got Satisfied=%v after %d iteration(s) and %d Claude run(s), want satisfied without running Claude",
+			result.Satisfied, result.Iterations, claude.runs())
+	}
+}
+
+func TestRunWatchStopsAtMaxIterations(t *testing.T) {
+	isolateState(t)
+	// CodeRabbit finds something new in every push, so it's never satisfied
+	github, claude := newFakeGitHub(testComments()...), &fakeClaude{}
+	next := 100
+	claude.onRun = func() {
+		next++
+		github.addComment(domain.Comment{ID: next, FilePath: "c.go", LineNumber: next, Body: "Another issue"})
+	}
+	svc := service.NewReviewService(github, fakeCI{}, claude)
+
+	opts := DefaultOptions()
+	opts.Watch = true
+	opts.ResetState = true
+	opts.MaxIterations = 1
+	opts.PollInterval = 10 * time.Millisecond
+	opts.Cooldown = 10 * time.Millisecond
+	opts.BatchWait = time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result, err := run(ctx, svc, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Satisfied {
+		t.Error("satisfied with every comment still open")
+	}
+	if result.Iterations != 1 || claude.runs() != 1 {
+		t.Errorf("Iterations = %d with %d Claude runs, want 1", result.Iterations, claude.runs())
+	}
+	if result.StopReason == "" {
+		t.Error("no StopReason for reaching MaxIterations")
+	}
+}